@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/filer"
+)
+
+// ---- Архивы как папки ----
+//
+// Enter на .zip, .tar, .tar.gz или .tgz открывает архив в левой панели
+// как папку: путь вида /заметки/фото.zip/2024 ведёт внутрь архива, Left
+// возвращает на уровень выше как обычно. Файлы из архива открываются
+// в редакторе только для чтения. Alt+u распаковывает выбранный элемент
+// (или отмеченные) рядом с архивом, Alt+U — весь архив. Чтение самих
+// архивов — internal/filer/archive.go.
+
+// Больше этого файлы из архива в редакторе не открываем
+const archiveMaxOpenSize = 16 << 20
+
+// Содержимое папки inner архива
+func (a *App) loadArchiveFiles(archive, inner string) {
+	seen := map[string]bool{}
+	err := filer.WalkArchive(archive, func(e filer.Entry, _ func() (io.ReadCloser, error)) error {
+		rest := e.Name
+		if inner != "" {
+			if !strings.HasPrefix(e.Name, inner+"/") {
+				return nil
+			}
+			rest = strings.TrimPrefix(e.Name, inner+"/")
+		}
+		first, more, _ := strings.Cut(rest, "/")
+		if first == "" || seen[first] || (!a.showHidden && strings.HasPrefix(first, ".")) {
+			return nil
+		}
+		seen[first] = true
+		a.files = append(a.files, fileItem{
+			name:  first,
+			path:  filepath.Join(a.currentDir, first),
+			isDir: e.IsDir || more != "",
+		})
+		return nil
+	})
+	if err != nil {
+		a.notify(levelError, tr("archive.failed"), filepath.Base(archive), err)
+	}
+}
+
+// Прочитать файл с диска или из архива (с сервера — readRemoteFile, в фоне)
+func readAnyFile(p string) ([]byte, error) {
+	archive, inner, ok := filer.SplitArchivePath(p)
+	if !ok {
+		return os.ReadFile(p)
+	}
+	var data []byte
+	found := false
+	err := filer.WalkArchive(archive, func(e filer.Entry, open func() (io.ReadCloser, error)) error {
+		if e.Name != inner || e.IsDir {
+			return nil
+		}
+		if e.Size > archiveMaxOpenSize {
+			return fmt.Errorf("%s: %s", inner, formatSize(e.Size))
+		}
+		rc, err := open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		data, err = io.ReadAll(io.LimitReader(rc, archiveMaxOpenSize))
+		found = true
+		if err == nil {
+			err = io.EOF // дальше не читаем
+		}
+		return err
+	})
+	if err == io.EOF {
+		err = nil
+	}
+	if err == nil && !found {
+		err = fs.ErrNotExist
+	}
+	return data, err
+}
+
+// Путь внутри архива (только для чтения)
+func inArchive(p string) bool {
+	_, _, ok := filer.SplitArchivePath(p)
+	return ok
+}
+
+// Alt+u: распаковать выбранный элемент (или отмеченные) рядом с архивом
+func (a *App) extractSelected() {
+	archive, inner, ok := filer.SplitArchivePath(a.currentDir)
+	if !ok || a.activePanel != "left" {
+		a.notify(levelInfo, "%s", tr("archive.not_inside"))
+		return
+	}
+	var names []string
+	for _, f := range a.markedFiles() {
+		names = append(names, path.Join(inner, f.name))
+	}
+	if len(names) == 0 {
+		return
+	}
+	// пути берутся относительно открытой в архиве папки
+	a.extract(archive, filepath.Dir(archive), inner, names)
+}
+
+// Alt+U: распаковать весь архив (открытый или под курсором) в его папку
+func (a *App) extractAll() {
+	archive, _, ok := filer.SplitArchivePath(a.currentDir)
+	if !ok {
+		if a.activePanel != "left" || a.cursor < 0 || a.cursor >= len(a.files) || !filer.IsArchiveName(a.files[a.cursor].name) {
+			a.notify(levelInfo, "%s", tr("archive.not_inside"))
+			return
+		}
+		archive = a.files[a.cursor].path
+	}
+	a.extract(archive, filepath.Dir(archive), "", nil)
+}
+
+// Распаковать элементы names (с их содержимым; nil — все) в dest,
+// отбрасывая префикс base. Если файлы уже есть, спрашивает подтверждение.
+func (a *App) extract(archive, dest, base string, names []string) {
+	selected := func(name string) bool {
+		if names == nil {
+			return true
+		}
+		for _, n := range names {
+			if name == n || strings.HasPrefix(name, n+"/") {
+				return true
+			}
+		}
+		return false
+	}
+	target := func(name string) string {
+		rel := name
+		if base != "" {
+			rel = strings.TrimPrefix(name, base+"/")
+		}
+		return filepath.Join(dest, filepath.FromSlash(rel))
+	}
+
+	exists := 0
+	err := filer.WalkArchive(archive, func(e filer.Entry, _ func() (io.ReadCloser, error)) error {
+		if selected(e.Name) && !e.IsDir {
+			if _, err := os.Stat(target(e.Name)); err == nil {
+				exists++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		a.notify(levelError, tr("archive.failed"), filepath.Base(archive), err)
+		return
+	}
+
+	run := func() {
+		count := 0
+		err := filer.WalkArchive(archive, func(e filer.Entry, open func() (io.ReadCloser, error)) error {
+			if !selected(e.Name) {
+				return nil
+			}
+			dst := target(e.Name)
+			if e.IsDir {
+				return os.MkdirAll(dst, 0755)
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			rc, err := open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			perm := e.Mode.Perm()
+			if perm == 0 {
+				perm = 0644
+			}
+			out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, rc)
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			count++
+			return err
+		})
+		if err != nil {
+			a.notify(levelError, tr("archive.extract_failed"), err)
+		} else {
+			a.notify(levelSuccess, tr("archive.extracted"), count, dest)
+		}
+		if !inArchive(a.currentDir) {
+			a.loadFiles()
+		}
+	}
+	if exists > 0 {
+		a.confirmIf(a.config.Confirm.Overwrite, trf("archive.overwrite", exists), run)
+		return
+	}
+	run()
+}
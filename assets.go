@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---- Вложения: картинки и файлы, на которые ссылается документ ----
+//
+// Alt+a — список вложений текущего документа и «сирот» в папке вложений
+// (notes.assets_dir рядом с документом), на которые не ссылается ни одна
+// заметка. При переименовании файла через панель (F2) ссылки на него
+// обновляются во всех заметках. Заметки ищутся от папки запуска.
+
+// Локальные файлы, на которые ссылается текст (пути абсолютные, без повторов)
+func referencedFiles(path, content string) []string {
+	dir := filepath.Dir(path)
+	lines := strings.Split(content, "\n")
+	code := codeBlockLines(lines)
+	seen := map[string]bool{}
+	var res []string
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		for _, m := range mdLinkRe.FindAllStringSubmatch(line, -1) {
+			p, ok := linkFilePath(dir, m[1])
+			if !ok || seen[p] {
+				continue
+			}
+			seen[p] = true
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// Markdown-файлы под root (скрытые папки пропускаются)
+func markdownFiles(root string) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == ".md" || ext == ".markdown" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// Где искать заметки, ссылающиеся на path: папка запуска,
+// а если path вне её — текущая папка панели
+func (a *App) notesRoot(path string) string {
+	if rel, err := filepath.Rel(a.rootDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return a.rootDir
+	}
+	return a.currentDir
+}
+
+// Содержимое заметки: из открытого буфера, если он есть, иначе с диска
+func (a *App) noteContent(path string) (string, bool) {
+	for _, v := range a.views {
+		if v.buf.path == path {
+			return v.buf.Content, true
+		}
+	}
+	b, err := os.ReadFile(path)
+	return string(b), err == nil
+}
+
+// Список вложений и сирот
+func (a *App) showAssets() {
+	buf := a.view.buf
+	if buf.path == "" {
+		a.notify(levelWarning, "%s", tr("links.no_file"))
+		return
+	}
+	var items []listItem
+	rel := func(p string) string {
+		if r, err := filepath.Rel(filepath.Dir(buf.path), p); err == nil {
+			return r
+		}
+		return p
+	}
+	for _, p := range referencedFiles(buf.path, buf.Content) {
+		detail := tr("assets.missing")
+		if st, err := os.Stat(p); err == nil {
+			detail = formatSize(st.Size())
+		}
+		items = append(items, listItem{label: rel(p), detail: detail, value: p})
+	}
+
+	// сироты: файлы папки вложений без ссылок из заметок
+	assetsDir := filepath.Join(filepath.Dir(buf.path), a.config.Notes.AssetsDir)
+	if entries, err := os.ReadDir(assetsDir); err == nil && a.config.Notes.AssetsDir != "" {
+		used := map[string]bool{}
+		for _, note := range markdownFiles(a.notesRoot(assetsDir)) {
+			content, ok := a.noteContent(note)
+			if !ok {
+				continue
+			}
+			for _, p := range referencedFiles(note, content) {
+				used[p] = true
+			}
+		}
+		for _, e := range entries {
+			p := filepath.Join(assetsDir, e.Name())
+			if !e.IsDir() && !used[p] {
+				items = append(items, listItem{label: rel(p), detail: tr("assets.orphan"), value: p})
+			}
+		}
+	}
+
+	if len(items) == 0 {
+		a.notify(levelInfo, "%s", tr("assets.none"))
+		return
+	}
+	a.pick(tr("assets.title"), items, func(item listItem) {
+		// показываем файл в панели: картинки в редакторе не открываем
+		if _, err := os.Stat(item.value); err != nil {
+			return
+		}
+		a.currentDir = filepath.Dir(item.value)
+		a.loadFiles()
+		a.selectFile(item.value)
+		a.setActivePanel("left")
+	})
+}
+
+// Размер файла для списка
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// Переписать ссылки на oldPath в ссылки на newPath; возвращает новый текст
+// и число изменённых ссылок
+func rewriteLinks(notePath, content, oldPath, newPath string) (string, int) {
+	dir := filepath.Dir(notePath)
+	count := 0
+	lines := strings.Split(content, "\n")
+	code := codeBlockLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		lines[i] = mdLinkRe.ReplaceAllStringFunc(line, func(link string) string {
+			m := mdLinkRe.FindStringSubmatchIndex(link)
+			target := link[m[2]:m[3]]
+			p, ok := linkFilePath(dir, target)
+			if !ok || p != oldPath {
+				return link
+			}
+			rel, err := filepath.Rel(dir, newPath)
+			if err != nil {
+				return link
+			}
+			// якорь и параметры сохраняем
+			suffix := ""
+			if j := strings.IndexAny(target, "#?"); j >= 0 {
+				suffix = target[j:]
+			}
+			count++
+			return link[:m[2]] + filepath.ToSlash(rel) + suffix + link[m[3]:]
+		})
+	}
+	return strings.Join(lines, "\n"), count
+}
+
+// Обновить ссылки во всех заметках после переименования
+func (a *App) updateLinksAfterRename(oldPath, newPath string) {
+	total := 0
+	for _, note := range markdownFiles(a.notesRoot(newPath)) {
+		// открытый буфер правим в памяти, чтобы не потерять несохранённое
+		var open *buffer
+		for _, v := range a.views {
+			if v.buf.path == note {
+				open = v.buf
+				break
+			}
+		}
+		if open != nil {
+			text, n := rewriteLinks(note, open.Content, oldPath, newPath)
+			if n > 0 {
+				open.Content = text
+				open.Modified = true
+				total += n
+			}
+			continue
+		}
+		b, err := os.ReadFile(note)
+		if err != nil {
+			continue
+		}
+		text, n := rewriteLinks(note, string(b), oldPath, newPath)
+		if n == 0 {
+			continue
+		}
+		if err := os.WriteFile(note, []byte(text), 0644); err != nil {
+			a.notify(levelError, tr("save.failed"), err)
+			continue
+		}
+		total += n
+	}
+	if total > 0 {
+		a.notify(levelInfo, tr("assets.links_updated"), total)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	_ "embed"
+	"os"
+	"path/filepath"
+)
+
+// ---- Первый запуск ----
+//
+// Если папки настроек ~/.config/eddy ещё нет, она создаётся с
+// config.toml и theme.toml — теми же, что лежат в репозитории, с
+// комментариями ко всем ключам. Сразу после запуска открывается выбор
+// темы (Alt+T): Esc оставляет theme.toml. Существующие файлы никогда не
+// перезаписываются, а файлы рядом с бинарником по-прежнему читаются,
+// если папку создать не удалось.
+
+//go:embed config.toml
+var defaultConfigFile []byte
+
+//go:embed theme.toml
+var defaultThemeFile []byte
+
+// Создать папку настроек с файлами по умолчанию; true — это первый запуск
+func bootstrapConfigDir() (bool, error) {
+	dir := configDir()
+	if _, err := os.Stat(dir); err == nil || !os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+	files := map[string][]byte{
+		"config.toml": defaultConfigFile,
+		"theme.toml":  defaultThemeFile,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// После запуска интерфейса: сообщить о созданной папке и предложить тему
+func (a *App) welcome(created bool, err error) {
+	if err != nil {
+		a.notify(levelWarning, tr("bootstrap.failed"), err)
+	}
+	if !created || err != nil {
+		return
+	}
+	a.notify(levelSuccess, tr("bootstrap.created"), configDir())
+	a.themePicker()
+}
@@ -0,0 +1,142 @@
+package main
+
+import "strings"
+
+// ---- Парные скобки (Ctrl+]) ----
+//
+// Скобка под курсором (или сразу перед ним) и её пара подсвечиваются
+// стилем [ui.bracket]. Ctrl+] переносит курсор на парную скобку, как % в
+// vim. Пары: (), [], {} и ограждения блоков кода ``` / ~~~ — для них
+// курсор может стоять в любом месте строки ограждения.
+
+// Дальше этого числа строк пару не ищем (подсветка считается при каждой отрисовке)
+const bracketScanLimit = 5000
+
+var bracketPairs = map[rune]rune{'(': ')', '[': ']', '{': '}', ')': '(', ']': '[', '}': '{'}
+
+func isOpenBracket(r rune) bool {
+	return r == '(' || r == '[' || r == '{'
+}
+
+// Строка — ограждение блока кода; col — колонка первого символа
+func fenceCol(line string) (int, bool) {
+	trimmed := strings.TrimLeft(line, " \t")
+	if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		return len([]rune(line)) - len([]rune(trimmed)), true
+	}
+	return 0, false
+}
+
+// Найти пару для позиции курсора. Возвращает позицию скобки (или
+// ограждения) у курсора и позицию её пары.
+func matchBracket(lines []string, y, x int) (fromY, fromX, toY, toX int, ok bool) {
+	if y < 0 || y >= len(lines) {
+		return
+	}
+	if col, fence := fenceCol(lines[y]); fence {
+		ty, tx, ok := matchFence(lines, y)
+		return y, col, ty, tx, ok
+	}
+	runes := []rune(lines[y])
+	// скобка под курсором, иначе перед ним
+	for _, bx := range []int{x, x - 1} {
+		if bx < 0 || bx >= len(runes) {
+			continue
+		}
+		if _, isBracket := bracketPairs[runes[bx]]; !isBracket {
+			continue
+		}
+		ty, tx, ok := scanBracket(lines, y, bx)
+		return y, bx, ty, tx, ok
+	}
+	return
+}
+
+// Пройти по тексту от скобки до парной, считая вложенность
+func scanBracket(lines []string, y, x int) (int, int, bool) {
+	open := []rune(lines[y])[x]
+	pair := bracketPairs[open]
+	step := 1
+	if !isOpenBracket(open) {
+		step = -1
+	}
+	depth := 0
+	for ly := y; ly >= 0 && ly < len(lines) && (ly-y)*step <= bracketScanLimit; ly += step {
+		runes := []rune(lines[ly])
+		lx := 0
+		if step < 0 {
+			lx = len(runes) - 1
+		}
+		if ly == y {
+			lx = x
+		}
+		for ; lx >= 0 && lx < len(runes); lx += step {
+			switch runes[lx] {
+			case open:
+				depth++
+			case pair:
+				depth--
+				if depth == 0 {
+					return ly, lx, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Парное ограждение: ограждения с начала файла идут парами (открывающее, закрывающее)
+func matchFence(lines []string, y int) (int, int, bool) {
+	var fences []int
+	idx := -1
+	for i, line := range lines {
+		if _, ok := fenceCol(line); ok {
+			if i == y {
+				idx = len(fences)
+			}
+			fences = append(fences, i)
+		}
+	}
+	other := idx + 1
+	if idx%2 == 1 {
+		other = idx - 1
+	}
+	if idx < 0 || other >= len(fences) {
+		return 0, 0, false
+	}
+	col, _ := fenceCol(lines[fences[other]])
+	return fences[other], col, true
+}
+
+// Ctrl+]: перейти к парной скобке
+func (a *App) jumpToBracket() {
+	v := a.view
+	if a.activePanel != "right" || v.mode != "edit" {
+		return
+	}
+	_, _, y, x, ok := matchBracket(v.buf.Lines(), v.editY, v.editX)
+	if !ok {
+		a.notify(levelInfo, "%s", tr("bracket.none"))
+		return
+	}
+	v.clearSelection()
+	v.editY, v.editX = y, x
+	a.ensureCursorVisible()
+}
+
+// Позиции для подсветки в окне: скобка у курсора и её пара
+func bracketMarks(v *editorView, lines []string) map[int][][2]int {
+	fy, fx, ty, tx, ok := matchBracket(lines, v.editY, v.editX)
+	if !ok {
+		return nil
+	}
+	marks := map[int][][2]int{}
+	for _, p := range [][2]int{{fy, fx}, {ty, tx}} {
+		n := 1
+		if _, fence := fenceCol(lines[p[0]]); fence {
+			n = 3
+		}
+		marks[p[0]] = append(marks[p[0]], [2]int{p[1], p[1] + n})
+	}
+	return marks
+}
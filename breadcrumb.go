@@ -0,0 +1,178 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Путь текущей папки над списком файлов ----
+//
+// Вторая строка левой панели показывает текущую папку по частям:
+// ~/notes/2024 (внутри домашней папки — от ~). Если путь не помещается,
+// средние части заменяются на «…», последняя всегда видна. Щелчок по
+// части пути переходит в эту папку; с клавиатуры — Alt+b, стрелки
+// влево/вправо и Enter.
+
+// Часть пути: подпись, папка и место в строке (колонки от начала)
+type crumb struct {
+	label string
+	path  string
+	x, w  int
+}
+
+// Части пути папки dir
+func pathCrumbs(dir string) []crumb {
+	if r, ok := parseRemote(dir); ok {
+		host := strings.TrimSuffix(r.with("/").String(), "/")
+		cs := []crumb{{label: strings.TrimPrefix(host, "sftp://") + ":", path: r.with("/").String()}}
+		p := "/"
+		for _, part := range strings.Split(strings.Trim(r.path, "/"), "/") {
+			if part == "" {
+				continue
+			}
+			p = path.Join(p, part)
+			cs = append(cs, crumb{label: part, path: r.with(p).String()})
+		}
+		return cs
+	}
+
+	dir = filepath.Clean(dir)
+	root := filepath.VolumeName(dir) + string(filepath.Separator)
+	cs := []crumb{{label: root, path: root}}
+	rest := strings.TrimPrefix(dir, root)
+	if home, err := os.UserHomeDir(); err == nil && home != root {
+		if rel, err := filepath.Rel(home, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			cs = []crumb{{label: "~", path: home}}
+			rest = rel
+		}
+	}
+	p := cs[0].path
+	for _, part := range strings.Split(rest, string(filepath.Separator)) {
+		if part == "" || part == "." {
+			continue
+		}
+		p = filepath.Join(p, part)
+		cs = append(cs, crumb{label: part, path: p})
+	}
+	return cs
+}
+
+// Разместить части в ширине width: лишние средние части — в «…»,
+// слишком длинная последняя обрезается
+func fitCrumbs(cs []crumb, width int) []crumb {
+	place := func(cs []crumb) ([]crumb, int) {
+		x := 0
+		out := make([]crumb, len(cs))
+		for i, c := range cs {
+			if i > 0 && !strings.HasSuffix(cs[i-1].label, string(filepath.Separator)) && !strings.HasSuffix(cs[i-1].label, ":") {
+				x++ // разделитель
+			}
+			c.x, c.w = x, runewidth.StringWidth(c.label)
+			out[i] = c
+			x += c.w
+		}
+		return out, x
+	}
+	out, total := place(cs)
+	for hidden := 1; total > width && len(cs)-hidden > 1; hidden++ {
+		// первая часть, «…» (ведёт в последнюю скрытую папку) и хвост
+		vis := append([]crumb{cs[0], {label: "…", path: cs[hidden].path}}, cs[hidden+1:]...)
+		out, total = place(vis)
+	}
+	if total > width && len(out) > 0 {
+		last := &out[len(out)-1]
+		last.label = runewidth.Truncate(last.label, max(width-last.x, 1), "…")
+		last.w = runewidth.StringWidth(last.label)
+	}
+	return out
+}
+
+// Нарисовать путь во второй строке панели; selected — выбранная часть (-1 — нет)
+func (a *App) drawBreadcrumb(selected int) {
+	styles := a.getStyles()
+	width := a.listWidth() - 2
+	if width < 1 {
+		return
+	}
+	a.crumbs = fitCrumbs(pathCrumbs(a.currentDir), width)
+	sep := styles.Border
+	for i, c := range a.crumbs {
+		if i > 0 && c.x > a.crumbs[i-1].x+a.crumbs[i-1].w {
+			ui.PutString(a.screen, c.x, 1, width+1, string(filepath.Separator), sep)
+		}
+		style := styles.fileRow(true, false)
+		if i == len(a.crumbs)-1 {
+			style = style.Bold(true)
+		}
+		if i == selected {
+			style = styles.fileRow(true, true)
+		}
+		ui.PutString(a.screen, c.x+1, 1, width+1, c.label, style)
+	}
+}
+
+// Часть пути под колонкой x второй строки панели (или -1)
+func (a *App) crumbAt(x int) int {
+	for i, c := range a.crumbs {
+		if x >= c.x+1 && x < c.x+1+c.w {
+			return i
+		}
+	}
+	return -1
+}
+
+// Щелчок мышью по пути
+func (a *App) clickBreadcrumb(x, y int) {
+	if lw := a.listWidth(); lw == 0 || y != 1 || x >= lw {
+		return
+	}
+	if i := a.crumbAt(x); i >= 0 && a.crumbs[i].path != a.currentDir {
+		a.enterDir(a.crumbs[i].path)
+	}
+}
+
+// Alt+b: выбор части пути с клавиатуры
+type breadcrumbOverlay struct {
+	selected int
+}
+
+func (a *App) selectBreadcrumb() {
+	if a.listWidth() == 0 {
+		a.setActivePanel("left")
+	}
+	n := len(fitCrumbs(pathCrumbs(a.currentDir), max(a.listWidth()-2, 1)))
+	a.pushOverlay(&breadcrumbOverlay{selected: max(n-2, 0)})
+}
+
+func (o *breadcrumbOverlay) draw(a *App) {
+	a.drawBreadcrumb(o.selected)
+	ui.PutString(a.screen, 1, a.height-3, a.width, tr("crumbs.hint"), a.dialogStyles().dim)
+}
+
+func (o *breadcrumbOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyLeft:
+		o.selected = max(o.selected-1, 0)
+	case tcell.KeyRight:
+		o.selected = min(o.selected+1, len(a.crumbs)-1)
+	case tcell.KeyHome:
+		o.selected = 0
+	case tcell.KeyEnd:
+		o.selected = len(a.crumbs) - 1
+	case tcell.KeyEnter:
+		if o.selected >= 0 && o.selected < len(a.crumbs) {
+			a.enterDir(a.crumbs[o.selected].path)
+		}
+		return true
+	}
+	return false
+}
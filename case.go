@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Смена регистра (Alt+C) ----
+//
+// ВЕРХНИЙ, нижний, Каждое Слово С Заглавной и slug-вид (строчные слова
+// через дефис — для якорей и имён файлов из заголовков). Работает с
+// выделением, а без него — со словом под курсором. Результат остаётся
+// выделенным; Alt+. повторяет преобразование.
+
+var caseOps = []struct {
+	name, desc string
+	apply      func(string) string
+}{
+	{"case.upper", "case.upper", strings.ToUpper},
+	{"case.lower", "case.lower", strings.ToLower},
+	{"case.title", "case.title", editor.TitleCase},
+	{"case.slug", "case.slug", editor.Slugify},
+}
+
+// Выделить слово под курсором; false — курсор не на слове
+func (a *App) selectWord() bool {
+	runes := []rune(a.getLines()[a.view.editY])
+	isWord := func(i int) bool {
+		return i >= 0 && i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_')
+	}
+	x := a.view.editX
+	if !isWord(x) {
+		x-- // курсор сразу за словом
+	}
+	if !isWord(x) {
+		return false
+	}
+	start, end := x, x
+	for isWord(start - 1) {
+		start--
+	}
+	for isWord(end) {
+		end++
+	}
+	a.view.selecting = true
+	a.view.selY, a.view.selX = a.view.editY, start
+	a.view.editX = end
+	return true
+}
+
+// Заменить выделение (или слово) результатом apply и выделить его
+func (a *App) applyCaseOp(apply func(string) string) {
+	if _, ok := a.view.selection(); !ok && !a.selectWord() {
+		return
+	}
+	text := a.view.selectedText()
+	out := apply(text)
+	if out == text {
+		return
+	}
+	a.deleteSelection()
+	y, x := a.view.editY, a.view.editX
+	a.insertText(out)
+	a.view.selecting = true
+	a.view.selY, a.view.selX = y, x
+}
+
+// Выполнить преобразование по имени (для реестра команд)
+func (a *App) runCaseOp(name string) {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	for _, op := range caseOps {
+		if op.name == name {
+			a.repeatable(func() { a.applyCaseOp(op.apply) })
+			return
+		}
+	}
+}
+
+// Alt+C: меню смены регистра с примером на текущем тексте
+func (a *App) caseMenu() {
+	if a.activePanel != "right" || a.view.mode != "edit" {
+		return
+	}
+	sample := a.view.selectedText()
+	if sample == "" {
+		// слово под курсором, не трогая выделение
+		v := a.view
+		sel, sx, sy, ex := v.selecting, v.selX, v.selY, v.editX
+		if a.selectWord() {
+			sample = v.selectedText()
+		}
+		v.selecting, v.selX, v.selY, v.editX = sel, sx, sy, ex
+	}
+	sample = firstLine(sample)
+	items := make([]listItem, len(caseOps))
+	for i, op := range caseOps {
+		items[i] = listItem{label: tr(op.desc), detail: op.apply(sample), value: op.name}
+	}
+	a.pick(tr("case.title_menu"), items, func(item listItem) {
+		a.runCaseOp(item.value)
+	})
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Контрольная сумма файла (Alt+h) ----
+//
+// MD5 и SHA-256 файла под курсором считаются в фоне, окно показывает
+// «Вычисление…», пока не готово. Из окна сумму можно скопировать
+// (m — MD5, s — SHA-256) и сравнить с ожидаемой (c): поле заранее
+// заполнено скопированным текстом, сумму можно и вставить из терминала.
+
+type checksumOverlay struct {
+	name   string
+	md5    string
+	sha256 string
+	err    error
+	done   bool
+	// результат сравнения: "" — не сравнивали
+	verdict string
+	cancel  context.CancelFunc
+}
+
+// Посчитать суммы файла под курсором
+func (a *App) showChecksum() {
+	if a.activePanel != "left" || a.cursor < 0 || a.cursor >= len(a.files) {
+		return
+	}
+	file := a.files[a.cursor]
+	if file.isDir {
+		a.notify(levelWarning, tr("checksum.dir"), file.name)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	o := &checksumOverlay{name: file.name, cancel: cancel}
+	a.pushOverlay(o)
+	go func() {
+		m, s, err := fileChecksums(ctx, file.path)
+		a.post(func() {
+			o.md5, o.sha256, o.err, o.done = m, s, err, true
+		})
+	}()
+}
+
+// MD5 и SHA-256 за одно чтение файла
+func fileChecksums(ctx context.Context, path string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+	m, s := md5.New(), sha256.New()
+	w := io.MultiWriter(m, s)
+	buf := make([]byte, 256<<10)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", "", err
+		}
+		n, err := f.Read(buf)
+		w.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return hex.EncodeToString(m.Sum(nil)), hex.EncodeToString(s.Sum(nil)), nil
+}
+
+// Сравнить с ожидаемой суммой (регистр и пробелы не важны;
+// принимается и строка вида "<сумма>  <имя файла>")
+func (o *checksumOverlay) compare(expected string) string {
+	fields := strings.Fields(strings.ToLower(expected))
+	if len(fields) == 0 {
+		return ""
+	}
+	switch fields[0] {
+	case o.md5:
+		return tr("checksum.match_md5")
+	case o.sha256:
+		return tr("checksum.match_sha256")
+	}
+	return tr("checksum.mismatch")
+}
+
+func (o *checksumOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := a.centeredRect(78, 7)
+	ui.Box(a.screen, x, y, w, h, " "+trf("checksum.title", filepath.Base(o.name))+" ", st.border, st.body)
+	switch {
+	case o.err != nil:
+		ui.PutString(a.screen, x+2, y+1, x+w-2, o.err.Error(), st.body)
+	case !o.done:
+		ui.PutString(a.screen, x+2, y+1, x+w-2, tr("checksum.computing"), st.dim)
+	default:
+		col := ui.PutString(a.screen, x+2, y+1, x+w-2, "MD5:     ", st.dim)
+		ui.PutString(a.screen, col, y+1, x+w-2, o.md5, st.body)
+		col = ui.PutString(a.screen, x+2, y+2, x+w-2, "SHA-256: ", st.dim)
+		ui.PutString(a.screen, col, y+2, x+w-2, o.sha256, st.body)
+	}
+	if o.verdict != "" {
+		ui.PutString(a.screen, x+2, y+4, x+w-2, o.verdict, st.body.Bold(true))
+	}
+	ui.PutString(a.screen, x+2, y+h-2, x+w-2, tr("checksum.hint"), st.dim)
+}
+
+func (o *checksumOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyEnter:
+		o.cancel()
+		return true
+	case tcell.KeyRune:
+	default:
+		return false
+	}
+	if ev.Rune() == 'q' {
+		o.cancel()
+		return true
+	}
+	if !o.done || o.err != nil {
+		return false
+	}
+	switch ev.Rune() {
+	case 'm':
+		a.copyToClipboard(o.md5)
+		a.notify(levelInfo, "%s", tr("checksum.copied"))
+	case 's':
+		a.copyToClipboard(o.sha256)
+		a.notify(levelInfo, "%s", tr("checksum.copied"))
+	case 'c':
+		initial := ""
+		if isHexDigest(a.clipboard) {
+			initial = strings.TrimSpace(a.clipboard)
+		}
+		a.prompt(tr("checksum.expected"), initial, func(text string) {
+			o.verdict = o.compare(text)
+		})
+	}
+	return false
+}
+
+// Похоже на шестнадцатеричную сумму
+func isHexDigest(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields[0]) < 32 {
+		return false
+	}
+	_, err := hex.DecodeString(fields[0])
+	return err == nil
+}
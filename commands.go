@@ -0,0 +1,298 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Реестр команд ----
+//
+// Каждое действие — именованная команда (file.delete, editor.save,
+// window.next, …) с описанием, разделом справки и клавишами по
+// умолчанию. Из реестра строятся обработка клавиш, справка (?),
+// подсказки which-key и палитра команд (Ctrl+P). Команды без run —
+// клавиши, которые обрабатывает само ядро редактора (стрелки, ввод
+// текста); они в реестре только для справки.
+//
+// Клавиша с пробелом ("Ctrl+W v") — префиксная: первая часть ждёт
+// продолжения. Одна клавиша может вести к разным командам в разных
+// панелях (Delete удаляет файл слева и символ справа); "preview" —
+// правая панель в режиме предпросмотра.
+
+type command struct {
+	name    string   // "file.delete"
+	context string   // раздел справки ("" — не показывать)
+	desc    string   // ключ каталога сообщений
+	keys    []string // клавиши, как их называет keyName
+	panel   string   // где действуют клавиши: "left", "right", "preview" или "" (везде)
+	run     func(a *App)
+}
+
+// Только в режиме правки активного окна
+func inEditor(fn func(a *App)) func(a *App) {
+	return func(a *App) {
+		if a.activePanel == "right" && a.view.mode == "edit" {
+			fn(a)
+		}
+	}
+}
+
+// Встроенные команды в порядке справки
+func builtinCommands() []*command {
+	cmds := []*command{
+		{name: "nav.up", context: "help.ctx.navigation", desc: "help.files.up", keys: []string{"Up"}},
+		{name: "nav.down", context: "help.ctx.navigation", desc: "help.files.down", keys: []string{"Down"}},
+		{name: "nav.open", context: "help.ctx.navigation", desc: "help.files.open", keys: []string{"Right"}},
+		{name: "nav.back", context: "help.ctx.navigation", desc: "help.files.back", keys: []string{"Left"}},
+		{name: "nav.enter", context: "help.ctx.navigation", desc: "help.files.enter", keys: []string{"Enter"}},
+		{"file.delete", "help.ctx.navigation", "help.files.delete", []string{"Delete"}, "left", (*App).deleteFile},
+		{"file.rename", "help.ctx.navigation", "help.files.rename", []string{"F2"}, "", func(a *App) {
+			if a.activePanel == "left" {
+				a.renameSelected()
+			}
+		}},
+		{"file.mark", "help.ctx.navigation", "help.files.mark", []string{"Space"}, "left", (*App).toggleMark},
+		{"file.properties", "help.ctx.navigation", "help.files.properties", []string{"Alt+p"}, "", (*App).showProperties},
+		{"file.checksum", "help.ctx.navigation", "help.files.checksum", []string{"Alt+h"}, "", (*App).showChecksum},
+		{"file.diskUsage", "help.ctx.navigation", "help.files.du", []string{"Alt+d"}, "", (*App).toggleDiskUsage},
+		{"file.diskUsageSort", "help.ctx.navigation", "help.files.du_sort", []string{"Alt+D"}, "", (*App).toggleDiskUsageSort},
+		{"file.extract", "help.ctx.navigation", "help.files.extract", []string{"Alt+u"}, "", (*App).extractSelected},
+		{"file.extractAll", "help.ctx.navigation", "help.files.extract_all", []string{"Alt+U"}, "", (*App).extractAll},
+		{"file.new", "help.ctx.navigation", "help.files.new", []string{"Ctrl+N"}, "", (*App).newFilePrompt},
+		{"file.openPath", "help.ctx.navigation", "help.files.open_path", []string{"Alt+o"}, "", (*App).openPathPrompt},
+		{"file.breadcrumb", "help.ctx.navigation", "help.files.breadcrumb", []string{"Alt+b"}, "", (*App).selectBreadcrumb},
+		{"file.download", "help.ctx.navigation", "help.files.download", []string{"Alt+w"}, "", (*App).downloadURL},
+
+		{"panel.focusLeft", "help.ctx.panels", "help.panels.left", []string{"Ctrl+Left"}, "", func(a *App) { a.setActivePanel("left") }},
+		{"panel.focusRight", "help.ctx.panels", "help.panels.right", []string{"Ctrl+Right"}, "", func(a *App) { a.setActivePanel("right") }},
+		{"panel.toggle", "help.ctx.panels", "help.panels.toggle", []string{"Ctrl+B"}, "", (*App).toggleFilePanel},
+		{"panel.grow", "help.ctx.panels", "help.panels.grow", []string{"Alt+>"}, "", func(a *App) { a.resizePanel(1) }},
+		{"panel.shrink", "help.ctx.panels", "help.panels.shrink", []string{"Alt+<"}, "", func(a *App) { a.resizePanel(-1) }},
+
+		{"editor.toggleMode", "help.ctx.editing", "help.edit.mode", []string{"Tab"}, "right", (*App).toggleMode},
+		{"editor.save", "help.ctx.editing", "help.edit.save", []string{"Ctrl+S"}, "", (*App).saveFile},
+		{"editor.gotoLine", "help.ctx.editing", "help.edit.goto", []string{"Ctrl+G"}, "", (*App).gotoLinePrompt},
+		{"editor.headings", "help.ctx.editing", "help.edit.headings", []string{"Ctrl+T"}, "right", (*App).headingPicker},
+		{name: "editor.homeEnd", context: "help.ctx.editing", desc: "help.edit.home_end", keys: []string{"Home/End"}},
+		{name: "editor.select", context: "help.ctx.editing", desc: "help.edit.select", keys: []string{"Shift+Arrows"}},
+		{"editor.copy", "help.ctx.editing", "help.edit.copy", []string{"Ctrl+C"}, "", inEditor((*App).copySelection)},
+		{"editor.cut", "help.ctx.editing", "help.edit.cut", []string{"Ctrl+X"}, "", inEditor((*App).cutSelection)},
+		{"editor.paste", "help.ctx.editing", "help.edit.paste", []string{"Ctrl+V"}, "", inEditor((*App).pasteClipboard)},
+		{"editor.clipboardHistory", "help.ctx.editing", "help.edit.clip_history", []string{"Alt+v"}, "", (*App).clipboardPicker},
+		{"editor.register", "help.ctx.editing", "help.edit.register", []string{`Alt+"`}, "", inEditor(func(a *App) {
+			a.register = registerPending
+			a.startPrefix()
+		})},
+		{"editor.search", "help.ctx.editing", "help.edit.search", []string{"Ctrl+F"}, "right", (*App).openSearch},
+		{"editor.searchNext", "help.ctx.editing", "help.edit.search_next", []string{"F3"}, "right", func(a *App) { a.searchAgain(false) }},
+		{"editor.searchPrev", "help.ctx.editing", "help.edit.search_prev", []string{"Shift+F3"}, "right", func(a *App) { a.searchAgain(true) }},
+		{"editor.replaceInFiles", "help.ctx.editing", "help.edit.replace_files", []string{"Alt+f"}, "", (*App).replaceInFiles},
+		{"editor.undo", "help.ctx.editing", "help.edit.undo", []string{"Ctrl+Z"}, "", (*App).undo},
+		{"editor.redo", "help.ctx.editing", "help.edit.redo", []string{"Ctrl+Y"}, "", (*App).redo},
+		{"editor.matchBracket", "help.ctx.editing", "help.edit.bracket", []string{"Ctrl+]"}, "", (*App).jumpToBracket},
+		{"editor.repeat", "help.ctx.editing", "help.edit.repeat", []string{"Alt+."}, "", (*App).repeatLastEdit},
+		{"editor.readOnly", "help.ctx.editing", "help.edit.readonly", []string{"Alt+r"}, "", (*App).toggleReadOnly},
+		{"editor.diff", "help.ctx.editing", "help.edit.diff", []string{"Alt+="}, "", (*App).diffUnsaved},
+		{"editor.conflict", "help.ctx.editing", "help.edit.conflict", []string{"Alt+k"}, "", (*App).conflictMenu},
+		{"editor.external", "help.ctx.editing", "help.edit.external", []string{"Alt+e"}, "", (*App).openInExternalEditor},
+		{"editor.export", "help.ctx.editing", "help.edit.export", []string{"Alt+x"}, "", (*App).exportMenu},
+		{"editor.lines", "help.ctx.editing", "help.edit.lines", []string{"Alt+S"}, "", (*App).linesMenu},
+		{"editor.timestamp", "help.ctx.editing", "help.edit.timestamp", []string{"Alt+i"}, "", (*App).insertTimestamp},
+		{"editor.case", "help.ctx.editing", "help.edit.case", []string{"Alt+C"}, "", (*App).caseMenu},
+		{"editor.filter", "help.ctx.editing", "help.edit.filter", []string{"Alt+|"}, "", (*App).filterPrompt},
+		{"editor.format", "help.ctx.editing", "help.edit.format", []string{"Alt+F"}, "", (*App).formatCommand},
+		{"editor.copyPlain", "help.ctx.editing", "help.edit.copy_plain", []string{"Alt+c"}, "", (*App).copyPlainText},
+		{"editor.spell", "help.ctx.editing", "help.edit.spell", []string{"F7"}, "", inEditor((*App).spellSuggest)},
+		{"editor.backspace", "", "help.edit.backspace", []string{"Backspace"}, "", inEditor((*App).deleteBackward)},
+		{"editor.delete", "", "help.edit.delete", []string{"Delete"}, "right", inEditor((*App).deleteForward)},
+
+		{"preview.top", "help.ctx.preview", "help.preview.top", []string{"g g", "Home"}, "preview", (*App).previewTop},
+		{"preview.bottom", "help.ctx.preview", "help.preview.bottom", []string{"G", "End"}, "preview", (*App).previewBottom},
+		{"preview.halfDown", "help.ctx.preview", "help.preview.half_down", []string{"Ctrl+D"}, "preview", func(a *App) { a.previewScrollPage(1, 2) }},
+		{"preview.halfUp", "help.ctx.preview", "help.preview.half_up", []string{"Ctrl+U"}, "preview", func(a *App) { a.previewScrollPage(-1, 2) }},
+		{"preview.pageDown", "help.ctx.preview", "help.preview.page_down", []string{"PgDn"}, "preview", func(a *App) { a.previewScrollPage(1, 1) }},
+		{"preview.pageUp", "help.ctx.preview", "help.preview.page_up", []string{"PgUp"}, "preview", func(a *App) { a.previewScrollPage(-1, 1) }},
+		{"preview.percent", "help.ctx.preview", "help.preview.percent", []string{"%"}, "preview", (*App).previewPercentPrompt},
+		{"preview.clearSearch", "help.ctx.preview", "help.preview.clear_search", []string{"Esc"}, "preview", (*App).clearPreviewSearch},
+
+		{"window.splitVertical", "help.ctx.windows", "help.win.vsplit", []string{"Ctrl+W v"}, "", func(a *App) { a.splitView("vertical") }},
+		{"window.splitHorizontal", "help.ctx.windows", "help.win.hsplit", []string{"Ctrl+W s"}, "", func(a *App) { a.splitView("horizontal") }},
+		{"window.next", "help.ctx.windows", "help.win.next", []string{"Ctrl+W w", "Ctrl+W Ctrl+W", "Ctrl+W Tab"}, "", (*App).nextView},
+		{"window.close", "help.ctx.windows", "help.win.close", []string{"Ctrl+W q", "Ctrl+W c"}, "", (*App).closeView},
+		{"window.only", "help.ctx.windows", "help.win.only", []string{"Ctrl+W o"}, "", (*App).onlyView},
+		{"window.grow", "help.ctx.windows", "help.win.grow", []string{"Ctrl+W +", "Ctrl+W >"}, "", func(a *App) { a.resizeSplit(5) }},
+		{"window.shrink", "help.ctx.windows", "help.win.shrink", []string{"Ctrl+W -", "Ctrl+W <"}, "", func(a *App) { a.resizeSplit(-5) }},
+		{"window.equal", "help.ctx.windows", "help.win.equal", []string{"Ctrl+W ="}, "", func(a *App) { a.splitRatio = 50 }},
+
+		{"files.toggleHidden", "help.ctx.other", "help.files.hidden", []string{"."}, "", (*App).toggleHidden},
+		{"app.help", "help.ctx.other", "help.other.help", []string{"?"}, "", (*App).showHelp},
+		{"app.whichKey", "help.ctx.other", "help.other.which_key", []string{"F1"}, "", func(a *App) { a.pushOverlay(&whichKeyOverlay{}) }},
+		{"app.palette", "help.ctx.other", "help.other.palette", []string{"Ctrl+P"}, "", (*App).commandPalette},
+		{"app.messages", "help.ctx.other", "help.other.messages", []string{"Alt+m"}, "", (*App).openMessages},
+		{"app.stats", "help.ctx.other", "help.other.stats", []string{"Alt+s"}, "", (*App).showStats},
+		{"app.tags", "help.ctx.other", "help.other.tags", []string{"Alt+t"}, "", (*App).showTags},
+		{"app.todo", "help.ctx.other", "help.other.todo", []string{"Alt+g"}, "", (*App).showTodos},
+		{"app.links", "help.ctx.other", "help.other.links", []string{"Alt+l"}, "", (*App).checkLinks},
+		{"app.linksDir", "help.ctx.other", "help.other.links_dir", []string{"Alt+L"}, "", (*App).checkLinksInDir},
+		{"app.assets", "help.ctx.other", "help.other.assets", []string{"Alt+a"}, "", (*App).showAssets},
+		{"app.shell", "help.ctx.other", "help.other.shell", []string{"Alt+!"}, "", (*App).shellPrompt},
+		{"theme.pick", "help.ctx.other", "help.other.themes", []string{"Alt+T"}, "", (*App).themePicker},
+		{"theme.edit", "help.ctx.other", "help.other.theme_edit", []string{"Alt+E"}, "", (*App).openThemeEditor},
+		{"app.settings", "help.ctx.other", "help.other.settings", []string{"Alt+,"}, "", (*App).openSettings},
+		{"theme.reload", "help.ctx.other", "help.other.theme", []string{"Ctrl+R"}, "", (*App).reloadTheme},
+		{"app.quit", "help.ctx.other", "help.other.quit", []string{"Ctrl+Q"}, "", (*App).quit},
+		{"plugins.list", "", "plugin.title", nil, "", (*App).showPlugins},
+		{"theme.dump", "", "themedump.title", nil, "", (*App).dumpThemePrompt},
+	}
+	// операции над строками и регистр — только в палитре, с клавиатуры
+	// через меню Alt+S и Alt+C
+	for _, op := range lineOps {
+		cmds = append(cmds, &command{name: op.name, desc: op.desc, run: func(a *App) { a.runLineOp(op.name) }})
+	}
+	for _, op := range caseOps {
+		cmds = append(cmds, &command{name: op.name, desc: op.desc, run: func(a *App) { a.runCaseOp(op.name) }})
+	}
+	return cmds
+}
+
+// Заполнить реестр встроенными командами
+func (a *App) initCommands() {
+	a.commands = nil
+	a.bindings = map[string][]*command{}
+	for _, c := range builtinCommands() {
+		a.registerCommand(c)
+	}
+}
+
+// Добавить команду (или заменить одноимённую) и привязать её клавиши
+func (a *App) registerCommand(c *command) {
+	if old := a.command(c.name); old != nil {
+		a.unbindCommand(old)
+		*old = *c
+		c = old
+	} else {
+		a.commands = append(a.commands, c)
+	}
+	for _, k := range c.keys {
+		a.bindings[k] = append(a.bindings[k], c)
+	}
+}
+
+// Снять все привязки команды
+func (a *App) unbindCommand(c *command) {
+	for _, k := range c.keys {
+		bs := a.bindings[k][:0]
+		for _, b := range a.bindings[k] {
+			if b != c {
+				bs = append(bs, b)
+			}
+		}
+		a.bindings[k] = bs
+	}
+}
+
+// Команда по имени (nil — нет такой)
+func (a *App) command(name string) *command {
+	for _, c := range a.commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Выполнить команду по имени; false — команды нет или её делает ядро
+func (a *App) runCommand(name string) bool {
+	c := a.command(name)
+	if c == nil || c.run == nil {
+		return false
+	}
+	c.run(a)
+	return true
+}
+
+// Команда, привязанная к клавише в активной панели; из нескольких
+// побеждает привязанная последней (плагины перекрывают встроенные)
+func (a *App) boundCommand(key string) *command {
+	bs := a.bindings[key]
+	for i := len(bs) - 1; i >= 0; i-- {
+		if a.commandActive(bs[i]) {
+			return bs[i]
+		}
+	}
+	return nil
+}
+
+// Действуют ли клавиши команды в активной панели
+func (a *App) commandActive(c *command) bool {
+	switch c.panel {
+	case "":
+	case "preview":
+		if a.activePanel != "right" || a.view.mode != "preview" {
+			return false
+		}
+	default:
+		if c.panel != a.activePanel {
+			return false
+		}
+	}
+	return c.run != nil
+}
+
+// Начинает ли клавиша префиксную команду
+func (a *App) isPrefixKey(key string) bool {
+	for k, cs := range a.bindings {
+		if !strings.HasPrefix(k, key+" ") {
+			continue
+		}
+		for _, c := range cs {
+			if a.commandActive(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Выполнить команду по нажатию; false — клавиша остаётся ядру
+func (a *App) dispatchKey(ev *tcell.EventKey) bool {
+	key := keyName(ev)
+	if a.pendingPrefix != "" {
+		// неизвестное продолжение просто отменяет префикс
+		key = a.pendingPrefix + " " + key
+		a.pendingPrefix = ""
+		if c := a.boundCommand(key); c != nil {
+			c.run(a)
+		}
+		return true
+	}
+	if c := a.boundCommand(key); c != nil {
+		c.run(a)
+		return true
+	}
+	if a.isPrefixKey(key) {
+		a.pendingPrefix = key
+		a.startPrefix()
+		return true
+	}
+	return false
+}
+
+// Ctrl+P: палитра команд с поиском по названию
+func (a *App) commandPalette() {
+	var items []listItem
+	for _, c := range a.commands {
+		if c.run == nil || c.name == "app.palette" {
+			continue
+		}
+		detail := c.name
+		if len(c.keys) > 0 {
+			detail = c.keys[0] + "  " + c.name
+		}
+		items = append(items, listItem{label: tr(c.desc), detail: detail, value: c.name})
+	}
+	a.pick(tr("palette.title"), items, func(it listItem) {
+		a.runCommand(it.value)
+	})
+}
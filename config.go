@@ -0,0 +1,458 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ---- Настройки приложения (TOML) ----
+//
+// Файл настроек: ~/.config/eddy/config.toml (см. configDir)
+//
+// Пример:
+//
+// language = "auto"   # "en", "ru" или "auto" (по LANG)
+// colors = "auto"     # "truecolor", "256", "16" или "auto" (см. internal/theme/colors.go)
+// background = "auto" # "light", "dark" или "auto" (см. termbg.go)
+//
+// [editor]
+// scrolloff = 3
+// ruler = 80
+// external = "nvim"
+// timestamps = ["2006-01-02", "2006-01-02 15:04"]
+//
+// [preview]
+// typography = true
+//
+// [statusbar]
+// left = ["panel", "mode", "file", "modified"]
+// right = ["position", "percent", "lines", "wordcount"]
+//
+// [spell]
+// enabled = true
+// dictionaries = ["/usr/share/hunspell/en_US.dic"]
+//
+// [notes]
+// assets_dir = "assets"
+//
+// [undo]
+// group_pause_ms = 1000
+// max_memory_mb = 16
+// persist = false
+//
+// [accessibility]
+// no_color = "auto"  # "on", "off" или "auto" (по NO_COLOR)
+// min_contrast = 4.5
+//
+// [confirm]
+// delete = true
+// overwrite = true
+// quit_unsaved = true
+// reload = true
+//
+// [export.formats.pdf]
+// ext = ".pdf"
+// command = ["pandoc", "{input}", "-o", "{output}", "--pdf-engine=xelatex"]
+//
+// [[hooks.on_save]]
+// match = "*.md"
+// run = "git add {file}"
+//
+// [format.formatters.markdown]
+// ext = [".md", ".markdown"]
+// command = ["prettier", "--parser", "markdown"]
+// on_save = true
+//
+// Отсутствующие ключи берутся из defaultConfig().
+
+// EditorConfig — настройки редактора
+type EditorConfig struct {
+	// Сколько строк контекста держать выше и ниже курсора при прокрутке
+	ScrollOff int `toml:"scrolloff"`
+	// Колонка вертикальной направляющей в режиме edit (0 — выключена)
+	Ruler int `toml:"ruler"`
+	// Внешний редактор для Alt+e (пусто — $VISUAL, $EDITOR или vi)
+	External string `toml:"external"`
+	// Форматы даты для Alt+i (раскладки Go: 2006-01-02 15:04);
+	// если их несколько, показывается список
+	Timestamps []string `toml:"timestamps"`
+}
+
+// PreviewConfig — предпросмотр Markdown
+type PreviewConfig struct {
+	// Типографика: -- и --- как тире, "прямые" кавычки как «фигурные»,
+	// ... как многоточие (только на экране, текст не меняется)
+	Typography bool `toml:"typography"`
+	// Переносить длинные строки по словам (иначе — прокрутка влево-вправо)
+	Wrap bool `toml:"wrap"`
+}
+
+// PanelConfig — панель файлов (см. panelsize.go)
+type PanelConfig struct {
+	// Ширина: число колонок ("30") или процент ширины терминала ("25%")
+	Width string `toml:"width"`
+}
+
+// StatusbarConfig — раскладка статусной строки (см. statusbar.go)
+type StatusbarConfig struct {
+	Left      []string `toml:"left"`
+	Right     []string `toml:"right"`
+	Separator string   `toml:"separator"`
+}
+
+// SpellConfig — проверка орфографии (см. spell.go)
+type SpellConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Словари hunspell (.dic) или списки слов; отсутствующие пропускаются
+	Dictionaries []string `toml:"dictionaries"`
+}
+
+// ExportFormat — внешняя программа экспорта (см. export.go).
+// В аргументах подставляются {input} и {output}.
+type ExportFormat struct {
+	Ext     string   `toml:"ext"`
+	Command []string `toml:"command"`
+}
+
+// ExportConfig — форматы экспорта по имени
+type ExportConfig struct {
+	Formats map[string]ExportFormat `toml:"formats"`
+}
+
+// Formatter — внешний форматировщик (см. format.go): текст подаётся
+// на stdin, результат читается из stdout. В аргументах подставляется {file}.
+type Formatter struct {
+	Ext     []string `toml:"ext"`
+	Command []string `toml:"command"`
+	// Форматировать перед каждым сохранением
+	OnSave bool `toml:"on_save"`
+}
+
+// FormatConfig — форматировщики по имени
+type FormatConfig struct {
+	Formatters map[string]Formatter `toml:"formatters"`
+}
+
+// Hook — shell-команда после открытия или сохранения файла (см. hooks.go).
+// {file} заменяется путём файла в кавычках.
+type Hook struct {
+	// Шаблон имени файла ("*.md"); пусто — любые файлы
+	Match string `toml:"match"`
+	Run   string `toml:"run"`
+}
+
+// HooksConfig — команды по событиям
+type HooksConfig struct {
+	OnOpen []Hook `toml:"on_open"`
+	OnSave []Hook `toml:"on_save"`
+}
+
+// NotesConfig — заметки и вложения (см. assets.go)
+type NotesConfig struct {
+	// Папка вложений относительно документа
+	AssetsDir string `toml:"assets_dir"`
+}
+
+// UndoConfig — история отмены (см. undo.go)
+type UndoConfig struct {
+	// Пауза (мс), после которой набор начинает новый шаг отмены
+	GroupPause int `toml:"group_pause_ms"`
+	// Предел памяти истории на буфер, МБ (0 — без предела)
+	MaxMemory int `toml:"max_memory_mb"`
+	// Сохранять историю между запусками (~/.local/state/eddy/undo)
+	Persist bool `toml:"persist"`
+}
+
+// AccessibilityConfig — доступность (см. internal/theme/accessibility.go)
+type AccessibilityConfig struct {
+	// Без цветов: "on", "off" или "auto" (по переменной NO_COLOR)
+	NoColor string `toml:"no_color"`
+	// Минимальный контраст текста и фона (WCAG, 1–21); 0 — не проверять
+	MinContrast float64 `toml:"min_contrast"`
+}
+
+// ConfirmConfig — какие действия переспрашивают (false — выполнять сразу)
+type ConfirmConfig struct {
+	// Удаление файла
+	Delete bool `toml:"delete"`
+	// Перезапись существующих файлов (загрузка, распаковка)
+	Overwrite bool `toml:"overwrite"`
+	// Выход при несохранённых изменениях
+	QuitUnsaved bool `toml:"quit_unsaved"`
+	// Перечитывание файла, изменённого снаружи, поверх несохранённых правок
+	Reload bool `toml:"reload"`
+}
+
+// Config — корневая структура настроек
+type Config struct {
+	// Язык интерфейса: "en", "ru" или "auto" (см. i18n.go)
+	Language string `toml:"language"`
+	// Глубина цвета: "auto", "truecolor", "256", "16"
+	Colors string `toml:"colors"`
+	// Фон терминала: "auto", "light", "dark"
+	Background string `toml:"background"`
+	// Прозрачный фон: не закрашивать фон терминала (то же, что ui.transparent в теме)
+	Transparent bool `toml:"transparent"`
+	// Тема: имя встроенной (см. gallery.go) или путь; пусто — theme.toml
+	Theme     string          `toml:"theme"`
+	Editor    EditorConfig    `toml:"editor"`
+	Preview   PreviewConfig   `toml:"preview"`
+	Panel     PanelConfig     `toml:"panel"`
+	Statusbar StatusbarConfig `toml:"statusbar"`
+	Spell     SpellConfig     `toml:"spell"`
+	Notes     NotesConfig     `toml:"notes"`
+	Export    ExportConfig    `toml:"export"`
+	Format    FormatConfig    `toml:"format"`
+	Hooks     HooksConfig     `toml:"hooks"`
+	Undo      UndoConfig      `toml:"undo"`
+	// Доступность: режим без цветов и минимальный контраст
+	Accessibility AccessibilityConfig `toml:"accessibility"`
+	Confirm       ConfirmConfig       `toml:"confirm"`
+}
+
+// Настройки по умолчанию. Каждый вызов строит новое значение: toml
+// пишет в срезы и карты на месте, и разбор файла не должен менять
+// значения по умолчанию (их использует и сброс в настройках).
+func defaultConfig() Config {
+	return Config{
+		Language:   "auto",
+		Colors:     "auto",
+		Background: "auto",
+		Editor: EditorConfig{
+			ScrollOff:  3,
+			Ruler:      80,
+			Timestamps: []string{"2006-01-02", "2006-01-02 15:04", "Monday, 2 January 2006"},
+		},
+		Preview: PreviewConfig{
+			Wrap: true,
+		},
+		Panel: PanelConfig{
+			Width: "30",
+		},
+		Statusbar: StatusbarConfig{
+			Left:      []string{"panel", "mode", "file", "modified"},
+			Right:     []string{"position", "percent", "lines", "wordcount"},
+			Separator: " | ",
+		},
+		Spell: SpellConfig{
+			Enabled: true,
+			Dictionaries: []string{
+				"/usr/share/hunspell/en_US.dic",
+				"/usr/share/hunspell/ru_RU.dic",
+			},
+		},
+		Notes: NotesConfig{
+			AssetsDir: "assets",
+		},
+		Undo: UndoConfig{
+			GroupPause: 1000,
+			MaxMemory:  16,
+		},
+		Accessibility: AccessibilityConfig{
+			NoColor: "auto",
+		},
+		Confirm: ConfirmConfig{
+			Delete:      true,
+			Overwrite:   true,
+			QuitUnsaved: true,
+			Reload:      true,
+		},
+		Export: ExportConfig{
+			Formats: map[string]ExportFormat{
+				"pdf":  {Ext: ".pdf", Command: []string{"pandoc", "{input}", "-o", "{output}"}},
+				"odt":  {Ext: ".odt", Command: []string{"pandoc", "{input}", "-o", "{output}"}},
+				"docx": {Ext: ".docx", Command: []string{"pandoc", "{input}", "-o", "{output}"}},
+			},
+		},
+		Format: FormatConfig{
+			Formatters: map[string]Formatter{
+				"markdown": {Ext: []string{".md", ".markdown"}, Command: []string{"prettier", "--parser", "markdown"}},
+				"go":       {Ext: []string{".go"}, Command: []string{"gofmt"}},
+			},
+		},
+	}
+}
+
+// Папка настроек из флага --config-dir (пусто — по окружению)
+var configDirFlag string
+
+// Папка пользовательских настроек: --config-dir, $EDDY_CONFIG_DIR,
+// $XDG_CONFIG_HOME/eddy, иначе папка настроек системы (os.UserConfigDir):
+// ~/.config/eddy, ~/Library/Application Support/eddy, %AppData%\eddy.
+// Прежняя ~/.config/myapp читается, пока новой папки нет.
+func configDir() string {
+	if configDirFlag != "" {
+		return configDirFlag
+	}
+	if dir := os.Getenv("EDDY_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	// относительный XDG_CONFIG_HOME по спецификации игнорируется
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(base) {
+		var err error
+		if base, err = os.UserConfigDir(); err != nil {
+			// $HOME есть, но XDG_CONFIG_HOME относительный
+			home, err := os.UserHomeDir()
+			if err != nil || home == "" {
+				return "."
+			}
+			base = filepath.Join(home, ".config")
+		}
+	}
+	dir := filepath.Join(base, appName)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if home, err := os.UserHomeDir(); err == nil {
+			legacy := filepath.Join(home, ".config", "myapp")
+			if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+				return legacy
+			}
+		}
+	}
+	return dir
+}
+
+// Получить путь к файлу настроек: ~/.config/eddy/config.toml
+func configPath() string {
+	userPath := filepath.Join(configDir(), "config.toml")
+	if _, err := os.Stat(userPath); err == nil {
+		return userPath
+	}
+	// fallback на файл рядом с бинарником
+	return "./config.toml"
+}
+
+// Загрузка настроек поверх значений по умолчанию
+func loadConfigFromFile(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("cannot access config file: %v", err)
+	}
+
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("failed to parse config: %v", err)
+	}
+	return cfg, nil
+}
+
+// загрузка настроек: при ошибке остаются значения по умолчанию
+func (a *App) loadConfig() {
+	cfg, err := loadConfigFromFile(configPath())
+	if err != nil {
+		a.config = defaultConfig()
+		uiLang = detectLanguage(a.config.Language)
+		a.notify(levelWarning, tr("config.load_failed"), err)
+		return
+	}
+	a.config = cfg
+	uiLang = detectLanguage(a.config.Language)
+	a.applyPanelWidth()
+}
+
+// Записать ключ в config.toml, не трогая остальной текст. Ключ с точкой
+// ("editor.ruler") пишется в свою таблицу; нет таблицы — она дописывается
+// в конец файла.
+func setConfigValue(key string, value interface{}) error {
+	path := configPath()
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(configDir(), "config.toml")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	table, name := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		table, name = key[:i], key[i+1:]
+	}
+	line := name + " = " + tomlLiteral(value)
+	lines := strings.Split(string(data), "\n")
+
+	// тело таблицы: строки [from, to); ключи верхнего уровня — до первой таблицы
+	from := 0
+	if table != "" {
+		from = -1
+		for i, l := range lines {
+			if strings.TrimSpace(l) == "["+table+"]" {
+				from = i + 1
+				break
+			}
+		}
+		if from < 0 {
+			text := strings.TrimRight(string(data), "\n")
+			if text != "" {
+				text += "\n\n"
+			}
+			text += "[" + table + "]\n" + line + "\n"
+			return os.WriteFile(path, []byte(text), 0644)
+		}
+	}
+	to := len(lines)
+	for i := from; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			to = i
+			break
+		}
+	}
+
+	keyRe := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(name) + `\s*=`)
+	for i := from; i < to; i++ {
+		if keyRe.MatchString(lines[i]) {
+			lines[i] = line + trailingComment(lines[i])
+			return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+		}
+	}
+	// новый ключ — после последнего ключа таблицы
+	at := from
+	for i := from; i < to; i++ {
+		if l := strings.TrimSpace(lines[i]); l != "" && !strings.HasPrefix(l, "#") {
+			at = i + 1
+		}
+	}
+	lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// Комментарий в конце строки "ключ = значение  # ..." (с отступом перед ним)
+func trailingComment(line string) string {
+	quote := rune(0)
+	for i, r := range line {
+		switch {
+		case quote != 0 && r == quote && (i == 0 || line[i-1] != '\\'):
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#':
+			start := i
+			for start > 0 && (line[start-1] == ' ' || line[start-1] == '\t') {
+				start--
+			}
+			return line[start:]
+		}
+	}
+	return ""
+}
+
+// Значение в записи TOML
+func tomlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigKeepsDefaults(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	data := `
+[editor]
+timestamps = ["15:04"]
+
+[statusbar]
+left = ["file", "mode"]
+
+[spell]
+dictionaries = ["/tmp/x.dic"]
+
+[export.formats.pdf]
+ext = ".pdf"
+command = ["wkhtmltopdf", "{input}", "{output}"]
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfigFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"file", "mode"}; !reflect.DeepEqual(cfg.Statusbar.Left, want) {
+		t.Errorf("statusbar.left = %q, want %q", cfg.Statusbar.Left, want)
+	}
+	if got := cfg.Export.Formats["pdf"].Command[0]; got != "wkhtmltopdf" {
+		t.Errorf("export pdf command = %q", got)
+	}
+
+	// файл не должен менять значения по умолчанию
+	def := defaultConfig()
+	missing, err := loadConfigFromFile(filepath.Join(dir, "none.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(missing, def) {
+		t.Errorf("defaults changed after loading a config:\n got %+v\nwant %+v", missing, def)
+	}
+
+	// и правка загруженных настроек — тоже
+	cfg.Statusbar.Right[0] = "changed"
+	cfg.Export.Formats["odt"].Command[0] = "changed"
+	if again := defaultConfig(); !reflect.DeepEqual(again, def) {
+		t.Errorf("defaults changed after editing a loaded config")
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import "strings"
+
+// ---- Конфликты слияния ----
+//
+// Блоки <<<<<<< / ======= / >>>>>>> (и ||||||| общего предка в стиле
+// diff3) подсвечиваются в редакторе: маркеры, наша и их сторона — своими
+// цветами (секция [ui.conflict] темы). Alt+k на конфликте предлагает
+// оставить нашу сторону, их или обе; вне конфликта — переходит к
+// следующему. Разрешение конфликта повторяется по Alt+.
+
+// Блок конфликта: номера строк маркеров (base = -1, если предка нет)
+type conflictBlock struct {
+	start, base, mid, end int
+}
+
+// Вид строки в блоке конфликта (индекс в ResolvedTheme.Conflict)
+const (
+	conflictNone = iota - 1
+	conflictMarker
+	conflictOurs
+	conflictTheirs
+)
+
+func isConflictMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+// Все законченные блоки конфликтов
+func findConflicts(lines []string) []conflictBlock {
+	var blocks []conflictBlock
+	cur := conflictBlock{start: -1}
+	for i, line := range lines {
+		switch {
+		case isConflictMarker(line, "<<<<<<<"):
+			cur = conflictBlock{start: i, base: -1, mid: -1}
+		case cur.start < 0:
+		case isConflictMarker(line, "|||||||") && cur.mid < 0:
+			cur.base = i
+		case line == "=======" && cur.mid < 0:
+			cur.mid = i
+		case isConflictMarker(line, ">>>>>>>") && cur.mid >= 0:
+			cur.end = i
+			blocks = append(blocks, cur)
+			cur = conflictBlock{start: -1}
+		}
+	}
+	return blocks
+}
+
+// Вид каждой строки для подсветки; nil — конфликтов нет
+func conflictKinds(content string, lines []string) []int {
+	if !strings.Contains(content, "<<<<<<<") {
+		return nil
+	}
+	blocks := findConflicts(lines)
+	if len(blocks) == 0 {
+		return nil
+	}
+	kinds := make([]int, len(lines))
+	for i := range kinds {
+		kinds[i] = conflictNone
+	}
+	for _, b := range blocks {
+		ours := b.mid
+		if b.base >= 0 {
+			ours = b.base
+		}
+		for i := b.start; i <= b.end; i++ {
+			switch {
+			case i == b.start || i == b.mid || i == b.end || (i >= b.base && i < b.mid && b.base >= 0):
+				kinds[i] = conflictMarker // маркеры и текст общего предка
+			case i < ours:
+				kinds[i] = conflictOurs
+			default:
+				kinds[i] = conflictTheirs
+			}
+		}
+	}
+	return kinds
+}
+
+// Alt+k: разрешить конфликт под курсором или перейти к следующему
+func (a *App) conflictMenu() {
+	if a.activePanel != "right" || a.view.mode != "edit" {
+		return
+	}
+	lines := a.getLines()
+	blocks := findConflicts(lines)
+	if len(blocks) == 0 {
+		a.notify(levelInfo, "%s", tr("conflict.none"))
+		return
+	}
+	y := a.view.editY
+	for _, b := range blocks {
+		if y >= b.start && y <= b.end {
+			a.pick(tr("conflict.title"), []listItem{
+				{label: tr("conflict.ours"), detail: strings.TrimSpace(strings.TrimPrefix(lines[b.start], "<<<<<<<")), value: "ours"},
+				{label: tr("conflict.theirs"), detail: strings.TrimSpace(strings.TrimPrefix(lines[b.end], ">>>>>>>")), value: "theirs"},
+				{label: tr("conflict.both"), value: "both"},
+			}, func(item listItem) {
+				if !a.checkWritable() {
+					return
+				}
+				a.repeatable(func() { a.resolveConflict(item.value) })
+			})
+			return
+		}
+	}
+	// следующий конфликт после курсора (или первый)
+	next := blocks[0]
+	for _, b := range blocks {
+		if b.start > y {
+			next = b
+			break
+		}
+	}
+	a.view.editY, a.view.editX = next.start, 0
+	a.view.clearSelection()
+	a.ensureCursorVisible()
+	a.notify(levelInfo, tr("conflict.count"), len(blocks))
+}
+
+// Оставить сторону keep ("ours", "theirs" или "both") конфликта под курсором
+func (a *App) resolveConflict(keep string) {
+	lines := a.getLines()
+	y := a.view.editY
+	for _, b := range findConflicts(lines) {
+		if y < b.start || y > b.end {
+			continue
+		}
+		oursEnd := b.mid
+		if b.base >= 0 {
+			oursEnd = b.base
+		}
+		ours := lines[b.start+1 : oursEnd]
+		theirs := lines[b.mid+1 : b.end]
+		var kept []string
+		switch keep {
+		case "ours":
+			kept = ours
+		case "theirs":
+			kept = theirs
+		default:
+			kept = append(append(kept, ours...), theirs...)
+		}
+		out := append([]string{}, lines[:b.start]...)
+		out = append(out, kept...)
+		out = append(out, lines[b.end+1:]...)
+		a.setLines(out)
+		a.view.editY, a.view.editX = b.start, 0
+		a.clampCursor()
+		a.ensureCursorVisible()
+		return
+	}
+}
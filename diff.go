@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Несохранённые изменения (Alt+=) ----
+//
+// «Что я поменял?»: единый diff между файлом на диске и текстом в
+// редакторе, как у git diff — удалённые строки красным, добавленные
+// зелёным, по три строки контекста вокруг каждого изменения. Само
+// сравнение — internal/editor/diff.go.
+
+// Alt+=: показать, что изменено в редакторе по сравнению с файлом на диске
+func (a *App) diffUnsaved() {
+	buf := a.view.buf
+	if buf.path == "" || isWebURL(buf.path) {
+		a.notify(levelInfo, "%s", tr("diff.no_file"))
+		return
+	}
+	if isRemote(buf.path) {
+		// с сервера — в фоне (см. remote.go)
+		go func() {
+			data, err := readRemoteFile(buf.path)
+			a.post(func() { a.showUnsavedDiff(buf, data, err) })
+		}()
+		return
+	}
+	data, err := readAnyFile(buf.path)
+	a.showUnsavedDiff(buf, data, err)
+}
+
+// Показать разницу между текстом буфера и прочитанным файлом
+func (a *App) showUnsavedDiff(buf *buffer, data []byte, err error) {
+	if err != nil {
+		a.notify(levelError, tr("file.read_error"), err)
+		return
+	}
+	name := filepath.Base(buf.path)
+	text := editor.UnifiedDiff(trf("diff.disk", name), trf("diff.editor", name),
+		editor.DiffLines(strings.Split(string(data), "\n"), buf.Lines()))
+	if text == "" {
+		a.notify(levelInfo, "%s", tr("diff.none"))
+		return
+	}
+	a.pushOverlay(&textViewOverlay{
+		title:     trf("diff.title", name),
+		lines:     strings.Split(text, "\n"),
+		lineStyle: a.diffLineStyle,
+	})
+}
+
+// Цвет строки diff: фон сообщений об ошибке и успехе
+func (a *App) diffLineStyle(line string) tcell.Style {
+	styles := a.getStyles()
+	st := styles.Dialog
+	color := func(level msgLevel) tcell.Style {
+		_, bg, _ := styles.Notify[level].Decompose()
+		if bg == tcell.ColorDefault {
+			return st.body.Bold(true)
+		}
+		return st.body.Foreground(bg)
+	}
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return st.body.Bold(true)
+	case strings.HasPrefix(line, "@@"):
+		return st.dim
+	case strings.HasPrefix(line, "+"):
+		return color(levelSuccess)
+	case strings.HasPrefix(line, "-"):
+		return color(levelError)
+	}
+	return st.body
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/StasKrav/eddy_tcell/internal/filer"
+)
+
+// ---- Чтение больших папок ----
+//
+// Папка читается порциями по dirChunk записей. Первая порция — сразу,
+// так что обычные папки показываются как раньше, без задержки. Если
+// записей больше, остальное дочитывается в фоне: порции добавляются в
+// список по мере чтения, в заголовке панели крутится индикатор с
+// числом прочитанных. Переход в другую папку (или повторное чтение)
+// прерывает фоновое чтение. Отметки и размеры папок (см. diskusage.go)
+// обновляются, когда папка прочитана целиком.
+//
+// Прочитанное копится в dirLoad.pending, а горутины только будят
+// главный цикл: a.post при полной очереди событий теряется, так что
+// индикатор, тикающий до конца чтения, заодно забирает пропущенное.
+
+const (
+	dirChunk        = 1000
+	spinnerInterval = 100 * time.Millisecond
+)
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// Фоновое чтение папки
+type dirLoad struct {
+	dir     string
+	stop    atomic.Bool
+	started time.Time
+
+	mu      sync.Mutex
+	pending [][]os.DirEntry // прочитано, но ещё не в списке
+	done    bool            // прочитано всё
+	err     error           // чтение не удалось (удалённая папка, см. remote.go)
+
+	// файл, на который встанет курсор, когда он появится в списке
+	// (только в главном цикле, см. selectFile)
+	selected string
+}
+
+// Прервать фоновое чтение, если оно идёт
+func (a *App) cancelDirLoad() {
+	if a.dirLoad != nil {
+		a.dirLoad.stop.Store(true)
+		a.dirLoad = nil
+	}
+}
+
+// Прочитать папку с диска: первую порцию сразу, остальное в фоне
+func (a *App) loadLocalFiles() {
+	f, err := os.Open(a.currentDir)
+	if err != nil {
+		return
+	}
+	entries, err := f.ReadDir(dirChunk)
+	a.addEntries(a.currentDir, entries)
+	if err != nil || len(entries) < dirChunk {
+		f.Close()
+		return
+	}
+
+	dl := &dirLoad{dir: a.currentDir, started: time.Now()}
+	a.dirLoad = dl
+	go func() {
+		defer f.Close()
+		for !dl.stop.Load() {
+			entries, err := f.ReadDir(dirChunk)
+			dl.mu.Lock()
+			dl.pending = append(dl.pending, entries)
+			dl.done = err != nil
+			dl.mu.Unlock()
+			a.post(func() { a.drainDirLoad(dl) })
+			if err != nil {
+				return
+			}
+		}
+	}()
+	a.spinDirLoad(dl)
+}
+
+// Крутить индикатор, пока чтение не закончится: он крутится, даже
+// если порции читаются медленно
+func (a *App) spinDirLoad(dl *dirLoad) {
+	go func() {
+		for !dl.stop.Load() {
+			time.Sleep(spinnerInterval)
+			a.post(func() { a.drainDirLoad(dl) })
+		}
+	}()
+}
+
+// Перенести прочитанное в список (в главном цикле)
+func (a *App) drainDirLoad(dl *dirLoad) {
+	if a.dirLoad != dl {
+		return
+	}
+	dl.mu.Lock()
+	pending, done, err := dl.pending, dl.done, dl.err
+	dl.pending = nil
+	dl.mu.Unlock()
+
+	if len(pending) > 0 {
+		for _, entries := range pending {
+			a.addEntries(dl.dir, entries)
+		}
+		a.sortFiles()
+		if sel := dl.selected; sel != "" {
+			dl.selected = ""
+			a.selectFile(sel) // не нашёлся — подождёт следующей порции
+		}
+	}
+	if !done {
+		return
+	}
+	// папка прочитана целиком
+	dl.stop.Store(true)
+	a.dirLoad = nil
+	a.cursor = min(a.cursor, max(len(a.files)-1, 0))
+	if err != nil {
+		a.notify(levelError, tr("remote.failed"), err)
+	}
+	a.pruneMarks()
+	a.refreshDiskUsage()
+}
+
+// Добавить прочитанные записи в список
+func (a *App) addEntries(dir string, entries []os.DirEntry) {
+	for _, entry := range entries {
+		// Пропускаем скрытые файлы если не включен их показ
+		if !a.showHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		a.files = append(a.files, fileItem{
+			name:  entry.Name(),
+			path:  childPath(dir, entry.Name()),
+			isDir: entry.IsDir(),
+		})
+	}
+}
+
+// Путь записи name в папке dir (в том числе удалённой)
+func childPath(dir, name string) string {
+	if r, ok := parseRemote(dir); ok {
+		return r.with(path.Join(r.path, name)).String()
+	}
+	return filepath.Join(dir, name)
+}
+
+// Отсортировать список по имени (см. natsort.go), сохранив файл под курсором
+func (a *App) sortFiles() {
+	current := ""
+	if a.dirLoad != nil && a.cursor >= 0 && a.cursor < len(a.files) {
+		current = a.files[a.cursor].path
+	}
+	sort.SliceStable(a.files, func(i, j int) bool {
+		return filer.NaturalLess(a.files[i].name, a.files[j].name)
+	})
+	if current != "" {
+		a.selectFile(current)
+	}
+}
+
+// Индикатор чтения для заголовка панели
+func (a *App) dirLoadProgress() string {
+	dl := a.dirLoad
+	if dl == nil {
+		return ""
+	}
+	frame := int(time.Since(dl.started)/spinnerInterval) % len(spinnerFrames)
+	return trf("dir.loading", string(spinnerFrames[frame]), len(a.files))
+}
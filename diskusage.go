@@ -0,0 +1,168 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// ---- Размеры папок (Alt+d) ----
+//
+// Режим, как du: в списке файлов справа показывается размер каждого
+// элемента, у папок — суммарный размер всего содержимого. Размеры
+// считаются в фоне и обновляются по ходу подсчёта; в заголовке панели
+// виден прогресс. Alt+D сортирует список по размеру (самые большие
+// сверху) и обратно по имени. При переходе в другую папку подсчёт
+// начинается заново, незаконченный — прерывается.
+
+// Как часто показывать промежуточные суммы папки
+const duUpdateInterval = 200 * time.Millisecond
+
+// Подсчёт размеров текущей папки
+type diskUsage struct {
+	dir    string
+	sizes  map[string]int64 // путь -> размер (частичный, пока не done)
+	done   map[string]bool
+	total  int // элементов в папке
+	bySize bool
+	// номер подсчёта: ответы устаревших подсчётов отбрасываются
+	gen *atomic.Int64
+}
+
+// Alt+d: включить или выключить столбец размеров
+func (a *App) toggleDiskUsage() {
+	if a.du != nil {
+		a.du.gen.Add(1) // прервать подсчёт
+		a.du = nil
+		a.loadFiles()
+		return
+	}
+	a.du = &diskUsage{gen: &atomic.Int64{}}
+	a.loadFiles()
+}
+
+// Alt+D: сортировка по размеру или по имени
+func (a *App) toggleDiskUsageSort() {
+	if a.du == nil {
+		a.toggleDiskUsage()
+	}
+	a.du.bySize = !a.du.bySize
+	a.loadFiles()
+}
+
+// Вызывается после чтения папки: запустить подсчёт для новой папки
+// и отсортировать список
+func (a *App) refreshDiskUsage() {
+	du := a.du
+	if du == nil {
+		return
+	}
+	if du.dir != a.currentDir || du.total != len(a.files) {
+		a.startDiskUsage()
+	}
+	a.sortFilesBySize()
+}
+
+func (a *App) startDiskUsage() {
+	du := a.du
+	gen := du.gen.Add(1)
+	du.dir = a.currentDir
+	du.sizes = map[string]int64{}
+	du.done = map[string]bool{}
+	du.total = len(a.files)
+	items := append([]fileItem{}, a.files...)
+	go func() {
+		for _, f := range items {
+			if du.gen.Load() != gen {
+				return
+			}
+			path := f.path
+			size := dirSize(path, func(partial int64) bool {
+				a.post(func() {
+					if du.gen.Load() == gen && !du.done[path] {
+						du.sizes[path] = partial
+					}
+				})
+				return du.gen.Load() == gen
+			})
+			a.post(func() {
+				if du.gen.Load() != gen {
+					return
+				}
+				du.sizes[path] = size
+				du.done[path] = true
+				a.sortFilesBySize()
+			})
+		}
+	}()
+}
+
+// Размер файла или суммарный размер папки. progress вызывается не чаще
+// duUpdateInterval с частичной суммой; false — прервать подсчёт.
+func dirSize(root string, progress func(int64) bool) int64 {
+	var total int64
+	last := time.Now()
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		// ссылки не разыменовываем, как du
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		if time.Since(last) > duUpdateInterval {
+			last = time.Now()
+			if !progress(total) {
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	return total
+}
+
+// Отсортировать список по размеру (если включено), сохранив файл под курсором
+func (a *App) sortFilesBySize() {
+	du := a.du
+	if du == nil || !du.bySize {
+		return
+	}
+	current := ""
+	if a.cursor >= 0 && a.cursor < len(a.files) {
+		current = a.files[a.cursor].path
+	}
+	sort.SliceStable(a.files, func(i, j int) bool {
+		return du.sizes[a.files[i].path] > du.sizes[a.files[j].path]
+	})
+	for i, f := range a.files {
+		if f.path == current {
+			a.cursor = i
+		}
+	}
+}
+
+// Размер для строки списка; "…" — ещё считается
+func (a *App) duLabel(path string) string {
+	du := a.du
+	if size, ok := du.sizes[path]; ok {
+		label := formatSize(size)
+		if !du.done[path] {
+			label = "…" + label
+		}
+		return label
+	}
+	return "…"
+}
+
+// Прогресс для заголовка панели: " 3/12" или "" после окончания
+func (a *App) duProgress() string {
+	du := a.du
+	if du == nil || len(du.done) >= du.total {
+		return ""
+	}
+	return trf("du.progress", len(du.done), du.total)
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Правка текста ----
+//
+// Вспомогательное для правки в активном окне: вставка и удаление с
+// учётом графем, отступы, переход к строке и удержание курсора в
+// видимой области (scrolloff). Сам текст и отмена — pkg/buffer.
+
+// Переход к строке по номеру
+func (a *App) gotoLinePrompt() {
+	a.prompt(tr("goto.title"), "", func(text string) {
+		n, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			a.notify(levelWarning, tr("goto.bad"), text)
+			return
+		}
+		a.gotoLine(n)
+	})
+}
+
+// Переместить курсор на строку n (с 1)
+func (a *App) gotoLine(n int) {
+	a.view.editY = n - 1
+	a.view.editX = 0
+	a.clampCursor()
+	if a.view.mode == "preview" {
+		a.scrollPreviewTo(a.view, a.view.editY)
+	}
+	a.activePanel = "right"
+	a.ensureCursorVisible()
+}
+
+// Получить строки (гарантированно хотя бы одна)
+func (a *App) getLines() []string {
+	return a.view.buf.Lines()
+}
+
+// Проверить, является ли файл Markdown файлом
+func (a *App) isMarkdownFile() bool {
+	low := strings.ToLower(a.view.buf.path)
+	return strings.HasSuffix(low, ".md") || strings.HasSuffix(low, ".markdown")
+}
+
+// Получить последнее слово в строке
+func (a *App) getLastWord(line string) string {
+	// Удаляем пробелы в конце строки
+	trimmed := strings.TrimRight(line, " \t")
+
+	// Находим начало последнего слова (ищем любой символ, не являющийся буквой, цифрой или подчеркиванием)
+	end := len(trimmed)
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		r := rune(trimmed[i])
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			continue
+		} else {
+			// Нашли разделитель, возвращаем часть строки после него
+			return trimmed[i+1 : end]
+		}
+	}
+
+	// Вся строка состоит из одного слова
+	return trimmed
+
+}
+
+// Подсчитать слова в тексте (последовательности без пробелов)
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+// Проверить, является ли символ разделителем
+func (a *App) isWordSeparator(r rune) bool {
+	return !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_')
+}
+
+// Получить текущий уровень отступа строки
+func (a *App) getCurrentIndentLevel(line string) int {
+	indent := 0
+	for _, r := range line {
+		if r == '\t' {
+			indent++
+		} else if r == ' ' {
+			// Считаем 4 пробела как 1 табуляцию
+			indent++
+			// Пропускаем еще 3 пробела
+			// Но для этого нужно модифицировать логику
+		} else {
+			break
+		}
+	}
+	return indent
+}
+
+// Создать строку отступа заданного уровня
+func (a *App) createIndentString(level int) string {
+	// Используем табуляции для отступов
+	return strings.Repeat("\t", level)
+}
+
+// Установить строки обратно в fileContent
+func (a *App) setLines(lines []string) {
+	if !a.checkWritable() {
+		return
+	}
+	a.view.buf.SetLines(lines)
+}
+
+// Вставить текст (возможно многострочный) в позицию курсора
+func (a *App) insertText(text string) {
+	if text == "" || !a.checkWritable() {
+		return
+	}
+	a.view.editY, a.view.editX = a.view.buf.Insert(a.view.editY, a.view.editX, text)
+	a.ensureCursorVisible()
+}
+
+// Ограничить позицию курсора в пределах содержимого
+func (a *App) clampCursor() {
+	a.clampViewCursor(a.view)
+}
+
+// Ограничить позицию курсора заданного окна
+func (a *App) clampViewCursor(v *editorView) {
+	v.editY, v.editX = v.buf.Clamp(v.editY, v.editX)
+}
+
+// helper: display column (in cells) of rune index (sum widths of graphemes before upto)
+func runesDisplayWidth(runes []rune, upto int) int {
+	if upto <= 0 {
+		return 0
+	}
+	w := 0
+	for _, g := range editor.Graphemes(runes) {
+		if g.Start >= upto {
+			break
+		}
+		w += g.Width
+	}
+	return w
+}
+
+// Обеспечить видимость курсора (корректирует scrollX/Y)
+func (a *App) ensureCursorVisible() {
+	a.layout()
+	a.ensureViewCursorVisible(a.view)
+}
+
+// Отступ прокрутки для окна заданной высоты (не больше половины окна)
+func (a *App) scrollOff(editorHeight int) int {
+	so := a.config.Editor.ScrollOff
+	if so > (editorHeight-1)/2 {
+		so = (editorHeight - 1) / 2
+	}
+	if so < 0 {
+		so = 0
+	}
+	return so
+}
+
+// Обеспечить видимость курсора в заданном окне
+func (a *App) ensureViewCursorVisible(v *editorView) {
+	_, _, editorWidth, editorHeight := v.textArea()
+
+	lines := v.buf.CachedLines()
+
+	// вертикальная прокрутка (в строках) с отступом scrolloff от краёв
+	so := a.scrollOff(editorHeight)
+	if v.editY < v.scrollY+so {
+		v.scrollY = v.editY - so
+	} else if v.editY >= v.scrollY+editorHeight-so {
+		v.scrollY = v.editY - editorHeight + so + 1
+		// не прокручиваем дальше конца файла
+		if maxScroll := len(lines) - editorHeight; v.scrollY > maxScroll && maxScroll >= v.editY-editorHeight+1 {
+			v.scrollY = maxScroll
+		}
+	}
+
+	// горизонтальная прокрутка: нужно учитывать реальную ширину рун в текущей строке
+	if v.editY < 0 || v.editY >= len(lines) {
+		// защита
+		if v.scrollX < 0 {
+			v.scrollX = 0
+		}
+		return
+	}
+	li := v.buf.LineInfo(v.editY)
+	runes := li.Runes
+
+	// текущее отображаемое смещение в колонках (cells)
+	cursorDisp := li.Width(v.editX)
+	scrollDisp := li.Width(v.scrollX)
+
+	if cursorDisp < scrollDisp {
+		// смещаем scrollX в rune-индекс равный editX
+		v.scrollX = v.editX
+	} else if cursorDisp >= scrollDisp+editorWidth {
+		// нужно подобрать новое scrollX (rune-индекс) так, чтобы курсор поместится
+		// минимально уменьшаем scrollX
+		newScroll := v.editX
+		// двигаемся назад, пока отображаемая ширина от newScroll до editX больше нужной
+		for newScroll > 0 {
+			if li.Width(newScroll) <= cursorDisp-editorWidth+1 {
+				break
+			}
+			newScroll = editor.PrevGrapheme(runes, newScroll)
+		}
+		v.scrollX = newScroll
+	}
+
+	if v.scrollY < 0 {
+		v.scrollY = 0
+	}
+	if v.scrollX < 0 {
+		v.scrollX = 0
+	}
+
+}
+
+// Backspace: удалить выделение или графему перед курсором
+func (a *App) deleteBackward() {
+	if !a.checkWritable() || a.deleteSelection() {
+		return
+	}
+	a.view.editY, a.view.editX = a.view.buf.DeleteBackward(a.view.editY, a.view.editX)
+	a.ensureCursorVisible()
+}
+
+// Delete: удалить выделение или графему под курсором
+func (a *App) deleteForward() {
+	if !a.checkWritable() || a.deleteSelection() {
+		return
+	}
+	a.view.editY, a.view.editX = a.view.buf.DeleteForward(a.view.editY, a.view.editX)
+	a.ensureCursorVisible()
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+)
+
+// ---- Экспорт (Alt+x) ----
+//
+// HTML строится встроенным рендером: CSS встраивается в документ и
+// строится из стилей [markdown] активной темы, поэтому экспорт выглядит
+// так же, как предпросмотр в терминале. Остальные форматы (PDF, ODT…)
+// делаются внешними программами из [export.formats] в config.toml.
+
+// Сколько ждать внешнюю программу экспорта
+const exportTimeout = 2 * time.Minute
+
+var (
+	mdListRe = regexp.MustCompile(`^\s*([-+*]|\d+\.)\s+`)
+	mdHRRe   = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	// применяются к уже экранированному тексту: " там — &#34;
+	mdImageRe   = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+&#34;.*?&#34;)?\)`)
+	mdLinkTxtRe = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)(?:\s+&#34;.*?&#34;)?\)`)
+	mdEmRe      = regexp.MustCompile(`(^|[^\pL\pN])[*_]([^*_\s](?:[^*_]*[^*_\s])?)[*_]`)
+)
+
+// Цвет темы в CSS-виде
+func cssColor(s string) string {
+	if strings.HasPrefix(s, "#") {
+		return s
+	}
+	c := theme.ParseColor(s)
+	if c.Hex() < 0 {
+		return ""
+	}
+	return fmt.Sprintf("#%06x", c.Hex())
+}
+
+// CSS-свойства из StyleSpec
+func cssFromSpec(spec theme.StyleSpec) string {
+	var b strings.Builder
+	if c := cssColor(spec.FG); spec.FG != "" && c != "" {
+		fmt.Fprintf(&b, "color: %s; ", c)
+	}
+	if c := cssColor(spec.BG); spec.BG != "" && c != "" {
+		fmt.Fprintf(&b, "background-color: %s; ", c)
+	}
+	if spec.Bold {
+		b.WriteString("font-weight: bold; ")
+	}
+	if spec.Italic {
+		b.WriteString("font-style: italic; ")
+	}
+	if spec.Underline {
+		b.WriteString("text-decoration: underline; ")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Таблица стилей документа по теме
+func themeCSS(t *theme.Theme) string {
+	md := t.Markdown
+	rules := []struct{ sel, css string }{
+		{"body", fmt.Sprintf("color: %s; background-color: %s; font-family: ui-monospace, monospace; max-width: 50em; margin: 2em auto; line-height: 1.5;",
+			cssColor(t.UI.Foreground), cssColor(t.UI.Background))},
+		{"h1", cssFromSpec(md.H1)},
+		{"h2", cssFromSpec(md.H2)},
+		{"h3", cssFromSpec(md.H3)},
+		{"code", cssFromSpec(md.InlineCode)},
+		{"pre", cssFromSpec(md.CodeBlock) + " padding: 0.5em 1em; overflow-x: auto;"},
+		{"pre code", "color: inherit; background: none;"},
+		{"a", cssFromSpec(md.Link)},
+		{"li::marker", cssFromSpec(md.ListMarker)},
+		{"blockquote", cssFromSpec(md.Blockquote) + " margin-left: 0; padding-left: 1em; border-left: 2px solid currentColor;"},
+		{"hr", cssFromSpec(md.HR) + " border: none; border-top: 1px solid currentColor;"},
+		{"img", "max-width: 100%;"},
+	}
+	var b strings.Builder
+	for _, r := range rules {
+		if r.css != "" {
+			fmt.Fprintf(&b, "%s { %s }\n", r.sel, strings.TrimSpace(r.css))
+		}
+	}
+	return b.String()
+}
+
+// Строчная разметка: `код`, ![картинки](...), [ссылки](...), *выделение*
+func inlineHTML(s string) string {
+	parts := strings.Split(s, "`")
+	var b strings.Builder
+	for i, p := range parts {
+		// нечётные части — внутри `…`, незакрытая кавычка остаётся текстом
+		if i%2 == 1 && i < len(parts)-1 {
+			b.WriteString("<code>" + html.EscapeString(p) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			b.WriteString("`")
+		}
+		p = html.EscapeString(p)
+		p = mdImageRe.ReplaceAllString(p, `<img src="$2" alt="$1">`)
+		p = mdLinkTxtRe.ReplaceAllString(p, `<a href="$2">$1</a>`)
+		p = mdEmRe.ReplaceAllString(p, `$1<strong>$2</strong>`)
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// Markdown → HTML (тот же набор конструкций, что и в предпросмотре)
+func renderHTML(md string, theme *theme.Theme, title string) string {
+	var body strings.Builder
+	var para []string
+	list := "" // "ul", "ol" или "" — открытый список
+	inCode := false
+
+	flushPara := func() {
+		if len(para) > 0 {
+			body.WriteString("<p>" + inlineHTML(strings.Join(para, "\n")) + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			body.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trim := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trim, "```") {
+			flushPara()
+			closeList()
+			if inCode {
+				body.WriteString("</code></pre>\n")
+			} else {
+				lang := strings.TrimSpace(strings.TrimPrefix(trim, "```"))
+				if lang != "" {
+					body.WriteString(`<pre><code class="language-` + html.EscapeString(lang) + `">`)
+				} else {
+					body.WriteString("<pre><code>")
+				}
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			body.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trim == "":
+			flushPara()
+			closeList()
+		case strings.HasPrefix(trim, "# "), strings.HasPrefix(trim, "## "), strings.HasPrefix(trim, "### "):
+			flushPara()
+			closeList()
+			level := strings.Index(trim, " ")
+			fmt.Fprintf(&body, "<h%d>%s</h%d>\n", level, inlineHTML(trim[level+1:]), level)
+		case mdHRRe.MatchString(trim):
+			flushPara()
+			closeList()
+			body.WriteString("<hr>\n")
+		case strings.HasPrefix(trim, ">"):
+			flushPara()
+			closeList()
+			body.WriteString("<blockquote>" + inlineHTML(strings.TrimSpace(strings.TrimPrefix(trim, ">"))) + "</blockquote>\n")
+		case mdListRe.MatchString(line):
+			flushPara()
+			kind := "ul"
+			if m := mdListRe.FindStringSubmatch(line); strings.HasSuffix(m[1], ".") {
+				kind = "ol"
+			}
+			if list != kind {
+				closeList()
+				body.WriteString("<" + kind + ">\n")
+				list = kind
+			}
+			body.WriteString("<li>" + inlineHTML(mdListRe.ReplaceAllString(line, "")) + "</li>\n")
+		default:
+			closeList()
+			para = append(para, trim)
+		}
+	}
+	flushPara()
+	closeList()
+	if inCode {
+		body.WriteString("</code></pre>\n")
+	}
+
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n" +
+		"<title>" + html.EscapeString(title) + "</title>\n" +
+		"<style>\n" + themeCSS(theme) + "</style>\n</head>\n<body>\n" +
+		body.String() + "</body>\n</html>\n"
+}
+
+// Путь экспорта по умолчанию: рядом с файлом, с другим расширением
+func exportPath(path, ext string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ext
+}
+
+// Выбор формата экспорта
+func (a *App) exportMenu() {
+	if a.view.buf.path == "" {
+		a.notify(levelWarning, "%s", tr("export.no_file"))
+		return
+	}
+	items := []listItem{{label: "html", detail: tr("export.builtin"), value: "html"}}
+	var names []string
+	for name := range a.config.Export.Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := a.config.Export.Formats[name]
+		if len(f.Command) == 0 {
+			continue
+		}
+		items = append(items, listItem{label: name, detail: f.Command[0], value: name})
+	}
+	a.pick(tr("export.title"), items, func(item listItem) {
+		if item.value == "html" {
+			a.exportHTML()
+		} else {
+			a.exportExternal(item.value, a.config.Export.Formats[item.value])
+		}
+	})
+}
+
+// Экспорт текущего документа в HTML
+func (a *App) exportHTML() {
+	buf := a.view.buf
+	a.prompt(tr("export.html"), exportPath(buf.path, ".html"), func(out string) {
+		out = strings.TrimSpace(out)
+		if out == "" {
+			return
+		}
+		title := titleFromName(filepath.Base(buf.path))
+		doc := renderHTML(buf.Content, a.loadedTheme(), title)
+		if err := os.WriteFile(out, []byte(doc), 0644); err != nil {
+			a.notify(levelError, tr("export.failed"), err)
+			return
+		}
+		a.loadFiles()
+		a.notify(levelSuccess, tr("export.done"), out)
+	})
+}
+
+// Экспорт внешней программой в фоне; ход и результат — в уведомлениях
+func (a *App) exportExternal(name string, f ExportFormat) {
+	buf := a.view.buf
+	if buf.Modified {
+		// программа читает файл с диска
+		a.notify(levelWarning, "%s", tr("export.unsaved"))
+	}
+	a.prompt(trf("export.to", name), exportPath(buf.path, f.Ext), func(out string) {
+		out = strings.TrimSpace(out)
+		if out == "" {
+			return
+		}
+		if abs, err := filepath.Abs(out); err == nil {
+			out = abs
+		}
+		args := make([]string, len(f.Command))
+		for i, arg := range f.Command {
+			args[i] = strings.NewReplacer("{input}", buf.path, "{output}", out).Replace(arg)
+		}
+		a.notify(levelInfo, tr("export.running"), name, args[0])
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+			defer cancel()
+			cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+			// относительные картинки ищутся от папки документа
+			cmd.Dir = filepath.Dir(buf.path)
+			output, err := cmd.CombinedOutput()
+			a.post(func() {
+				if err != nil {
+					msg := err.Error()
+					if lines := strings.Split(strings.TrimSpace(string(output)), "\n"); lines[len(lines)-1] != "" {
+						msg = lines[len(lines)-1]
+					}
+					a.debugf("export %s: %v\n%s", name, err, output)
+					a.notify(levelError, tr("export.failed"), msg)
+					return
+				}
+				a.loadFiles()
+				a.notify(levelSuccess, tr("export.done"), out)
+			})
+		}()
+	})
+}
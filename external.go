@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ---- Внешний редактор (Alt+e) ----
+//
+// Экран tcell приостанавливается, текущий файл открывается во внешней
+// программе, после её завершения буфер перечитывается с диска.
+// Программа: editor.external из config.toml, иначе $VISUAL, $EDITOR, vi
+// (в Windows — notepad).
+// Файлы, изменённые другими программами, перечитываются так же, когда
+// окно терминала снова получает фокус.
+
+// Командная строка внешнего редактора
+func (a *App) externalEditor() []string {
+	for _, cmd := range []string{a.config.Editor.External, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if f := strings.Fields(cmd); len(f) > 0 {
+			return f
+		}
+	}
+	return []string{defaultExternalEditor}
+}
+
+// Открыть текущий файл во внешнем редакторе
+func (a *App) openInExternalEditor() {
+	if a.view.buf.path == "" {
+		a.notify(levelWarning, "%s", tr("external.no_file"))
+		return
+	}
+	if !a.checkWritable() {
+		return
+	}
+	if a.view.buf.Modified {
+		// иначе внешний редактор увидит старую версию файла
+		a.confirm(tr("external.save_first"), func() {
+			a.saveFile()
+			if !a.view.buf.Modified {
+				a.runExternalEditor()
+			}
+		})
+		return
+	}
+	a.runExternalEditor()
+}
+
+func (a *App) runExternalEditor() {
+	buf := a.view.buf
+	args := append(a.externalEditor(), buf.path)
+
+	if err := a.screen.Suspend(); err != nil {
+		a.notify(levelError, tr("external.failed"), args[0], err)
+		return
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	runErr := cmd.Run()
+	if err := a.screen.Resume(); err != nil {
+		a.notify(levelError, tr("external.failed"), args[0], err)
+	}
+	if runErr != nil {
+		a.notify(levelError, tr("external.failed"), args[0], runErr)
+	}
+	a.reloadBuffer(buf)
+}
+
+// Перечитать буфер с диска (во всех окнах, где он открыт). Несохранённые
+// правки при этом теряются — тогда спрашиваем (настройка confirm.reload).
+func (a *App) reloadBuffer(buf *buffer) {
+	content, err := os.ReadFile(buf.path)
+	if err != nil {
+		a.notify(levelError, tr("file.read_error"), err)
+		return
+	}
+	buf.diskTime = fileModTime(buf.path)
+	if string(content) == buf.Content {
+		return
+	}
+	if buf.Modified {
+		a.confirmIf(a.config.Confirm.Reload, trf("external.reload_confirm", filepath.Base(buf.path)), func() {
+			buf.Modified = false
+			a.reloadBuffer(buf)
+		})
+		return
+	}
+	buf.Content = string(content)
+	buf.Modified = false
+	for _, v := range a.views {
+		if v.buf == buf {
+			a.clampViewCursor(v)
+		}
+	}
+	a.notify(levelInfo, tr("external.reloaded"), filepath.Base(buf.path))
+}
+
+// Время изменения локального файла (нулевое, если его нет)
+func fileModTime(path string) time.Time {
+	if !lockable(path) {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Перечитать открытые файлы, изменённые снаружи
+func (a *App) checkDiskChanges() {
+	seen := map[*buffer]bool{}
+	for _, v := range a.views {
+		buf := v.buf
+		if seen[buf] || buf.diskTime.IsZero() {
+			continue
+		}
+		seen[buf] = true
+		if t := fileModTime(buf.path); t.After(buf.diskTime) {
+			a.reloadBuffer(buf)
+		}
+	}
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/filer"
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+	textbuf "github.com/StasKrav/eddy_tcell/pkg/buffer"
+)
+
+// ---- Файловая панель ----
+//
+// Левая панель: список папки (локальной, внутри архива или на сервере),
+// переходы по папкам, открытие, сохранение, удаление и переименование
+// файлов. Отметки — properties.go, чтение больших папок — dirload.go.
+
+// Структура для хранения информации о файле
+type fileItem struct {
+	name  string
+	path  string
+	isDir bool
+}
+
+// Загрузка файлов из текущей директории
+func (a *App) loadFiles() {
+	a.files = []fileItem{}
+	a.cancelDirLoad()
+
+	// Удалённая папка (см. remote.go) или папка внутри архива (см. archive.go)
+	if r, ok := parseRemote(a.currentDir); ok {
+		a.loadRemoteFiles(r)
+	} else if archive, inner, ok := filer.SplitArchivePath(a.currentDir); ok {
+		a.loadArchiveFiles(archive, inner)
+	} else {
+		// "C:" — текущая папка диска, а не его корень
+		if vol := filepath.VolumeName(a.currentDir); vol != "" && vol == a.currentDir {
+			a.currentDir += string(filepath.Separator)
+		}
+		a.loadLocalFiles()
+	}
+	a.sortFiles()
+	if a.dirLoad != nil {
+		return // остальное — когда папка дочитается (см. dirload.go)
+	}
+	a.pruneMarks()
+	a.refreshDiskUsage()
+}
+
+// Открытие выбранного файла или директории
+func (a *App) openSelected() {
+	if len(a.files) == 0 || a.cursor < 0 || a.cursor >= len(a.files) {
+		return
+	}
+
+	file := a.files[a.cursor]
+
+	// архив открывается как папка (вложенные архивы — нет)
+	if file.isDir || filer.IsArchiveName(file.name) && !inArchive(a.currentDir) {
+		// Переходим в директорию
+		a.currentDir = file.path
+		a.cursor = 0
+		a.fileScroll = 0
+		a.loadFiles()
+		a.activePanel = "left"
+	} else {
+		// Открываем файл
+		a.openFile(file.path)
+		a.activePanel = "right"
+	}
+
+}
+
+// Открытие файла для редактирования/предпросмотра
+func (a *App) openFile(path string) {
+	if isRemote(path) {
+		a.openRemoteFile(path) // в фоне, см. remote.go
+		return
+	}
+	content, err := readAnyFile(path)
+	if err != nil {
+		a.notify(levelError, tr("file.read_error"), err)
+		return
+	}
+	a.openContent(path, content)
+}
+
+// Показать в текущем окне уже прочитанный документ
+func (a *App) openContent(path string, content []byte) {
+	// Если файл уже открыт в другом окне — используем его буфер
+	var buf *buffer
+	for _, v := range a.views {
+		if v.buf.path == path {
+			buf = v.buf
+			break
+		}
+	}
+	if buf == nil {
+		buf = &buffer{Buffer: textbuf.Buffer{Content: string(content)}, path: path, openWords: countWords(string(content)), readOnly: a.openReadOnly(path)}
+		buf.Undo = a.loadUndo(buf)
+		buf.diskTime = fileModTime(path)
+	}
+	old := a.view.buf
+	a.view.buf = buf
+	a.view.editX = 0
+	a.view.editY = 0
+	a.view.scrollX = 0
+	a.view.scrollY = 0
+	a.view.previewY = 0
+	a.clampCursor()
+	// блокировка от правки другим экземпляром (см. lock.go)
+	a.releaseUnused(old)
+	a.lockBuffer(buf)
+
+	// Если markdown - открываем в последнем режиме (по умолчанию preview)
+	low := strings.ToLower(path)
+	if strings.HasSuffix(low, ".md") || strings.HasSuffix(low, ".markdown") {
+		a.view.mode = a.markdownMode
+	} else {
+		a.view.mode = "edit"
+	}
+	a.fireHooks(eventOpen)
+}
+
+// Удаление выбранного файла
+func (a *App) deleteFile() {
+	// Проверяем, что файл выбран и мы в левой панели
+	if a.activePanel != "left" || len(a.files) == 0 || a.cursor < 0 || a.cursor >= len(a.files) {
+		return
+	}
+
+	file := a.files[a.cursor]
+
+	// Не удаляем директории (для безопасности)
+	if file.isDir {
+		a.notify(levelWarning, tr("file.dir_not_deleted"), file.name)
+		return
+	}
+
+	a.confirmIf(a.config.Confirm.Delete, trf("file.delete_confirm", file.name), func() {
+		// удалённый файл удаляется на сервере, в фоне (см. remote.go)
+		if isRemote(file.path) {
+			go func() {
+				err := removeRemoteFile(file.path)
+				a.post(func() { a.fileDeleted(file, err) })
+			}()
+			return
+		}
+		a.fileDeleted(file, os.Remove(file.path))
+	})
+
+}
+
+// Итог удаления файла
+func (a *App) fileDeleted(file fileItem, err error) {
+	if err != nil {
+		a.notify(levelError, tr("file.delete_failed"), file.name, err)
+		return
+	}
+	a.notify(levelInfo, tr("file.deleted"), file.name)
+
+	// Обновляем список файлов
+	a.loadFiles()
+
+	// Корректируем позицию курсора, если нужно
+	if a.cursor >= len(a.files) && len(a.files) > 0 {
+		a.cursor = len(a.files) - 1
+	}
+}
+
+// Переименование выбранного файла или директории
+func (a *App) renameSelected() {
+	if len(a.files) == 0 || a.cursor < 0 || a.cursor >= len(a.files) {
+		return
+	}
+	file := a.files[a.cursor]
+
+	a.prompt(tr("file.rename"), file.name, func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" || name == file.name {
+			return
+		}
+		if strings.ContainsRune(name, os.PathSeparator) {
+			a.notify(levelWarning, tr("file.bad_name"), os.PathSeparator)
+			return
+		}
+		// удалённый файл переименовывается на сервере, в фоне (см. remote.go)
+		if isRemote(file.path) {
+			newPath := childPath(remoteParent(file.path), name)
+			go func() {
+				err := renameRemote(file.path, newPath)
+				a.post(func() { a.fileRenamed(file, name, newPath, err) })
+			}()
+			return
+		}
+		newPath := filepath.Join(filepath.Dir(file.path), name)
+		if _, err := os.Stat(newPath); err == nil {
+			a.notify(levelError, tr("file.exists"), name)
+			return
+		}
+		a.fileRenamed(file, name, newPath, os.Rename(file.path, newPath))
+	})
+}
+
+// Итог переименования: открытые буферы и ссылки следуют за файлом
+func (a *App) fileRenamed(file fileItem, name, newPath string, err error) {
+	if errors.Is(err, fs.ErrExist) {
+		a.notify(levelError, tr("file.exists"), name)
+		return
+	}
+	if err != nil {
+		a.notify(levelError, tr("file.rename_failed"), err)
+		return
+	}
+
+	// открытые буферы следуют за файлом, блокировка — тоже
+	for _, v := range a.views {
+		if v.buf.path == file.path {
+			a.unlock(v.buf)
+			v.buf.path = newPath
+			a.lockBuffer(v.buf)
+		}
+	}
+	a.notify(levelInfo, tr("file.renamed"), file.name, name)
+	if !file.isDir && !isRemote(newPath) {
+		a.updateLinksAfterRename(file.path, newPath)
+	}
+
+	a.loadFiles()
+	a.selectFile(newPath)
+}
+
+// Поставить курсор списка на файл с заданным путём
+func (a *App) selectFile(path string) {
+	for i, f := range a.files {
+		if f.path == path {
+			a.cursor = i
+			return
+		}
+	}
+	// папка ещё читается (большая или удалённая): курсор встанет позже
+	if a.dirLoad != nil {
+		a.dirLoad.selected = path
+	}
+}
+
+// Сохранение текущего файла
+func (a *App) saveFile() {
+	if a.view.buf.path == "" {
+		// Текст без имени (например, из stdin): спрашиваем имя
+		a.saveUnnamed()
+		return
+	}
+	if inArchive(a.view.buf.path) {
+		a.notify(levelWarning, "%s", tr("archive.read_only"))
+		return
+	}
+	if isWebURL(a.view.buf.path) {
+		a.notify(levelWarning, "%s", tr("web.read_only"))
+		return
+	}
+	if a.view.buf.readOnly {
+		a.notify(levelWarning, "%s", tr("readonly.blocked"))
+		return
+	}
+	if a.confirmStolenLock(a.view.buf, a.saveFile) {
+		return
+	}
+	if _, f, ok := a.formatterFor(a.view.buf.path); ok && f.OnSave {
+		a.formatBuffer(true) // см. format.go
+	}
+
+	if isRemote(a.view.buf.path) {
+		a.saveRemoteFile(a.view.buf) // в фоне, см. remote.go
+		return
+	}
+	if err := os.WriteFile(a.view.buf.path, []byte(a.view.buf.Content), 0644); err != nil {
+		a.notify(levelError, tr("save.failed"), err)
+		return
+	}
+	a.saved(a.view.buf, a.view.buf.Content)
+}
+
+// Буфер записан в файл; content — записанный текст
+func (a *App) saved(buf *buffer, content string) {
+	a.notify(levelSuccess, tr("save.ok"), filepath.Base(buf.path))
+	if buf == a.view.buf {
+		a.fireHooks(eventSave)
+	}
+
+	// Сбрасываем флаг изменений, если текст не правили, пока шла запись
+	buf.Modified = buf.Content != content
+	buf.diskTime = fileModTime(buf.path)
+	a.undoCheckpoint()
+	a.saveUndo(buf)
+
+	// Перерисовываем интерфейс, чтобы обновить индикатор изменений
+	a.redraw()
+}
+
+// Возврат в родительскую директорию
+func (a *App) goBack() {
+	parent := filepath.Dir(a.currentDir)
+	if isRemote(a.currentDir) {
+		parent = remoteParent(a.currentDir)
+	}
+	if parent != a.currentDir {
+		a.currentDir = parent
+		a.cursor = 0
+		a.fileScroll = 0
+		a.loadFiles()
+		return
+	}
+	// выше корня диска — выбор диска (Windows)
+	if roots := driveRoots(); len(roots) > 1 {
+		a.pickDrive(roots)
+	}
+}
+
+// Выбрать диск из списка корней
+func (a *App) pickDrive(roots []string) {
+	items := make([]listItem, 0, len(roots))
+	current := 0
+	for i, root := range roots {
+		items = append(items, listItem{label: root, value: root})
+		if strings.EqualFold(filepath.VolumeName(root), filepath.VolumeName(a.currentDir)) {
+			current = i
+		}
+	}
+	l := a.pick(tr("drives.title"), items, func(item listItem) {
+		a.enterDir(item.value)
+	})
+	l.selected = current
+}
+
+// Переключение показа скрытых файлов
+func (a *App) toggleHidden() {
+	a.showHidden = !a.showHidden
+	a.loadFiles()
+}
+
+// Цвет заголовка панели: акцентный у активной, иначе fg панели или цвет текста
+func panelTitleColor(t *theme.Theme, active bool, fg string) tcell.Color {
+	if active {
+		if accent := theme.ParseColor(t.UI.Accent); accent != tcell.ColorDefault {
+			return accent
+		}
+	}
+	if c := theme.ParseColor(fg); c != tcell.ColorDefault {
+		return c
+	}
+	return theme.ParseColor(t.UI.Foreground)
+}
+
+// Стиль строки списка файлов: цвета из [ui.left_panel] (выделение —
+// selected_fg/selected_bg, по умолчанию accent), поверх них [ui.file_list]
+func fileRowStyle(t *theme.Theme, isDir, selected bool) tcell.Style {
+	lp := t.UI.LeftPanel
+	style := styleFromSpec(theme.StyleSpec{}, t.UI)
+	if isDir && lp.DirFG != "" {
+		style = style.Foreground(theme.ParseColor(lp.DirFG))
+	}
+	if selected {
+		fg, bg := lp.SelectedFG, lp.SelectedBG
+		if isDir && lp.SelectedDirFG != "" {
+			fg = lp.SelectedDirFG
+		}
+		if fg == "" {
+			fg = t.UI.Background
+		}
+		if bg == "" {
+			bg = t.UI.Accent
+		}
+		style = style.Foreground(theme.ParseColor(fg)).Background(theme.ParseColor(bg)).Bold(lp.SelectedBold)
+		// нет ни одного цвета выделения (режим без цветов) — инверсия
+		if bg == "" {
+			style = style.Reverse(true)
+		}
+	}
+	fl := t.UI.FileList
+	switch {
+	case isDir && selected:
+		return overlayStyle(style, fl.DirItemSelected)
+	case isDir:
+		return overlayStyle(style, fl.DirItem)
+	case selected:
+		return overlayStyle(style, fl.FileItemSelected)
+	}
+	return overlayStyle(style, fl.FileItem)
+}
+
+// Отрисовка списка файлов
+func (a *App) drawFileList() {
+	styles := a.getStyles()
+	lw := a.listWidth()
+
+	// Рамка слева — цветом left panel fg или общим foreground
+	for y := 0; y < a.height-3; y++ {
+		a.screen.SetContent(lw, y, '│', nil, styles.Border)
+	}
+
+	// Заголовок: у активной панели — акцентным цветом
+	title := tr("ui.files") + a.dirLoadProgress() + a.duProgress()
+	col := 0
+	titleColor := styles.title(false, a.activePanel == "left")
+	for _, r := range title {
+		w := runewidth.RuneWidth(r)
+		if col >= lw-2 {
+			break
+		}
+		a.screen.SetContent(col+1, 0, r, nil, tcell.StyleDefault.Foreground(titleColor).Bold(true))
+		col += w
+	}
+	// позиция курсора справа: "12/240", если помещается
+	if len(a.files) > 0 {
+		pos := fmt.Sprintf("%d/%d", a.cursor+1, len(a.files))
+		if x := lw - 1 - len(pos); x > col+2 {
+			ui.PutString(a.screen, x, 0, lw-1, pos, tcell.StyleDefault.Foreground(titleColor))
+		}
+	}
+
+	// Путь текущей папки (см. breadcrumb.go)
+	a.drawBreadcrumb(-1)
+
+	// Список файлов
+	startY := 2
+	visibleHeight := a.height - 5
+
+	a.ensureFileCursorVisible()
+
+	for i := a.fileScroll; i < len(a.files); i++ {
+		file := a.files[i]
+		if i-a.fileScroll >= visibleHeight {
+			break
+		}
+
+		y := startY + i - a.fileScroll
+		if y >= a.height-3 {
+			break
+		}
+
+		style := styles.fileRow(file.isDir, i == a.cursor && a.activePanel == "left")
+		if a.marked[file.path] {
+			style = styles.Selection.apply(style).Bold(true)
+		}
+		name := file.name
+
+		// Обрезаем имя если слишком длинное (учитываем видимую ширину)
+		maxCols := lw - 2
+		if a.du != nil {
+			// размер справа (см. diskusage.go)
+			size := a.duLabel(file.path)
+			sizeX := lw - 2 - runewidth.StringWidth(size)
+			ui.PutString(a.screen, sizeX, y, lw-1, size, style)
+			maxCols = sizeX - 2
+		}
+		displayName := runewidth.Truncate(name, maxCols, "...")
+
+		col := 0
+		for _, r := range displayName {
+			w := runewidth.RuneWidth(r)
+			if col >= maxCols {
+				break
+			}
+			a.screen.SetContent(col+1, y, r, nil, style)
+			col += w
+		}
+	}
+
+	// Полоса прокрутки у правого края панели
+	a.drawScrollbar(lw-1, startY, visibleHeight, len(a.files), visibleHeight, a.fileScroll)
+
+}
+
+// Ограничить прокрутку списка файлов
+func (a *App) clampFileScroll() {
+	visibleHeight := a.height - 5
+	if a.fileScroll > len(a.files)-visibleHeight {
+		a.fileScroll = len(a.files) - visibleHeight
+	}
+	if a.fileScroll < 0 {
+		a.fileScroll = 0
+	}
+}
+
+// Прокрутить список файлов так, чтобы курсор был виден
+func (a *App) ensureFileCursorVisible() {
+	visibleHeight := a.height - 5
+	if a.cursor < a.fileScroll {
+		a.fileScroll = a.cursor
+	} else if a.cursor >= a.fileScroll+visibleHeight {
+		a.fileScroll = a.cursor - visibleHeight + 1
+	}
+	a.clampFileScroll()
+}
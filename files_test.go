@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameMovesLock(t *testing.T) {
+	a := newTestApp(t, nil)
+	dir := t.TempDir()
+	oldPath, newPath := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	if err := os.WriteFile(oldPath, []byte("text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a.currentDir = dir
+	a.openFile(oldPath)
+	if !a.view.buf.locked {
+		t.Fatal("opened file is not locked")
+	}
+
+	err := os.Rename(oldPath, newPath)
+	a.fileRenamed(fileItem{name: "a.md", path: oldPath}, "b.md", newPath, err)
+	if a.view.buf.path != newPath || !a.view.buf.locked {
+		t.Errorf("buffer path %s locked=%v, want %s locked", a.view.buf.path, a.view.buf.locked, newPath)
+	}
+	if _, err := os.Stat(lockPath(oldPath)); !os.IsNotExist(err) {
+		t.Errorf("lock for the old path left: %v", err)
+	}
+	if _, err := os.Stat(lockPath(newPath)); err != nil {
+		t.Errorf("no lock for the new path: %v", err)
+	}
+
+	// при закрытии снимается уже новая метка
+	a.releaseLocks()
+	if _, err := os.Stat(lockPath(newPath)); !os.IsNotExist(err) {
+		t.Errorf("lock for the new path left after release: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "strings"
+
+// ---- Фильтр через shell-команду (Alt+|) ----
+//
+// Выделенный текст (или весь буфер, если выделения нет) подаётся на
+// stdin команды, например sort или pandoc -t html, и заменяется её
+// выводом — как :!{motion} в vim. Команда запоминается в той же
+// истории, что и Alt+!. При ошибке текст не меняется. Выполняется так
+// же, как команды плагинов (plugins.go).
+
+// Alt+|: спросить команду и пропустить через неё текст
+func (a *App) filterPrompt() {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	input := "buffer"
+	if _, ok := a.view.selection(); ok {
+		input = "selection"
+	}
+	a.promptHistory(historyCommand, "|", "", func(cmd string) {
+		if strings.TrimSpace(cmd) == "" {
+			return
+		}
+		a.runPluginCommand(pluginCommand{Name: "|" + cmd, Title: cmd, Run: cmd, Input: input, Output: "replace"})
+	})
+}
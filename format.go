@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---- Форматирование внешними программами (Alt+F) ----
+//
+// Форматировщики задаются в [format.formatters] по расширению файла
+// (prettier для Markdown, gofmt для Go…). Текст буфера подаётся на
+// stdin, вывод заменяет буфер одним шагом отмены. С on_save = true
+// форматирование выполняется перед каждым сохранением. Курсор ставится
+// на тот же непробельный символ: форматировщики в основном двигают
+// пробелы и переносы, так что он остаётся у того же слова.
+
+// Форматировщик для файла (по расширению)
+func (a *App) formatterFor(path string) (string, Formatter, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return "", Formatter{}, false
+	}
+	names := make([]string, 0, len(a.config.Format.Formatters))
+	for name := range a.config.Format.Formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := a.config.Format.Formatters[name]
+		for _, e := range f.Ext {
+			if strings.ToLower(e) == ext && len(f.Command) > 0 {
+				return name, f, true
+			}
+		}
+	}
+	return "", Formatter{}, false
+}
+
+// Прогнать текст через форматировщик
+func runFormatter(f Formatter, path, text string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+	args := make([]string, len(f.Command))
+	for i, arg := range f.Command {
+		args[i] = strings.ReplaceAll(arg, "{file}", path)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if !isRemote(path) && !inArchive(path) && path != "" {
+		cmd.Dir = filepath.Dir(path)
+	}
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); errors.As(err, &exitErr) && msg != "" {
+			return "", fmt.Errorf("%s", firstLine(msg))
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// Alt+F: отформатировать текущий буфер
+func (a *App) formatCommand() {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	a.formatBuffer(false)
+}
+
+// Отформатировать буфер активного окна; quiet — без сообщений об
+// отсутствии форматировщика и неизменном тексте (для сохранения)
+func (a *App) formatBuffer(quiet bool) {
+	buf := a.view.buf
+	name, f, ok := a.formatterFor(buf.path)
+	if !ok {
+		if !quiet {
+			a.notify(levelInfo, tr("format.none"), filepath.Ext(buf.path))
+		}
+		return
+	}
+	out, err := runFormatter(f, buf.path, buf.Content)
+	if err != nil {
+		a.notify(levelError, tr("format.failed"), name, err)
+		return
+	}
+	if out == buf.Content {
+		if !quiet {
+			a.notify(levelInfo, "%s", tr("format.unchanged"))
+		}
+		return
+	}
+	// курсор — у того же по счёту непробельного символа
+	pos := byteOffset(buf.Content, a.view.editY, a.view.editX)
+	n := nonSpaceBefore(buf.Content, pos)
+	onChar := pos < len(buf.Content) && !unicode.IsSpace(rune(buf.Content[pos]))
+	row := a.view.editY - a.view.scrollY
+	a.view.clearSelection()
+	a.setLines(strings.Split(out, "\n"))
+	a.view.editY, a.view.editX = cursorAt(out, nonSpaceOffset(out, n, onChar))
+	a.view.scrollY = max(a.view.editY-row, 0)
+	a.clampCursor()
+	a.ensureCursorVisible()
+	a.debugf("format: %s (%s)", buf.path, name)
+}
+
+// Сколько непробельных символов в s до байта pos
+func nonSpaceBefore(s string, pos int) int {
+	n := 0
+	for _, r := range s[:min(pos, len(s))] {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// Позиция в s после n непробельных символов: перед следующим
+// непробельным (onChar) или сразу за n-м
+func nonSpaceOffset(s string, n int, onChar bool) int {
+	if n == 0 && !onChar {
+		return 0
+	}
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if n == 0 {
+			return i
+		}
+		n--
+		if n == 0 && !onChar {
+			return i + utf8.RuneLen(r)
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+)
+
+// ---- Галерея встроенных тем (Alt+T) ----
+//
+// Темы из папки themes/ вшиты в бинарник и доступны по имени так же, как
+// default/dark/light: в настройке theme config.toml и в inherit файлов
+// тем. Окно выбора сразу показывает тему под курсором, Enter записывает
+// выбор в config.toml, Esc возвращает прежнюю тему. Пункт «файл темы»
+// возвращает к theme.toml. Ниже встроенных показываются файлы тем и
+// схемы base16 из ~/.config/eddy/themes.
+
+//go:embed themes/*.toml
+var themeFiles embed.FS
+
+func init() {
+	entries, err := themeFiles.ReadDir("themes")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		data, err := themeFiles.ReadFile("themes/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		name := strings.TrimSuffix(e.Name(), ".toml")
+		t, err := theme.DecodeData("themes/"+e.Name(), string(data), "", &theme.Default, 0)
+		if err != nil {
+			panic(fmt.Sprintf("embedded theme %s: %v", name, err))
+		}
+		theme.Builtin[name] = t
+	}
+}
+
+// Имена встроенных тем для выбора (default — синоним dark, не показываем)
+func builtinThemeNames() []string {
+	var names []string
+	for name := range theme.Builtin {
+		if name != "default" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Файлы тем пользователя: ~/.config/eddy/themes/*.toml, *.yaml, *.yml
+func userThemeFiles() []string {
+	dir := filepath.Join(configDir(), "themes")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !e.IsDir() && (filepath.Ext(path) == ".toml" || theme.IsBase16File(path)) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// Окно выбора темы с предпросмотром
+func (a *App) themePicker() {
+	prev := a.loadedTheme()
+	items := []listItem{{label: tr("themes.file"), detail: filepath.Base(a.themePath())}}
+	current := 0
+	for _, name := range builtinThemeNames() {
+		detail := ""
+		if theme.Builtin[name].IsLight() {
+			detail = tr("themes.light")
+		}
+		if strings.EqualFold(name, a.config.Theme) {
+			current = len(items)
+		}
+		items = append(items, listItem{label: name, detail: detail, value: name})
+	}
+	// свои темы и схемы base16 из ~/.config/eddy/themes
+	for _, path := range userThemeFiles() {
+		detail := ""
+		if theme.IsBase16File(path) {
+			if name, _, err := theme.ParseBase16(path); err == nil {
+				detail = "base16: " + name
+			}
+		}
+		if path == a.config.Theme {
+			current = len(items)
+		}
+		items = append(items, listItem{label: filepath.Base(path), detail: detail, value: path})
+	}
+
+	preview := func(item listItem) {
+		ref := a.config.Theme
+		a.config.Theme = item.value
+		// тема с замечаниями тоже показывается
+		if t, _, _ := a.readTheme(); t != nil {
+			a.applyTheme(t)
+		}
+		a.config.Theme = ref
+	}
+	l := a.pick(tr("themes.title"), items, func(item listItem) {
+		ref := a.config.Theme
+		a.config.Theme = item.value
+		t, _, err := a.readTheme()
+		if t == nil {
+			a.config.Theme = ref
+			a.applyTheme(prev)
+			a.notify(levelError, tr("theme.error"), err)
+			return
+		}
+		a.applyTheme(t)
+		a.notifyThemeWarnings(err)
+		if err := setConfigValue("theme", item.value); err != nil {
+			a.notify(levelError, tr("config.save_failed"), err)
+			return
+		}
+		a.notify(levelSuccess, tr("themes.saved"), item.label)
+	})
+	l.selected = current
+	l.onChange = preview
+	l.onCancel = func() { a.applyTheme(prev) }
+}
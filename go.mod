@@ -1,4 +1,4 @@
-module eddy_tcell
+module github.com/StasKrav/eddy_tcell
 
 go 1.25.0
 
@@ -7,13 +7,17 @@ require (
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/gdamore/tcell/v2 v2.9.0
 	github.com/mattn/go-runewidth v0.0.16
+	github.com/pkg/sftp v1.13.11
+	github.com/rivo/uniseg v0.4.3
+	github.com/yuin/gopher-lua v1.1.2
+	golang.org/x/term v0.45.0
 )
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/rivo/uniseg v0.4.3 // indirect
-	golang.org/x/sys v0.35.0 // indirect
-	golang.org/x/term v0.34.0 // indirect
-	golang.org/x/text v0.28.0 // indirect
+	golang.org/x/crypto v0.54.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
 )
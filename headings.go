@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---- Переход к заголовку (Ctrl+T в редакторе) ----
+//
+// Быстрый список заголовков текущего документа: ввод фильтрует их
+// нечётким поиском, окно редактора сразу прокручивается к выбранному
+// заголовку, Enter оставляет курсор там, Esc возвращает прежнее место.
+
+// Заголовок документа
+type heading struct {
+	line  int // с 0
+	level int
+	text  string
+}
+
+// Заголовки # … ###### вне блоков кода
+func documentHeadings(lines []string) []heading {
+	var hs []heading
+	code := codeBlockLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			continue // отступ 4 — это код
+		}
+		level := 0
+		for level < len(trimmed) && trimmed[level] == '#' {
+			level++
+		}
+		if level == 0 || level > 6 || (level < len(trimmed) && trimmed[level] != ' ' && trimmed[level] != '\t') {
+			continue
+		}
+		text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+		hs = append(hs, heading{line: i, level: level, text: text})
+	}
+	return hs
+}
+
+// Список заголовков с переходом
+func (a *App) headingPicker() {
+	hs := documentHeadings(a.getLines())
+	if len(hs) == 0 {
+		a.notify(levelInfo, "%s", tr("headings.none"))
+		return
+	}
+	v := a.view
+	editX, editY, scrollY, previewY := v.editX, v.editY, v.scrollY, v.previewY
+	items := make([]listItem, len(hs))
+	current := 0
+	for i, h := range hs {
+		items[i] = listItem{
+			label:  strings.Repeat("  ", h.level-1) + h.text,
+			detail: fmt.Sprint(h.line + 1),
+			value:  fmt.Sprint(h.line + 1),
+		}
+		// раздел, в котором стоит курсор
+		if h.line <= editY {
+			current = i
+		}
+	}
+	jump := func(item listItem) {
+		var n int
+		fmt.Sscan(item.value, &n)
+		a.gotoLine(n)
+	}
+	l := a.pick(tr("headings.title"), items, jump)
+	l.selected = current
+	l.onChange = jump
+	l.onCancel = func() {
+		v.editX, v.editY, v.scrollY, v.previewY = editX, editY, scrollY, previewY
+	}
+}
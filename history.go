@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- История ввода ----
+//
+// Запросы поиска, шаблоны замены, команды (Alt+!) и пути (Alt+o)
+// запоминаются в ~/.config/eddy/history.toml и доступны в следующих
+// запусках. В окне ввода Up и Down листают историю этого окна,
+// недописанный текст возвращается после последней записи.
+
+// Виды истории (ключи в history.toml)
+const (
+	historySearch  = "search"
+	historyReplace = "replace"
+	historyCommand = "command"
+	historyOpen    = "path"
+)
+
+// Сколько записей каждого вида хранить
+const historyLimit = 100
+
+// Файл истории
+func historyPath() string {
+	return filepath.Join(configDir(), "history.toml")
+}
+
+// История вида kind (старые записи первыми); файл читается один раз
+func (a *App) historyItems(kind string) []string {
+	if a.histories == nil {
+		a.histories = map[string][]string{}
+		if _, err := toml.DecodeFile(historyPath(), &a.histories); err != nil && !os.IsNotExist(err) {
+			a.debugf("history: %v", err)
+		}
+	}
+	return a.histories[kind]
+}
+
+// Добавить запись: повтор переносится в конец, история записывается в файл
+func (a *App) addHistory(kind, text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	items := a.historyItems(kind)
+	kept := make([]string, 0, len(items)+1)
+	for _, s := range items {
+		if s != text {
+			kept = append(kept, s)
+		}
+	}
+	kept = append(kept, text)
+	if len(kept) > historyLimit {
+		kept = kept[len(kept)-historyLimit:]
+	}
+	a.histories[kind] = kept
+	if a.headless {
+		return // сценарий (см. script.go) не трогает историю пользователя
+	}
+
+	var b strings.Builder
+	err := toml.NewEncoder(&b).Encode(a.histories)
+	if err == nil {
+		if err = os.MkdirAll(configDir(), 0755); err == nil {
+			err = os.WriteFile(historyPath(), []byte(b.String()), 0644)
+		}
+	}
+	if err != nil {
+		a.debugf("history: %v", err)
+	}
+}
+
+// Листание истории в поле ввода
+type historyNav struct {
+	kind  string
+	pos   int    // индекс в истории; len(items) — свой текст
+	draft string // свой текст до начала листания
+}
+
+func newHistoryNav(a *App, kind string) historyNav {
+	return historyNav{kind: kind, pos: len(a.historyItems(kind))}
+}
+
+// Обработать Up/Down: true — клавиша использована
+func (h *historyNav) handleKey(a *App, ev *tcell.EventKey, in *inputLine) bool {
+	step := 0
+	switch ev.Key() {
+	case tcell.KeyUp:
+		step = -1
+	case tcell.KeyDown:
+		step = 1
+	}
+	if h.kind == "" || step == 0 {
+		return false
+	}
+	items := a.historyItems(h.kind)
+	if h.pos > len(items) {
+		h.pos = len(items)
+	}
+	next := h.pos + step
+	if next < 0 || next > len(items) {
+		return true
+	}
+	if h.pos == len(items) {
+		h.draft = in.String()
+	}
+	h.pos = next
+	if h.pos == len(items) {
+		in.set(h.draft)
+	} else {
+		in.set(items[h.pos])
+	}
+	return true
+}
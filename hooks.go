@@ -0,0 +1,111 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ---- Команды по событиям (hooks) ----
+//
+// После открытия и сохранения файла выполняются команды из [hooks] в
+// config.toml (пересобрать оглавление, запустить линтер, git add…) и
+// обработчики плагинов (plugins.go). Команда идёт через sh -c (в Windows
+// cmd /C) в фоне, {file} заменяется путём файла в кавычках этой оболочки,
+// текст буфера подаётся на stdin.
+// Вывод попадает в журнал сообщений (Alt+m), последняя строка видна
+// в уведомлении; ошибка и ненулевой код выхода — сообщение об ошибке.
+// Обработчик сценария Lua (lua.go) вызывается сразу, в главном цикле.
+
+// События
+const (
+	eventOpen        = "open"
+	eventSave        = "save"
+	eventThemeReload = "theme-reload"
+)
+
+// Больше этого строк вывода одной команды в журнал не пишем
+const hookMaxLines = 20
+
+// Обработчик события
+type hook struct {
+	source string // плагин или "config"
+	match  string // шаблон имени файла ("" — любой)
+	run    string
+	fn     func(file string) error // обработчик сценария (вместо run)
+}
+
+// Обработчики события из настроек и плагинов
+func (a *App) eventHooks(event string) []hook {
+	var cfg []Hook
+	switch event {
+	case eventOpen:
+		cfg = a.config.Hooks.OnOpen
+	case eventSave:
+		cfg = a.config.Hooks.OnSave
+	}
+	var hs []hook
+	for _, h := range cfg {
+		if strings.TrimSpace(h.Run) != "" {
+			hs = append(hs, hook{source: "config", match: h.Match, run: h.Run})
+		}
+	}
+	return append(hs, a.hooks[event]...)
+}
+
+// Подходит ли файл под шаблон обработчика
+func (h hook) matches(path string) bool {
+	if h.match == "" {
+		return true
+	}
+	ok, _ := filepath.Match(h.match, filepath.Base(path))
+	return ok
+}
+
+// Запустить обработчики события для файла активного окна (в фоне)
+func (a *App) fireHooks(event string) {
+	hs := a.eventHooks(event)
+	if len(hs) == 0 {
+		return
+	}
+	path := a.view.buf.path
+	env := a.pluginEnv(event)
+	dir := a.currentDir
+	content := a.view.buf.Content
+	for _, h := range hs {
+		if !h.matches(path) {
+			continue
+		}
+		if h.fn != nil {
+			if err := h.fn(path); err != nil {
+				a.notify(levelError, tr("hook.failed"), h.source+" "+event, err)
+			}
+			continue
+		}
+		cmd := strings.ReplaceAll(h.run, "{file}", shellQuote(path))
+		a.debugf("hook %s (%s): %s", event, h.source, cmd)
+		go func() {
+			res := runShellInput(dir, cmd, env, strings.NewReader(content))
+			a.post(func() { a.reportHook(h, event, res) })
+		}()
+	}
+}
+
+// Вывод обработчика — в журнал сообщений
+func (a *App) reportHook(h hook, event string, res shellResult) {
+	name := h.source + " " + event
+	lines := strings.Split(res.output, "\n")
+	if len(lines) > hookMaxLines {
+		lines = append(lines[:hookMaxLines], "…")
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			a.notify(levelInfo, tr("hook.output"), name, line)
+		}
+	}
+	switch {
+	case res.err != nil:
+		a.notify(levelError, tr("hook.failed"), name, res.err)
+	case res.exitCode != 0:
+		a.notify(levelError, tr("hook.exit"), name, res.exitCode)
+	}
+}
@@ -0,0 +1,854 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ---- Локализация интерфейса ----
+//
+// Все строки интерфейса берутся из каталога по ключу через tr/trf.
+// Язык задаётся в config.toml (language = "en" | "ru" | "auto"),
+// при "auto" — по LC_ALL / LC_MESSAGES / LANG. По умолчанию английский.
+
+// Текущий язык интерфейса
+var uiLang = "en"
+
+// Поддерживаемые языки
+var languages = []string{"en", "ru"}
+
+var catalog = map[string]map[string]string{
+	"en": {
+		"ui.files":          "Files",
+		"ui.editor":         "Editor",
+		"ui.preview":        "Preview",
+		"ui.too_small":      "Terminal too small",
+		"ui.too_small_need": "need ≥ %d×%d, now %d×%d",
+		"ui.messages":       "Messages",
+		"ui.help":           "Help",
+
+		"panel.left":   "left",
+		"panel.right":  "right",
+		"mode.edit":    "edit",
+		"mode.preview": "preview",
+
+		"status.panel":    "Panel: %s",
+		"status.mode":     "Mode: %s",
+		"status.file":     "File: %s",
+		"status.position": "Ln %d, Col %d",
+		"status.lines":    "%d lines",
+		"status.words":    "%d words",
+
+		"confirm.hint": "[y] yes   [n] no",
+
+		"help.hint":      "/ search   Ctrl+K what does a key do   Esc close",
+		"help.press_key": "Press a key…",
+		"help.unbound":   "%s — not bound",
+
+		"help.ctx.navigation": "NAVIGATION",
+		"help.ctx.panels":     "PANELS",
+		"help.ctx.editing":    "EDITING",
+		"help.ctx.preview":    "PREVIEW",
+		"help.ctx.windows":    "WINDOWS (Ctrl+W, then)",
+		"help.ctx.other":      "OTHER",
+		"help.ctx.plugins":    "PLUGINS",
+
+		"help.files.up":          "move up the file list",
+		"help.files.down":        "move down the file list",
+		"help.files.open":        "open file/directory",
+		"help.files.back":        "go to the parent directory",
+		"help.files.enter":       "open the selected item",
+		"help.files.delete":      "delete file (left panel)",
+		"help.files.rename":      "rename file (left panel)",
+		"help.files.mark":        "mark file for group actions",
+		"help.files.properties":  "properties and permissions of marked files",
+		"help.files.checksum":    "MD5 and SHA-256 of the file",
+		"help.files.du":          "show sizes of files and folders",
+		"help.files.du_sort":     "sort by size / by name",
+		"help.files.extract":     "extract selected archive entries next to the archive",
+		"help.files.extract_all": "extract the whole archive",
+		"help.files.new":         "new file (from a template)",
+		"help.files.open_path":   "go to a folder, file, sftp:// or http(s):// address",
+		"help.files.breadcrumb":  "jump up several folders along the path",
+		"help.files.download":    "save a document opened by URL to the current folder",
+		"help.files.hidden":      "show/hide hidden files",
+
+		"help.panels.left":   "focus the left panel",
+		"help.panels.right":  "focus the right panel",
+		"help.panels.toggle": "hide/show the file panel (narrow terminal: list/editor)",
+		"help.panels.grow":   "widen the file panel",
+		"help.panels.shrink": "narrow the file panel",
+
+		"help.edit.mode":          "toggle edit/preview mode",
+		"help.edit.save":          "save file",
+		"help.edit.goto":          "go to line",
+		"help.edit.headings":      "jump to a heading",
+		"help.edit.home_end":      "start/end of line",
+		"help.edit.select":        "select text (also Shift+Home/End)",
+		"help.edit.copy":          "copy the selection",
+		"help.edit.cut":           "cut the selection",
+		"help.edit.paste":         "paste the last copied text or the register",
+		"help.edit.clip_history":  "paste from clipboard history or registers",
+		"help.edit.register":      "use register a–z for the next copy/cut/paste (A–Z appends)",
+		"help.edit.search":        "search (Alt+C case, Alt+W word, Alt+R regex)",
+		"help.edit.search_next":   "next match",
+		"help.edit.search_prev":   "previous match",
+		"help.edit.backspace":     "delete character before cursor",
+		"help.edit.delete":        "delete character under cursor",
+		"help.edit.replace_files": "replace in all files of the folder",
+		"help.edit.undo":          "undo",
+		"help.edit.redo":          "redo",
+		"help.edit.bracket":       "jump to matching bracket or code fence",
+		"help.edit.repeat":        "repeat the last edit at the cursor",
+		"help.edit.readonly":      "toggle read-only for the current window",
+		"help.edit.diff":          "show unsaved changes as a diff",
+		"help.edit.conflict":      "resolve a merge conflict / next conflict",
+		"help.edit.external":      "open in external editor ($EDITOR)",
+		"help.edit.export":        "export the document (HTML, PDF…)",
+		"help.edit.lines":         "sort, deduplicate or reverse lines",
+		"help.edit.case":          "UPPER, lower, Title Case or slug-case",
+		"help.edit.timestamp":     "insert the current date or time",
+		"help.edit.filter":        "pipe the selection or text through a shell command",
+		"help.edit.format":        "format with an external formatter",
+		"help.edit.copy_plain":    "copy the rendered document as plain text",
+		"help.edit.spell":         "spelling suggestions for the word under cursor",
+
+		"help.win.vsplit":           "split vertically",
+		"help.win.hsplit":           "split horizontally",
+		"help.win.next":             "next window (also Tab)",
+		"help.win.close":            "close window",
+		"help.win.only":             "keep only the current window",
+		"help.win.grow":             "enlarge window",
+		"help.win.shrink":           "shrink window",
+		"help.win.equal":            "equalize windows",
+		"help.preview.top":          "top of document (also Home)",
+		"help.preview.bottom":       "end of document (also End)",
+		"help.preview.half_down":    "half a page down",
+		"help.preview.half_up":      "half a page up",
+		"help.preview.page_down":    "page down",
+		"help.preview.page_up":      "page up",
+		"help.preview.percent":      "go to N% of document",
+		"help.preview.clear_search": "clear search highlights",
+
+		"help.other.help":       "show help",
+		"help.other.which_key":  "keys of the current panel",
+		"help.other.palette":    "command palette",
+		"help.other.messages":   "message history",
+		"help.other.shell":      "run a shell command",
+		"help.other.stats":      "document statistics",
+		"help.other.tags":       "browse notes by tag",
+		"help.other.todo":       "TODO, FIXME and open tasks in notes",
+		"help.other.links":      "check relative links in the document",
+		"help.other.links_dir":  "check relative links in all Markdown files of the folder",
+		"help.other.assets":     "attachments of the document and orphaned assets",
+		"help.other.theme":      "reload theme",
+		"help.other.themes":     "choose a theme",
+		"help.other.theme_edit": "edit the theme",
+		"help.other.settings":   "settings",
+		"help.other.quit":       "quit",
+
+		"help.notes": "INDICATORS:\n" +
+			"* in the editor title means the file has unsaved changes\n" +
+			"\n" +
+			"PREVIEW:\n" +
+			".md/.markdown files open in Preview mode by default (Tab toggles the mode)",
+
+		"config.load_failed":     "Config not loaded: %v",
+		"config.save_failed":     "Config not saved: %v",
+		"config.panel_width_bad": "Bad panel.width: %v",
+		"bootstrap.created":      "Created %s with default settings",
+		"bootstrap.failed":       "Settings folder not created: %v",
+		"theme.load_failed":      "Theme not loaded: %v",
+		"theme.error":            "Theme error: %v",
+		"theme.warning":          "Theme: %s",
+		"theme.reloaded":         "Theme reloaded: %d keys changed",
+		"theme.reloaded_keys":    "Theme reloaded: %s",
+		"theme.unchanged":        "Theme reloaded, nothing changed",
+		"themes.title":           "Theme",
+		"themes.file":            "theme file",
+		"themes.light":           "light",
+		"themes.saved":           "Theme: %s",
+		"themeedit.title":        "Theme editor",
+		"themeedit.hint":         "Enter edit · p palette · Space toggle · Del reset · Ctrl+S save",
+		"themeedit.discard":      "Discard theme changes?",
+		"themeedit.bad_color":    "Not a color: %s",
+		"themeedit.in_theme":     "in theme",
+		"themeedit.saved":        "Theme saved: %s",
+		"themedump.title":        "Export the full theme to a file",
+		"themedump.saved":        "Theme exported: %s",
+		"script.bad_size":        "bad screen size %q (expected: size WIDTH HEIGHT)",
+		"script.bad_key":         "unknown key %q",
+		"script.bad_command":     "unknown command %q",
+		"script.bad_step":        "unknown step %q",
+		"script.missing":         "not on screen: %q",
+		"script.present":         "on screen: %q",
+		"settings.title":         "Settings",
+		"settings.hint":          "Enter edit · Space toggle · Del default · Ctrl+S save",
+		"settings.discard":       "Discard settings changes?",
+		"settings.bad_value":     "Not allowed: %s",
+		"settings.saved":         "Settings saved (%d changed)",
+		"settings.type_bool":     "on/off",
+		"settings.type_number":   "number",
+		"settings.type_text":     "text",
+		"settings.type_width":    "columns (30) or percent of the terminal (25%)",
+
+		"file.read_error":        "Cannot read file: %v",
+		"file.dir_not_deleted":   "Directories are not deleted: %s",
+		"file.delete_confirm":    "Delete %s?",
+		"file.delete_failed":     "Cannot delete %s: %v",
+		"file.deleted":           "Deleted %s",
+		"props.title":            "Properties",
+		"props.failed":           "Cannot read %s: %v",
+		"props.name":             "Name",
+		"props.size":             "Size",
+		"props.owner":            "Owner",
+		"props.modified":         "Modified",
+		"props.mode":             "Mode",
+		"props.files":            "Files",
+		"props.mixed":            "differs",
+		"props.user":             "owner",
+		"props.group":            "group",
+		"props.other":            "others",
+		"props.octal":            "Octal:",
+		"props.hint":             "Arrows, Space — toggle, Tab — octal, Enter — apply, Esc — close",
+		"props.bad_octal":        "Invalid mode: %s",
+		"props.chmod_failed":     "Cannot change permissions of %s: %v",
+		"props.chmod_done":       "Mode %s set for %d files",
+		"checksum.title":         "Checksum: %s",
+		"checksum.dir":           "%s is a folder",
+		"checksum.computing":     "Computing…",
+		"checksum.hint":          "m — copy MD5, s — copy SHA-256, c — compare, Esc — close",
+		"checksum.copied":        "Checksum copied",
+		"checksum.expected":      "Expected checksum",
+		"checksum.match_md5":     "✓ Matches MD5",
+		"checksum.match_sha256":  "✓ Matches SHA-256",
+		"checksum.mismatch":      "✗ Does not match",
+		"du.progress":            " %d/%d…",
+		"dir.loading":            " %s %d",
+		"archive.failed":         "Cannot read archive %s: %v",
+		"archive.read_only":      "Files inside an archive are read-only; extract them first (Alt+u)",
+		"archive.not_inside":     "Open an archive or select one in the file list",
+		"archive.extract_failed": "Extraction failed: %v",
+		"archive.extracted":      "Extracted %d files to %s",
+		"archive.overwrite":      "%d files already exist. Overwrite?",
+		"open.title":             "Go to path",
+		"open.bad":               "Cannot open %v",
+		"remote.failed":          "Remote error: %v",
+		"remote.loading":         "Connecting to %s…",
+		"remote.saving":          "Saving %s…",
+		"drives.title":           "Drive",
+		"web.loading":            "Loading %s…",
+		"web.failed":             "Download failed: %v",
+		"web.opened":             "Read-only; Alt+w saves it to the current folder",
+		"web.read_only":          "Documents opened by URL are read-only; Alt+w saves a copy",
+		"readonly.blocked":       "Read-only: Alt+r allows editing",
+		"readonly.on":            "Read-only",
+		"readonly.off":           "Editing allowed",
+		"lock.busy":              "%s is open in another eddy (%s, pid %d, %s). Edit anyway?",
+		"lock.taken":             "%s was taken over by another eddy (%s, pid %d). Save anyway?",
+		"diff.title":             "Changes: %s",
+		"diff.disk":              "%s (on disk)",
+		"diff.editor":            "%s (editor)",
+		"diff.none":              "No unsaved changes",
+		"diff.no_file":           "The text is not saved to a file yet",
+		"conflict.none":          "No merge conflicts",
+		"conflict.count":         "Merge conflicts: %d",
+		"conflict.title":         "Resolve conflict",
+		"conflict.ours":          "Keep ours",
+		"conflict.theirs":        "Keep theirs",
+		"conflict.both":          "Keep both",
+		"web.not_url":            "The document was not opened by URL",
+		"web.local_only":         "Open a local folder to save the document",
+		"web.overwrite":          "%s already exists. Overwrite?",
+		"file.rename":            "Rename",
+		"file.bad_name":          "Name must not contain %c",
+		"file.exists":            "Already exists: %s",
+		"file.rename_failed":     "Cannot rename: %v",
+		"file.renamed":           "%s → %s",
+
+		"goto.title":            "Go to line",
+		"goto.bad":              "Not a line number: %s",
+		"preview.percent_title": "Go to % of document",
+		"preview.percent_bad":   "Not a percentage: %s",
+
+		"save.no_name":    "No file name to save to",
+		"save.name_title": "Save as (file name in the current folder)",
+		"save.failed":     "Save failed: %v",
+		"save.ok":         "Saved: %s",
+
+		"main.log_error":   "Log error: %v",
+		"main.init_error":  "Initialization error: %v",
+		"main.stdin_error": "Cannot read standard input: %v",
+
+		"shell.running":   "Running: %s",
+		"shell.failed":    "%s: %v",
+		"shell.exit":      "%s: exit code %d",
+		"shell.exit_code": "(exit %d)",
+		"shell.hint":      "i insert output (replaces selection)   Esc close",
+		"shell.not_edit":  "Output can be inserted only in edit mode",
+
+		"external.no_file":        "No file to open",
+		"external.save_first":     "Save changes before opening in the external editor?",
+		"external.failed":         "%s: %v",
+		"external.reloaded":       "Reloaded %s",
+		"external.reload_confirm": "%s changed on disk. Reload and lose unsaved edits?",
+
+		"stats.title":      "Statistics",
+		"stats.words":      "Words",
+		"stats.chars":      "Characters",
+		"stats.chars_ns":   "Characters (no spaces)",
+		"stats.lines":      "Lines",
+		"stats.paragraphs": "Paragraphs",
+		"stats.reading":    "Reading time",
+		"stats.minutes":    "%d min",
+		"stats.session":    "Words this session",
+
+		"spell.load_failed": "Dictionaries not loaded: %v",
+		"spell.no_dict":     "Spell checking is off: no dictionaries found",
+		"spell.ok":          "No spelling errors under the cursor",
+		"spell.add":         "Add “%s” to the dictionary",
+		"spell.add_failed":  "Cannot update the dictionary: %v",
+
+		"links.title":   "Broken links: %d",
+		"links.none":    "No broken links",
+		"links.no_file": "No file to check",
+
+		"tags.title":     "Tags",
+		"tags.none":      "No tags found in this folder",
+		"todo.title":     "Tasks: %d",
+		"todo.none":      "No TODO, FIXME or open tasks in this folder",
+		"headings.title": "Headings",
+		"headings.none":  "No headings in this document",
+		"crumbs.hint":    "←/→ choose a folder · Enter go · Esc cancel",
+		"quit.unsaved":   "Unsaved changes in %d file(s). Quit anyway?",
+
+		"new.title":    "New file",
+		"new.template": "Template",
+		"new.empty":    "(empty)",
+		"new.created":  "Created %s",
+
+		"export.html":    "Export to HTML",
+		"export.no_file": "No file to export",
+		"export.failed":  "Export failed: %v",
+		"export.done":    "Exported to %s",
+		"export.title":   "Export",
+		"export.builtin": "built-in",
+		"export.to":      "Export to %s",
+		"export.unsaved": "Unsaved changes are not exported — save first",
+		"export.running": "Exporting to %s with %s…",
+
+		"plain.copied":          "Copied as plain text (%d words)",
+		"selection.copied":      "Copied %d characters",
+		"selection.cut":         "Cut %d characters",
+		"register.selected":     "Register \"%c",
+		"register.empty":        "Register \"%c is empty",
+		"clip.title":            "Clipboard history",
+		"clip.empty":            "Clipboard history is empty",
+		"clip.lines":            "%d lines",
+		"clip.chars":            "%d chars",
+		"undo.none":             "Nothing to undo",
+		"redo.none":             "Nothing to redo",
+		"repeat.none":           "Nothing to repeat",
+		"repeat.no_match":       "Text under the cursor is not \"%s\"",
+		"bracket.none":          "No matching bracket",
+		"search.title":          "Search",
+		"search.opt.case":       "Aa case",
+		"search.opt.word":       "whole word",
+		"search.opt.regex":      ".* regex",
+		"search.bad":            "bad regex",
+		"search.bad_regex":      "Bad regular expression: %v",
+		"search.not_found":      "Not found: %s",
+		"replace.find":          "Replace in files",
+		"replace.with":          "Replace \"%s\" with",
+		"replace.skipped":       "Skipped, has unsaved changes: %s",
+		"replace.title":         "Replace: %d of %d in %d files",
+		"replace.hint":          "Space — include/exclude, Enter — apply, Esc — cancel",
+		"replace.changed":       "%s changed since search",
+		"replace.failed":        "Replace failed: %v",
+		"replace.failed_backup": "Replace failed: %v (originals saved to %s)",
+		"replace.nothing":       "Nothing to replace",
+		"replace.backup":        "Originals saved to %s",
+		"replace.done":          "Replaced %d in %d files",
+
+		"assets.title":         "Attachments",
+		"assets.none":          "No attachments",
+		"assets.missing":       "missing",
+		"assets.orphan":        "orphan",
+		"assets.links_updated": "Links updated: %d",
+
+		"palette.title": "Commands",
+
+		"plugin.failed":         "Plugin %s: %v",
+		"plugin.command_failed": "%s failed: %s",
+		"plugin.changed":        "%s: the text changed while the command was running",
+		"plugin.none":           "No plugins in %s",
+		"plugin.title":          "Plugins",
+
+		"format.none":      "No formatter for %s files (see [format] in config.toml)",
+		"format.failed":    "Formatter %s: %v",
+		"format.unchanged": "Already formatted",
+
+		"hook.output": "%s: %s",
+		"hook.failed": "%s: %v",
+		"hook.exit":   "%s: exit code %d",
+
+		"lines.title":     "Lines (%d)",
+		"lines.sort_asc":  "Sort lines A–Z",
+		"lines.sort_desc": "Sort lines Z–A",
+		"lines.unique":    "Remove duplicate lines",
+		"lines.reverse":   "Reverse line order",
+
+		"case.title_menu": "Case",
+		"case.upper":      "UPPER CASE",
+		"case.lower":      "lower case",
+		"case.title":      "Title Case",
+		"case.slug":       "slug-case",
+
+		"timestamp.title": "Insert date",
+
+		"callout.note":      "Note",
+		"callout.tip":       "Tip",
+		"callout.important": "Important",
+		"callout.warning":   "Warning",
+		"callout.caution":   "Caution",
+		"preview.image":     "image",
+	},
+	"ru": {
+		"ui.files":          "Файлы",
+		"ui.editor":         "Редактор",
+		"ui.preview":        "Просмотр",
+		"ui.too_small":      "Терминал слишком мал",
+		"ui.too_small_need": "нужно не меньше %d×%d, сейчас %d×%d",
+		"ui.messages":       "Сообщения",
+		"ui.help":           "Справка",
+
+		"panel.left":   "левая",
+		"panel.right":  "правая",
+		"mode.edit":    "правка",
+		"mode.preview": "просмотр",
+
+		"status.panel":    "Панель: %s",
+		"status.mode":     "Режим: %s",
+		"status.file":     "Файл: %s",
+		"status.position": "Стр %d, Кол %d",
+		"status.lines":    "строк: %d",
+		"status.words":    "слов: %d",
+
+		"confirm.hint": "[y] да   [n] нет",
+
+		"help.hint":      "/ поиск   Ctrl+K что делает клавиша   Esc закрыть",
+		"help.press_key": "Нажмите клавишу…",
+		"help.unbound":   "%s — не назначена",
+
+		"help.ctx.navigation": "НАВИГАЦИЯ",
+		"help.ctx.panels":     "ПЕРЕКЛЮЧЕНИЕ ПАНЕЛЕЙ",
+		"help.ctx.editing":    "РЕДАКТИРОВАНИЕ",
+		"help.ctx.preview":    "ПРЕДПРОСМОТР",
+		"help.ctx.windows":    "ОКНА (Ctrl+W, затем)",
+		"help.ctx.other":      "ПРОЧЕЕ",
+		"help.ctx.plugins":    "ПЛАГИНЫ",
+
+		"help.files.up":          "перемещение по списку файлов вверх",
+		"help.files.down":        "перемещение по списку файлов вниз",
+		"help.files.open":        "открыть файл/папку",
+		"help.files.back":        "вернуться в родительскую папку",
+		"help.files.enter":       "открыть выбранный элемент",
+		"help.files.delete":      "удалить файл (в левой панели)",
+		"help.files.rename":      "переименовать файл (в левой панели)",
+		"help.files.mark":        "отметить файл для групповых действий",
+		"help.files.properties":  "свойства и права отмеченных файлов",
+		"help.files.checksum":    "MD5 и SHA-256 файла",
+		"help.files.du":          "показать размеры файлов и папок",
+		"help.files.du_sort":     "сортировать по размеру / по имени",
+		"help.files.extract":     "распаковать выбранное из архива рядом с ним",
+		"help.files.extract_all": "распаковать весь архив",
+		"help.files.new":         "новый файл (из шаблона)",
+		"help.files.open_path":   "перейти к папке, файлу, адресу sftp:// или http(s)://",
+		"help.files.breadcrumb":  "перейти вверх по пути на несколько папок",
+		"help.files.download":    "сохранить открытый по ссылке документ в текущую папку",
+		"help.files.hidden":      "показать/скрыть скрытые файлы",
+
+		"help.panels.left":   "переключить на левую панель",
+		"help.panels.right":  "переключить на правую панель",
+		"help.panels.toggle": "скрыть/показать панель файлов (в узком терминале: список/редактор)",
+		"help.panels.grow":   "расширить панель файлов",
+		"help.panels.shrink": "сузить панель файлов",
+
+		"help.edit.mode":          "переключить режим редактирования/предпросмотра",
+		"help.edit.save":          "сохранить файл",
+		"help.edit.goto":          "перейти к строке",
+		"help.edit.headings":      "перейти к заголовку",
+		"help.edit.home_end":      "начало/конец строки",
+		"help.edit.select":        "выделить текст (также Shift+Home/End)",
+		"help.edit.copy":          "копировать выделение",
+		"help.edit.cut":           "вырезать выделение",
+		"help.edit.paste":         "вставить скопированное или регистр",
+		"help.edit.clip_history":  "вставить из истории буфера обмена или регистра",
+		"help.edit.register":      "регистр a–z для следующего копирования, вырезания, вставки (A–Z дописывает)",
+		"help.edit.search":        "поиск (Alt+C регистр, Alt+W слово, Alt+R regex)",
+		"help.edit.search_next":   "следующее совпадение",
+		"help.edit.search_prev":   "предыдущее совпадение",
+		"help.edit.backspace":     "удалить символ перед курсором",
+		"help.edit.delete":        "удалить символ под курсором",
+		"help.edit.replace_files": "замена во всех файлах папки",
+		"help.edit.undo":          "отменить правку",
+		"help.edit.redo":          "вернуть отменённое",
+		"help.edit.bracket":       "к парной скобке или ограждению кода",
+		"help.edit.repeat":        "повторить последнюю правку у курсора",
+		"help.edit.readonly":      "только чтение для текущего окна",
+		"help.edit.diff":          "показать несохранённые изменения (diff)",
+		"help.edit.conflict":      "разрешить конфликт слияния / следующий конфликт",
+		"help.edit.external":      "открыть во внешнем редакторе ($EDITOR)",
+		"help.edit.export":        "экспорт документа (HTML, PDF…)",
+		"help.edit.lines":         "сортировка, повторы и обратный порядок строк",
+		"help.edit.case":          "ВЕРХНИЙ, нижний регистр, Заглавные или slug",
+		"help.edit.timestamp":     "вставить текущую дату или время",
+		"help.edit.filter":        "пропустить выделение или текст через команду",
+		"help.edit.format":        "отформатировать внешней программой",
+		"help.edit.copy_plain":    "скопировать документ как простой текст",
+		"help.edit.spell":         "варианты исправления слова под курсором",
+
+		"help.win.vsplit":           "разделить вертикально",
+		"help.win.hsplit":           "разделить горизонтально",
+		"help.win.next":             "переключить окно (также Tab)",
+		"help.win.close":            "закрыть окно",
+		"help.win.only":             "оставить только текущее окно",
+		"help.win.grow":             "увеличить окно",
+		"help.win.shrink":           "уменьшить окно",
+		"help.win.equal":            "выровнять окна",
+		"help.preview.top":          "в начало документа (также Home)",
+		"help.preview.bottom":       "в конец документа (также End)",
+		"help.preview.half_down":    "на полстраницы вниз",
+		"help.preview.half_up":      "на полстраницы вверх",
+		"help.preview.page_down":    "на страницу вниз",
+		"help.preview.page_up":      "на страницу вверх",
+		"help.preview.percent":      "перейти на N% документа",
+		"help.preview.clear_search": "убрать подсветку поиска",
+
+		"help.other.help":       "показать справку",
+		"help.other.which_key":  "клавиши текущей панели",
+		"help.other.palette":    "палитра команд",
+		"help.other.messages":   "история сообщений",
+		"help.other.shell":      "выполнить shell-команду",
+		"help.other.stats":      "статистика документа",
+		"help.other.tags":       "заметки по тегам",
+		"help.other.todo":       "TODO, FIXME и невыполненные задачи в заметках",
+		"help.other.links":      "проверить относительные ссылки в документе",
+		"help.other.links_dir":  "проверить ссылки во всех Markdown-файлах папки",
+		"help.other.assets":     "вложения документа и неиспользуемые файлы",
+		"help.other.theme":      "перезагрузить тему",
+		"help.other.themes":     "выбрать тему",
+		"help.other.theme_edit": "редактировать тему",
+		"help.other.settings":   "настройки",
+		"help.other.quit":       "выйти",
+
+		"help.notes": "ИНДИКАТОРЫ:\n" +
+			"* в заголовке редактора означает, что файл был изменен, но еще не сохранен\n" +
+			"\n" +
+			"ПРЕДПРОСМОТР:\n" +
+			"Файлы .md/.markdown открываются по умолчанию в режиме Preview (Tab переключает режим)",
+
+		"config.load_failed":     "Настройки не загружены: %v",
+		"config.save_failed":     "Настройки не сохранены: %v",
+		"config.panel_width_bad": "Неверный panel.width: %v",
+		"bootstrap.created":      "Создана папка настроек %s",
+		"bootstrap.failed":       "Папка настроек не создана: %v",
+		"theme.load_failed":      "Тема не загружена: %v",
+		"theme.error":            "Ошибка темы: %v",
+		"theme.warning":          "Тема: %s",
+		"theme.reloaded":         "Тема перезагружена: изменено ключей: %d",
+		"theme.reloaded_keys":    "Тема перезагружена: %s",
+		"theme.unchanged":        "Тема перезагружена, изменений нет",
+		"themes.title":           "Тема",
+		"themes.file":            "файл темы",
+		"themes.light":           "светлая",
+		"themes.saved":           "Тема: %s",
+		"themeedit.title":        "Редактор темы",
+		"themeedit.hint":         "Enter правка · p палитра · пробел флаг · Del сброс · Ctrl+S записать",
+		"themeedit.discard":      "Отменить правки темы?",
+		"themeedit.bad_color":    "Не цвет: %s",
+		"themeedit.in_theme":     "в теме",
+		"themeedit.saved":        "Тема записана: %s",
+		"themedump.title":        "Выгрузить тему целиком в файл",
+		"themedump.saved":        "Тема выгружена: %s",
+		"script.bad_size":        "неверный размер экрана %q (нужно: size ШИРИНА ВЫСОТА)",
+		"script.bad_key":         "неизвестная клавиша %q",
+		"script.bad_command":     "неизвестная команда %q",
+		"script.bad_step":        "неизвестный шаг %q",
+		"script.missing":         "нет на экране: %q",
+		"script.present":         "есть на экране: %q",
+		"settings.title":         "Настройки",
+		"settings.hint":          "Enter правка · пробел флаг · Del по умолчанию · Ctrl+S записать",
+		"settings.discard":       "Отменить правки настроек?",
+		"settings.bad_value":     "Недопустимое значение: %s",
+		"settings.saved":         "Настройки записаны (изменено: %d)",
+		"settings.type_bool":     "да/нет",
+		"settings.type_number":   "число",
+		"settings.type_text":     "текст",
+		"settings.type_width":    "колонки (30) или процент ширины терминала (25%)",
+
+		"file.read_error":        "Ошибка чтения файла: %v",
+		"file.dir_not_deleted":   "Директории не удаляются: %s",
+		"file.delete_confirm":    "Удалить %s?",
+		"file.delete_failed":     "Не удалось удалить %s: %v",
+		"file.deleted":           "Удалён %s",
+		"props.title":            "Свойства",
+		"props.failed":           "Не удалось прочитать %s: %v",
+		"props.name":             "Имя",
+		"props.size":             "Размер",
+		"props.owner":            "Владелец",
+		"props.modified":         "Изменён",
+		"props.mode":             "Права",
+		"props.files":            "Файлов",
+		"props.mixed":            "разные",
+		"props.user":             "владелец",
+		"props.group":            "группа",
+		"props.other":            "остальные",
+		"props.octal":            "Число:",
+		"props.hint":             "Стрелки, пробел — переключить, Tab — число, Enter — применить, Esc — закрыть",
+		"props.bad_octal":        "Неверные права: %s",
+		"props.chmod_failed":     "Не удалось изменить права %s: %v",
+		"props.chmod_done":       "Права %s установлены, файлов: %d",
+		"checksum.title":         "Контрольная сумма: %s",
+		"checksum.dir":           "%s — папка",
+		"checksum.computing":     "Вычисление…",
+		"checksum.hint":          "m — копировать MD5, s — копировать SHA-256, c — сравнить, Esc — закрыть",
+		"checksum.copied":        "Сумма скопирована",
+		"checksum.expected":      "Ожидаемая сумма",
+		"checksum.match_md5":     "✓ Совпадает с MD5",
+		"checksum.match_sha256":  "✓ Совпадает с SHA-256",
+		"checksum.mismatch":      "✗ Не совпадает",
+		"du.progress":            " %d/%d…",
+		"dir.loading":            " %s %d",
+		"archive.failed":         "Не удалось прочитать архив %s: %v",
+		"archive.read_only":      "Файлы в архиве только для чтения; сначала распакуйте (Alt+u)",
+		"archive.not_inside":     "Откройте архив или выберите его в списке",
+		"archive.extract_failed": "Распаковка не удалась: %v",
+		"archive.extracted":      "Распаковано файлов: %d в %s",
+		"archive.overwrite":      "Уже существует файлов: %d. Перезаписать?",
+		"open.title":             "Перейти по пути",
+		"open.bad":               "Не удалось открыть %v",
+		"remote.failed":          "Ошибка сервера: %v",
+		"remote.loading":         "Подключение к %s…",
+		"remote.saving":          "Сохранение %s…",
+		"drives.title":           "Диск",
+		"web.loading":            "Загрузка %s…",
+		"web.failed":             "Не удалось загрузить: %v",
+		"web.opened":             "Только чтение; Alt+w сохранит в текущую папку",
+		"web.read_only":          "Документ по ссылке только для чтения; Alt+w сохранит копию",
+		"readonly.blocked":       "Только для чтения: Alt+r разрешает правку",
+		"readonly.on":            "Только для чтения",
+		"readonly.off":           "Правка разрешена",
+		"lock.busy":              "%s открыт в другом eddy (%s, pid %d, %s). Всё равно править?",
+		"lock.taken":             "Блокировку %s забрал другой eddy (%s, pid %d). Всё равно сохранить?",
+		"diff.title":             "Изменения: %s",
+		"diff.disk":              "%s (на диске)",
+		"diff.editor":            "%s (в редакторе)",
+		"diff.none":              "Несохранённых изменений нет",
+		"diff.no_file":           "Текст ещё не сохранён в файл",
+		"conflict.none":          "Конфликтов слияния нет",
+		"conflict.count":         "Конфликтов слияния: %d",
+		"conflict.title":         "Разрешить конфликт",
+		"conflict.ours":          "Оставить нашу сторону",
+		"conflict.theirs":        "Оставить их сторону",
+		"conflict.both":          "Оставить обе",
+		"web.not_url":            "Документ открыт не по ссылке",
+		"web.local_only":         "Откройте локальную папку, чтобы сохранить документ",
+		"web.overwrite":          "%s уже существует. Перезаписать?",
+		"file.rename":            "Переименовать",
+		"file.bad_name":          "Имя не должно содержать %c",
+		"file.exists":            "Уже существует: %s",
+		"file.rename_failed":     "Не удалось переименовать: %v",
+		"file.renamed":           "%s → %s",
+
+		"goto.title":            "Перейти к строке",
+		"goto.bad":              "Не номер строки: %s",
+		"preview.percent_title": "Перейти на % документа",
+		"preview.percent_bad":   "Не процент: %s",
+
+		"save.no_name":    "Нет имени файла для сохранения",
+		"save.name_title": "Сохранить как (имя файла в текущей папке)",
+		"save.failed":     "Ошибка сохранения: %v",
+		"save.ok":         "Сохранено: %s",
+
+		"main.log_error":   "Ошибка лога: %v",
+		"main.init_error":  "Ошибка инициализации: %v",
+		"main.stdin_error": "Не удалось прочитать стандартный ввод: %v",
+
+		"shell.running":   "Выполняется: %s",
+		"shell.failed":    "%s: %v",
+		"shell.exit":      "%s: код выхода %d",
+		"shell.exit_code": "(код %d)",
+		"shell.hint":      "i вставить вывод (вместо выделения)   Esc закрыть",
+		"shell.not_edit":  "Вставка вывода возможна только в режиме правки",
+
+		"external.no_file":        "Нет файла для открытия",
+		"external.save_first":     "Сохранить изменения перед открытием во внешнем редакторе?",
+		"external.failed":         "%s: %v",
+		"external.reloaded":       "Перечитан %s",
+		"external.reload_confirm": "%s изменён на диске. Перечитать, потеряв несохранённые правки?",
+
+		"stats.title":      "Статистика",
+		"stats.words":      "Слов",
+		"stats.chars":      "Символов",
+		"stats.chars_ns":   "Символов без пробелов",
+		"stats.lines":      "Строк",
+		"stats.paragraphs": "Абзацев",
+		"stats.reading":    "Время чтения",
+		"stats.minutes":    "%d мин",
+		"stats.session":    "Слов за сессию",
+
+		"spell.load_failed": "Словари не загружены: %v",
+		"spell.no_dict":     "Проверка орфографии выключена: словари не найдены",
+		"spell.ok":          "Под курсором нет ошибок",
+		"spell.add":         "Добавить «%s» в словарь",
+		"spell.add_failed":  "Не удалось обновить словарь: %v",
+
+		"links.title":   "Битые ссылки: %d",
+		"links.none":    "Битых ссылок нет",
+		"links.no_file": "Нет файла для проверки",
+
+		"tags.title":     "Теги",
+		"tags.none":      "В этой папке нет тегов",
+		"todo.title":     "Задачи: %d",
+		"todo.none":      "В этой папке нет TODO, FIXME и невыполненных задач",
+		"headings.title": "Заголовки",
+		"headings.none":  "В документе нет заголовков",
+		"crumbs.hint":    "←/→ выбрать папку · Enter перейти · Esc отмена",
+		"quit.unsaved":   "Несохранённые изменения в файлах: %d. Всё равно выйти?",
+
+		"new.title":    "Новый файл",
+		"new.template": "Шаблон",
+		"new.empty":    "(пустой)",
+		"new.created":  "Создан %s",
+
+		"export.html":    "Экспорт в HTML",
+		"export.no_file": "Нет файла для экспорта",
+		"export.failed":  "Ошибка экспорта: %v",
+		"export.done":    "Экспортировано в %s",
+		"export.title":   "Экспорт",
+		"export.builtin": "встроенный",
+		"export.to":      "Экспорт в %s",
+		"export.unsaved": "Несохранённые изменения не попадут в экспорт — сохраните файл",
+		"export.running": "Экспорт в %s через %s…",
+
+		"plain.copied":          "Скопировано как текст (слов: %d)",
+		"selection.copied":      "Скопировано символов: %d",
+		"selection.cut":         "Вырезано символов: %d",
+		"register.selected":     "Регистр \"%c",
+		"register.empty":        "Регистр \"%c пуст",
+		"clip.title":            "История буфера обмена",
+		"clip.empty":            "История буфера обмена пуста",
+		"clip.lines":            "строк: %d",
+		"clip.chars":            "символов: %d",
+		"undo.none":             "Нечего отменять",
+		"redo.none":             "Нечего возвращать",
+		"repeat.none":           "Нечего повторять",
+		"repeat.no_match":       "Под курсором не «%s»",
+		"bracket.none":          "Нет парной скобки",
+		"search.title":          "Поиск",
+		"search.opt.case":       "Aa регистр",
+		"search.opt.word":       "слово целиком",
+		"search.opt.regex":      ".* regex",
+		"search.bad":            "ошибка в regex",
+		"search.bad_regex":      "Ошибка в регулярном выражении: %v",
+		"search.not_found":      "Не найдено: %s",
+		"replace.find":          "Замена в файлах",
+		"replace.with":          "Заменить «%s» на",
+		"replace.skipped":       "Пропущен, есть несохранённые правки: %s",
+		"replace.title":         "Замена: %d из %d в файлах: %d",
+		"replace.hint":          "Пробел — включить/исключить, Enter — применить, Esc — отмена",
+		"replace.changed":       "%s изменился после поиска",
+		"replace.failed":        "Замена не выполнена: %v",
+		"replace.failed_backup": "Замена не выполнена: %v (оригиналы сохранены в %s)",
+		"replace.nothing":       "Нечего заменять",
+		"replace.backup":        "Оригиналы сохранены в %s",
+		"replace.done":          "Заменено: %d, файлов: %d",
+
+		"assets.title":         "Вложения",
+		"assets.none":          "Вложений нет",
+		"assets.missing":       "нет файла",
+		"assets.orphan":        "не используется",
+		"assets.links_updated": "Обновлено ссылок: %d",
+
+		"palette.title": "Команды",
+
+		"plugin.failed":         "Плагин %s: %v",
+		"plugin.command_failed": "%s: ошибка: %s",
+		"plugin.changed":        "%s: текст изменился, пока работала команда",
+		"plugin.none":           "Нет плагинов в %s",
+		"plugin.title":          "Плагины",
+
+		"format.none":      "Нет форматировщика для файлов %s (см. [format] в config.toml)",
+		"format.failed":    "Форматировщик %s: %v",
+		"format.unchanged": "Уже отформатировано",
+
+		"hook.output": "%s: %s",
+		"hook.failed": "%s: %v",
+		"hook.exit":   "%s: код выхода %d",
+
+		"lines.title":     "Строки (%d)",
+		"lines.sort_asc":  "Сортировать строки А–Я",
+		"lines.sort_desc": "Сортировать строки Я–А",
+		"lines.unique":    "Удалить повторяющиеся строки",
+		"lines.reverse":   "Обратный порядок строк",
+
+		"case.title_menu": "Регистр",
+		"case.upper":      "ВЕРХНИЙ РЕГИСТР",
+		"case.lower":      "нижний регистр",
+		"case.title":      "Каждое Слово С Заглавной",
+		"case.slug":       "slug-вид",
+
+		"timestamp.title": "Вставить дату",
+
+		"callout.note":      "Заметка",
+		"callout.tip":       "Совет",
+		"callout.important": "Важно",
+		"callout.warning":   "Внимание",
+		"callout.caution":   "Осторожно",
+		"preview.image":     "изображение",
+	},
+}
+
+// Перевод по ключу: текущий язык, затем английский, затем сам ключ
+func tr(key string) string {
+	if s, ok := catalog[uiLang][key]; ok {
+		return s
+	}
+	if s, ok := catalog["en"][key]; ok {
+		return s
+	}
+	return key
+}
+
+// Перевод с подстановкой аргументов
+func trf(key string, args ...interface{}) string {
+	return fmt.Sprintf(tr(key), args...)
+}
+
+// Определить язык: явная настройка или переменные окружения локали
+func detectLanguage(setting string) string {
+	setting = strings.ToLower(strings.TrimSpace(setting))
+	if setting != "" && setting != "auto" {
+		for _, l := range languages {
+			if strings.HasPrefix(setting, l) {
+				return l
+			}
+		}
+		return "en"
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := strings.ToLower(os.Getenv(env))
+		if v == "" {
+			continue
+		}
+		for _, l := range languages {
+			if strings.HasPrefix(v, l) {
+				return l
+			}
+		}
+		return "en"
+	}
+	return "en"
+}
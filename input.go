@@ -0,0 +1,274 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Клавиатура и мышь ----
+//
+// События tcell разбираются здесь: оверлеи получают клавиши первыми,
+// затем привязки реестра команд (commands.go), а то, что команды не
+// забрали, — стрелки и ввод текста в активной панели. Мышь выбирает
+// панель и окно, ставит курсор и прокручивает.
+
+// Обработка событий клавиатуры
+func (a *App) handleKey(ev *tcell.EventKey) {
+	// Модальное окно перехватывает клавиатуру
+	if a.handleOverlayKey(ev) {
+		return
+	}
+	if a.register == registerPending {
+		a.selectRegister(ev)
+		return
+	}
+	// Команды из реестра (см. commands.go)
+	if a.dispatchKey(ev) {
+		return
+	}
+
+	// Shift со стрелками выделяет текст, без Shift — снимает выделение
+	if a.activePanel == "right" && a.view.mode == "edit" && isSelectionMoveKey(ev.Key()) {
+		if ev.Modifiers()&tcell.ModShift != 0 {
+			a.view.startSelection()
+		} else {
+			a.view.clearSelection()
+		}
+	}
+
+	// Навигация стрелками/Enter
+	switch ev.Key() {
+	case tcell.KeyHome:
+		if a.activePanel == "right" && a.view.mode == "edit" {
+			a.view.editX = 0
+			a.ensureCursorVisible()
+		}
+	case tcell.KeyEnd:
+		if a.activePanel == "right" && a.view.mode == "edit" {
+			a.view.editX = len([]rune(a.getLines()[a.view.editY]))
+			a.ensureCursorVisible()
+		}
+	case tcell.KeyUp:
+		if a.activePanel == "left" && a.cursor > 0 {
+			a.cursor--
+		} else if a.activePanel == "right" {
+			lines := a.getLines()
+			if a.view.mode == "edit" && a.view.editY > 0 {
+				a.view.editY--
+				a.view.editX = editor.SnapGrapheme([]rune(lines[a.view.editY]), a.view.editX)
+				a.ensureCursorVisible()
+			} else if a.view.mode == "preview" {
+				a.scrollPreview(a.view, -1)
+			}
+		}
+	case tcell.KeyDown:
+		if a.activePanel == "left" && a.cursor < len(a.files)-1 {
+			a.cursor++
+		} else if a.activePanel == "right" {
+			lines := a.getLines()
+			if a.view.mode == "edit" && a.view.editY < len(lines)-1 {
+				a.view.editY++
+				a.view.editX = editor.SnapGrapheme([]rune(lines[a.view.editY]), a.view.editX)
+				a.ensureCursorVisible()
+			} else if a.view.mode == "preview" {
+				a.scrollPreview(a.view, 1)
+			}
+		}
+	case tcell.KeyLeft:
+		if a.activePanel == "left" {
+			a.goBack()
+		} else if a.activePanel == "right" {
+			if a.view.mode == "edit" {
+				if a.view.editX > 0 {
+					a.view.editX = editor.PrevGrapheme([]rune(a.getLines()[a.view.editY]), a.view.editX)
+				} else if a.view.editY > 0 {
+					a.view.editY--
+					a.view.editX = len([]rune(a.getLines()[a.view.editY]))
+				}
+				a.ensureCursorVisible()
+			} else if a.view.mode == "preview" && a.view.scrollX > 0 {
+				a.view.scrollX--
+			}
+		}
+	case tcell.KeyRight:
+		if a.activePanel == "left" {
+			a.openSelected()
+		} else if a.activePanel == "right" {
+			lines := a.getLines()
+			if a.view.mode == "edit" {
+				runes := []rune(lines[a.view.editY])
+				if a.view.editX < len(runes) {
+					a.view.editX = editor.NextGrapheme(runes, a.view.editX)
+				} else if a.view.editY < len(lines)-1 {
+					a.view.editY++
+					a.view.editX = 0
+				}
+				a.ensureCursorVisible()
+			} else if a.view.mode == "preview" && !a.config.Preview.Wrap {
+				a.view.scrollX++
+			}
+		}
+	case tcell.KeyEnter:
+		if a.activePanel == "left" {
+			a.openSelected()
+		} else if a.activePanel == "right" && a.view.mode == "edit" && a.checkWritable() {
+			a.deleteSelection()
+			lines := a.getLines()
+			line := lines[a.view.editY]
+			runes := []rune(line)
+			left := string(runes[:a.view.editX])
+			right := string(runes[a.view.editX:])
+			lines[a.view.editY] = left
+			newLines := append([]string{}, lines[:a.view.editY+1]...)
+			newLines = append(newLines, right)
+			if a.view.editY+1 < len(lines) {
+				newLines = append(newLines, lines[a.view.editY+1:]...)
+			}
+			a.setLines(newLines)
+			a.view.editY++
+			a.view.editX = 0
+			a.ensureCursorVisible()
+		}
+	}
+
+	// Ввод символов
+	if ev.Rune() != 0 {
+		r := ev.Rune()
+		if r == 'q' && a.pager && a.activePanel == "right" && a.view.mode == "preview" {
+			a.quit()
+			return
+		}
+		if a.activePanel == "right" && a.view.mode == "edit" && a.checkWritable() {
+			a.deleteSelection()
+			lines := a.getLines()
+			if len(lines) == 0 {
+				lines = []string{""}
+			}
+			line := lines[a.view.editY]
+			runes := []rune(line)
+			if a.view.editX < 0 {
+				a.view.editX = 0
+			}
+			if a.view.editX > len(runes) {
+				a.view.editX = len(runes)
+			}
+
+			// Вставляем символ
+			// копия головы: append в runes[:editX] затёр бы символ под курсором
+			head := append([]rune{}, runes[:a.view.editX]...)
+			lines[a.view.editY] = string(append(append(head, r), runes[a.view.editX:]...))
+			a.view.editX++
+
+			// Для Markdown не выполняем специальные авто-отступы как для Go
+			a.setLines(lines)
+			a.ensureCursorVisible()
+		}
+	}
+
+}
+
+// Обработка событий мыши: колесо прокручивает панель под указателем,
+// независимо от того, где находится фокус клавиатуры
+func (a *App) handleMouse(ev *tcell.EventMouse) {
+	delta := 0
+	switch {
+	case ev.Buttons()&tcell.WheelUp != 0:
+		delta = -wheelScrollLines
+	case ev.Buttons()&tcell.WheelDown != 0:
+		delta = wheelScrollLines
+	case ev.Buttons()&tcell.Button1 != 0 && len(a.overlays) == 0:
+		a.redraw()
+		a.clickBreadcrumb(ev.Position())
+		return
+	default:
+		// движение мыши и прочее — без перерисовки
+		return
+	}
+	a.redraw()
+
+	x, y := ev.Position()
+	if lw := a.listWidth(); lw > 0 && x < lw {
+		a.fileScroll += delta
+		a.clampFileScroll()
+		// курсор — внутрь видимой части, иначе прокрутка откатится
+		if len(a.files) > 0 {
+			visibleHeight := a.height - 5
+			a.cursor = min(max(a.cursor, a.fileScroll), a.fileScroll+visibleHeight-1, len(a.files)-1)
+		}
+		return
+	}
+
+	a.layout()
+	for _, v := range a.views {
+		if x >= v.x && x < v.x+v.w && y >= v.y && y < v.y+v.h {
+			a.scrollView(v, delta)
+			return
+		}
+	}
+}
+
+// Обработать одно событие терминала
+func (a *App) handleEvent(ev tcell.Event) {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		a.redraw()
+		if a.pasting {
+			a.pasteKey(ev)
+			return
+		}
+		// на маленьком экране — только выход (см. minsize.go)
+		if a.tooSmall() && keyName(ev) != "Ctrl+Q" {
+			return
+		}
+		a.handleKey(ev)
+	case *tcell.EventPaste:
+		a.redraw()
+		a.handlePaste(ev)
+	case *tcell.EventMouse:
+		if !a.tooSmall() {
+			a.handleMouse(ev)
+		}
+	case *tcell.EventFocus:
+		// вернулись в терминал: файлы могли измениться снаружи
+		if ev.Focused {
+			a.redraw()
+			a.checkDiskChanges()
+		}
+	case *tcell.EventResize:
+		a.redraw()
+		a.screen.Sync()
+		a.resized()
+	case *tcell.EventInterrupt:
+		// функции из фоновых горутин выполняются в главном цикле
+		a.redraw()
+		if fn, ok := ev.Data().(func()); ok {
+			fn()
+		}
+	case *tickEvent:
+		a.redraw()
+	}
+	// правки этого события — в историю отмены
+	a.undoCheckpoint()
+}
+
+// Новый размер терминала: пересчитать окна, перенос предпросмотра и
+// прокрутку под новую высоту
+func (a *App) resized() {
+	if a.tooSmall() {
+		return // раскладка подождёт нормального размера
+	}
+	a.layout()
+	a.clampFileScroll()
+	if !a.editorShown() {
+		return // окна скрыты списком файлов (узкий терминал)
+	}
+	for _, v := range a.views {
+		if v.mode == "preview" {
+			a.clampPreview(v) // заодно перенос по новой ширине
+		} else {
+			a.clampViewCursor(v)
+			a.ensureViewCursorVisible(v)
+		}
+	}
+}
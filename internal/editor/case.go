@@ -0,0 +1,47 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ---- Регистр и slug ----
+//
+// Преобразования для меню Alt+C. Апостроф внутри слова слово не делит.
+
+// Каждое слово с заглавной буквы, остальные буквы строчные
+func TitleCase(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’' {
+			if start {
+				runes[i] = unicode.ToTitle(r)
+			} else {
+				runes[i] = unicode.ToLower(r)
+			}
+			start = false
+		} else {
+			start = true
+		}
+	}
+	return string(runes)
+}
+
+// Строчные буквы и цифры, всё остальное — один дефис между словами
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else if r != '\'' && r != '’' {
+			dash = true
+		}
+	}
+	return b.String()
+}
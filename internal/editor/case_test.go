@@ -0,0 +1,37 @@
+package editor
+
+import "testing"
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"hello world", "Hello World"},
+		{"HELLO wORLD", "Hello World"},
+		{"don't stop", "Don't Stop"},
+		{"it’s fine", "It’s Fine"},
+		{"привет, мир", "Привет, Мир"},
+		{"x-ray 3d", "X-Ray 3d"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := TitleCase(tt.in); got != tt.want {
+			t.Errorf("TitleCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Hello World", "hello-world"},
+		{"  Leading and trailing  ", "leading-and-trailing"},
+		{"Don't Stop!", "dont-stop"},
+		{"C++ & Go: 2024", "c-go-2024"},
+		{"Привет, мир", "привет-мир"},
+		{"already-slug", "already-slug"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---- Построчное сравнение ----
+//
+// Diff двух текстов по строкам (Myers) и его запись в виде единого diff,
+// как у git diff.
+
+// Строк контекста вокруг изменения
+const diffContext = 3
+
+// Дальше этого числа правок строки не сопоставляем: весь текст — замена
+const diffMaxEdits = 2000
+
+// Строка сравнения: ' ' — общая, '-' — только в старом тексте, '+' — только в новом
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// Построчное сравнение (общие начало и конец отбрасываются, середина — Myers)
+func DiffLines(a, b []string) []DiffLine {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	var out []DiffLine
+	for _, s := range a[:pre] {
+		out = append(out, DiffLine{' ', s})
+	}
+	out = append(out, myersDiff(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, s := range a[len(a)-suf:] {
+		out = append(out, DiffLine{' ', s})
+	}
+	return out
+}
+
+// Кратчайший список правок (алгоритм Майерса)
+func myersDiff(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	max := n + m
+	v := make([]int, 2*max+2)
+	// trace[d] — v[-d..d] перед шагом d
+	var trace [][]int
+	for d := 0; d <= max && d <= diffMaxEdits; d++ {
+		trace = append(trace, append([]int(nil), v[max-d:max+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				return diffBacktrack(trace, a, b)
+			}
+		}
+	}
+	// слишком много отличий: заменить всё
+	var out []DiffLine
+	for _, s := range a {
+		out = append(out, DiffLine{'-', s})
+	}
+	for _, s := range b {
+		out = append(out, DiffLine{'+', s})
+	}
+	return out
+}
+
+// Восстановить путь по сохранённым шагам
+func diffBacktrack(trace [][]int, a, b []string) []DiffLine {
+	var out []DiffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		get := func(k int) int { return v[k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		}
+		prevX := get(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			out = append(out, DiffLine{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			out = append(out, DiffLine{'+', b[y-1]})
+			y--
+		} else {
+			out = append(out, DiffLine{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		out = append(out, DiffLine{' ', a[x-1]})
+		x--
+		y--
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// Единый diff с заголовками; "" — отличий нет
+func UnifiedDiff(oldName, newName string, lines []DiffLine) string {
+	// номера строк (с 1) перед каждой строкой сравнения
+	oldNo, newNo := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	oldNo[0], newNo[0] = 1, 1
+	var changes []int
+	for i, l := range lines {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+		if l.Op != '+' {
+			oldNo[i+1]++
+		}
+		if l.Op != '-' {
+			newNo[i+1]++
+		}
+		if l.Op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(changes); {
+		from := max(changes[i]-diffContext, 0)
+		j := i
+		// изменения ближе двух контекстов — в одном блоке
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext {
+			j++
+		}
+		to := min(changes[j]+diffContext+1, len(lines))
+		oldCount, newCount := oldNo[to]-oldNo[from], newNo[to]-newNo[from]
+		oldStart, newStart := oldNo[from], newNo[from]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, l := range lines[from:to] {
+			b.WriteByte(l.Op)
+			b.WriteString(l.Text)
+			b.WriteByte('\n')
+		}
+		i = j + 1
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,98 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+// Сравнение в записи "-a +b  c": знак и текст строки через пробел
+func diffString(lines []DiffLine) string {
+	var parts []string
+	for _, l := range lines {
+		parts = append(parts, string(l.Op)+l.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string // строки через пробел
+		want string
+	}{
+		{"", "", ""},
+		{"a b c", "a b c", " a  b  c"},
+		{"a b c", "a x c", " a -b +x  c"},
+		{"a b c", "a c", " a -b  c"},
+		{"a c", "a b c", " a +b  c"},
+		{"a b", "", "-a -b"},
+		{"", "a", "+a"},
+		{"a b c d", "b c d a", "-a  b  c  d +a"},
+		{"x a y b z", "x b y a z", " x -a -y  b +y +a  z"},
+	}
+	for _, tt := range tests {
+		got := diffString(DiffLines(strings.Fields(tt.a), strings.Fields(tt.b)))
+		if got != tt.want {
+			t.Errorf("DiffLines(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMyersDiffIsMinimal(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"a b c a b b a", "c b a b a c", 5},
+		{"x y z", "a b c", 6},
+		{"a a a", "a a", 1},
+	}
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		lines := myersDiff(a, b)
+		var old, cur []string
+		edits := 0
+		for _, l := range lines {
+			if l.Op != '+' {
+				old = append(old, l.Text)
+			}
+			if l.Op != '-' {
+				cur = append(cur, l.Text)
+			}
+			if l.Op != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(old, " ") != tt.a || strings.Join(cur, " ") != tt.b {
+			t.Errorf("myersDiff(%q, %q) does not rebuild both sides: %q", tt.a, tt.b, diffString(lines))
+		}
+		if edits != tt.edits {
+			t.Errorf("myersDiff(%q, %q): %d edits, want %d", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := strings.Split("1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16", " ")
+	cur := append([]string(nil), old...)
+	cur[1] = "two"
+	cur = append(cur[:14], cur[15:]...) // без 15
+
+	want := strings.Join([]string{
+		"--- disk",
+		"+++ editor",
+		"@@ -1,5 +1,5 @@",
+		" 1", "-2", "+two", " 3", " 4", " 5",
+		"@@ -12,5 +12,4 @@",
+		" 12", " 13", " 14", "-15", " 16",
+	}, "\n")
+	if got := UnifiedDiff("disk", "editor", DiffLines(old, cur)); got != want {
+		t.Errorf("unifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("disk", "editor", DiffLines(old, old)); got != "" {
+		t.Errorf("unifiedDiff of equal texts = %q", got)
+	}
+	// вставка в пустой файл: старый блок начинается с 0
+	if got := UnifiedDiff("a", "b", DiffLines(nil, []string{"x"})); !strings.Contains(got, "@@ -0,0 +1,1 @@") {
+		t.Errorf("insertion into empty file:\n%s", got)
+	}
+}
@@ -0,0 +1,76 @@
+// Package editor — работа с текстом редактора, не зависящая от экрана:
+// границы и ширина графем для курсора и отрисовки, операции над
+// строками, смена регистра и построчное сравнение.
+package editor
+
+import "github.com/rivo/uniseg"
+
+// ---- Графемы ----
+//
+// Видимый символ может состоять из нескольких рун: буква с диакритикой,
+// эмодзи с ZWJ, флаг из двух региональных символов. Курсор редактора
+// по-прежнему хранится индексом руны, но встаёт только на границы графем:
+// стрелки, Backspace и Delete работают с графемой целиком, ширина
+// считается по графемам (rivo/uniseg).
+
+// Графема строки: первая руна, число рун и ширина в колонках
+type Grapheme struct {
+	Start, N, Width int
+}
+
+// Разбить строку на графемы
+func Graphemes(runes []rune) []Grapheme {
+	var gs []Grapheme
+	pos := 0
+	g := uniseg.NewGraphemes(string(runes))
+	for g.Next() {
+		n := len(g.Runes())
+		gs = append(gs, Grapheme{Start: pos, N: n, Width: g.Width()})
+		pos += n
+	}
+	return gs
+}
+
+// Начало графемы перед позицией x (0 — если x в начале строки)
+func PrevGrapheme(runes []rune, x int) int {
+	prev := 0
+	for _, g := range Graphemes(runes) {
+		if g.Start >= x {
+			break
+		}
+		prev = g.Start
+	}
+	return prev
+}
+
+// Конец графемы, начинающейся на позиции x или содержащей её
+func NextGrapheme(runes []rune, x int) int {
+	for _, g := range Graphemes(runes) {
+		if g.Start+g.N > x {
+			return g.Start + g.N
+		}
+	}
+	return len(runes)
+}
+
+// Ближайшая граница графемы не правее x
+func SnapGrapheme(runes []rune, x int) int {
+	if x >= len(runes) {
+		return len(runes)
+	}
+	for _, g := range Graphemes(runes) {
+		if g.Start+g.N > x {
+			return g.Start
+		}
+	}
+	return len(runes)
+}
+
+// Графемы по индексу первой руны: у остальных рун графемы n == 0
+func GraphemeSpans(runes []rune) []Grapheme {
+	spans := make([]Grapheme, len(runes))
+	for _, g := range Graphemes(runes) {
+		spans[g.Start] = g
+	}
+	return spans
+}
@@ -0,0 +1,45 @@
+package editor
+
+import (
+	"sort"
+	"strings"
+)
+
+// ---- Операции над строками ----
+//
+// Чистые функции для меню Alt+S: вход не меняется, результат — новый срез.
+
+// Сортировка без учёта регистра; равные остаются в исходном порядке
+func SortLines(ls []string, desc bool) []string {
+	res := append([]string{}, ls...)
+	sort.SliceStable(res, func(i, j int) bool {
+		x, y := strings.ToLower(res[i]), strings.ToLower(res[j])
+		if desc {
+			return x > y
+		}
+		return x < y
+	})
+	return res
+}
+
+// Убрать повторы (остаётся первое вхождение)
+func UniqueLines(ls []string) []string {
+	seen := map[string]bool{}
+	var res []string
+	for _, l := range ls {
+		if !seen[l] {
+			seen[l] = true
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// Строки в обратном порядке
+func ReverseLines(ls []string) []string {
+	res := make([]string, len(ls))
+	for i, l := range ls {
+		res[len(ls)-1-i] = l
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+package editor
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLineFuncs(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func([]string) []string
+		in   string
+		want string
+	}{
+		{"sort", func(ls []string) []string { return SortLines(ls, false) }, "b,A,c,a", "A,a,b,c"},
+		{"sort desc", func(ls []string) []string { return SortLines(ls, true) }, "b,A,c,a", "c,b,A,a"},
+		{"sort stable", func(ls []string) []string { return SortLines(ls, false) }, "x,B,b,a", "a,B,b,x"},
+		{"unique", UniqueLines, "a,b,a,,b,", "a,b,"},
+		{"unique keeps case", UniqueLines, "A,a", "A,a"},
+		{"reverse", ReverseLines, "1,2,3", "3,2,1"},
+	}
+	for _, tt := range tests {
+		in := strings.Split(tt.in, ",")
+		orig := slices.Clone(in)
+		if got := strings.Join(tt.fn(in), ","); got != tt.want {
+			t.Errorf("%s(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+		if !slices.Equal(in, orig) {
+			t.Errorf("%s changed its input", tt.name)
+		}
+	}
+}
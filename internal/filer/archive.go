@@ -0,0 +1,125 @@
+package filer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ---- Архивы ----
+//
+// Чтение .zip, .tar, .tar.gz и .tgz без распаковки: путь внутри архива
+// и обход его элементов. Имена, ведущие за пределы архива, пропускаются.
+
+// Архив ли это по имени
+func IsArchiveName(name string) bool {
+	low := strings.ToLower(name)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(low, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Разделить путь на файл архива и путь внутри него ("" — корень архива)
+func SplitArchivePath(p string) (archive, inner string, ok bool) {
+	p = filepath.Clean(p)
+	parts := strings.Split(p, string(filepath.Separator))
+	for i := range parts {
+		if !IsArchiveName(parts[i]) {
+			continue
+		}
+		prefix := strings.Join(parts[:i+1], string(filepath.Separator))
+		if prefix == "" {
+			continue
+		}
+		if info, err := os.Stat(prefix); err == nil && info.Mode().IsRegular() {
+			return prefix, path.Join(parts[i+1:]...), true
+		}
+	}
+	return "", "", false
+}
+
+// Элемент архива
+type Entry struct {
+	Name  string // путь внутри архива через "/", без "/" в конце
+	Size  int64
+	IsDir bool
+	Mode  fs.FileMode
+}
+
+// Безопасное имя элемента: без абсолютных путей и выходов через ".."
+func CleanEntryName(name string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./"))
+	if name == "." || path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	return name, true
+}
+
+// Пройти по элементам архива. open открывает содержимое текущего элемента.
+func WalkArchive(archive string, fn func(e Entry, open func() (io.ReadCloser, error)) error) error {
+	low := strings.ToLower(archive)
+	if strings.HasSuffix(low, ".zip") {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			name, ok := CleanEntryName(f.Name)
+			if !ok {
+				continue
+			}
+			e := Entry{Name: name, Size: int64(f.UncompressedSize64), IsDir: f.FileInfo().IsDir(), Mode: f.Mode()}
+			if err := fn(e, f.Open); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if strings.HasSuffix(low, ".gz") || strings.HasSuffix(low, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tarReader := tar.NewReader(r)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeDir {
+			continue // ссылки и устройства пропускаем
+		}
+		name, ok := CleanEntryName(hdr.Name)
+		if !ok {
+			continue
+		}
+		e := Entry{Name: name, Size: hdr.Size, IsDir: hdr.Typeflag == tar.TypeDir, Mode: hdr.FileInfo().Mode()}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tarReader), nil }
+		if err := fn(e, open); err != nil {
+			return err
+		}
+	}
+}
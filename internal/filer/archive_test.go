@@ -0,0 +1,68 @@
+package filer
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCleanEntryName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"docs/a.md", "docs/a.md", true},
+		{"./docs/", "docs", true},
+		{"docs/../a.md", "a.md", true},
+		{`docs\a.md`, "docs/a.md", true},
+		{"../evil", "", false},
+		{"docs/../../evil", "", false},
+		{`..\evil`, "", false},
+		{"/etc/passwd", "", false},
+		{"..", "", false},
+		{".", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := CleanEntryName(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("CleanEntryName(%q) = %q %v, want %q %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWalkArchiveSkipsUnsafeNames(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "x.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"ok/a.txt", "../evil.txt", "/abs.txt", `ok\..\..\up.txt`, "b.txt"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, name)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	var names []string
+	err = WalkArchive(archive, func(e Entry, open func() (io.ReadCloser, error)) error {
+		names = append(names, e.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ok/a.txt", "b.txt"}; !slices.Equal(names, want) {
+		t.Errorf("entries = %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,101 @@
+// Package filer — правила списка файлов, не зависящие от экрана:
+// естественный порядок имён и чтение архивов как папок.
+package filer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---- Естественная сортировка имён ----
+//
+// Список файлов сортируется «по-человечески»: числа внутри имени
+// сравниваются как числа (chapter2.md раньше chapter10.md), буквы — без
+// учёта регистра и по алфавиту, а не по кодам байтов (ё рядом с е).
+// Имена, равные с точностью до регистра и ведущих нулей, упорядочиваются
+// побайтно, чтобы порядок не зависел от порядка чтения папки.
+
+// Меньше ли имя a имени b в естественном порядке
+func NaturalLess(a, b string) bool {
+	if c := naturalCompare(a, b); c != 0 {
+		return c < 0
+	}
+	return a < b
+}
+
+// Сравнение: -1, 0 или 1 (без учёта регистра и ведущих нулей)
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		ra, sa := utf8.DecodeRuneInString(a)
+		rb, sb := utf8.DecodeRuneInString(b)
+		if isASCIIDigit(ra) && isASCIIDigit(rb) {
+			na, restA := digitRun(a)
+			nb, restB := digitRun(b)
+			if c := compareDigits(na, nb); c != 0 {
+				return c
+			}
+			a, b = restA, restB
+			continue
+		}
+		if c := sign(collateKey(ra) - collateKey(rb)); c != 0 {
+			return c
+		}
+		a, b = a[sa:], b[sb:]
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+func isASCIIDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// Цифры в начале s без ведущих нулей и остаток строки
+func digitRun(s string) (string, string) {
+	i := 0
+	for i < len(s) && isASCIIDigit(rune(s[i])) {
+		i++
+	}
+	n := s[:i]
+	for len(n) > 1 && n[0] == '0' {
+		n = n[1:]
+	}
+	return n, s[i:]
+}
+
+// Сравнить числа, записанные цифрами (любой длины)
+func compareDigits(a, b string) int {
+	switch {
+	case len(a) != len(b):
+		return sign(len(a) - len(b))
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Ключ символа: без учёта регистра, ё сразу после е
+func collateKey(r rune) int {
+	r = unicode.ToLower(r)
+	if r == 'ё' {
+		return 2*int('е') + 1
+	}
+	return 2 * int(r)
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
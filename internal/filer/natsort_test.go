@@ -0,0 +1,48 @@
+package filer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"chapter2.md", "chapter10.md", true},
+		{"chapter10.md", "chapter2.md", false},
+		{"a", "B", true},
+		{"B", "a", false},
+		{"file", "file1", true},
+		{"x99999999999999999999", "x100000000000000000000", true},
+		{"еж", "ёж", true},
+		{"ёж", "жук", true},
+		{"img007", "img7", true}, // равны без ведущих нулей — побайтно
+		{"img7", "img007", false},
+		{"A.txt", "a.txt", true},
+		{"same", "same", false},
+	}
+	for _, tt := range tests {
+		if got := NaturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("NaturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNaturalSort(t *testing.T) {
+	names := []string{"b10", "a", "B2", "b1", "Ёлка", "ель", "10", "9", "Zeta"}
+	slices.SortFunc(names, func(a, b string) int {
+		if NaturalLess(a, b) {
+			return -1
+		}
+		if NaturalLess(b, a) {
+			return 1
+		}
+		return 0
+	})
+	want := []string{"9", "10", "a", "b1", "B2", "b10", "Zeta", "ель", "Ёлка"}
+	if !slices.Equal(names, want) {
+		t.Errorf("sorted = %q, want %q", names, want)
+	}
+}
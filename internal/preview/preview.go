@@ -0,0 +1,82 @@
+// Package preview — буфер предпросмотра, не зависящий от экрана:
+// экранные строки документа и перевод позиций между исходными и
+// экранными строками.
+package preview
+
+import (
+	"sort"
+
+	"github.com/StasKrav/eddy_tcell/pkg/mdrender"
+)
+
+// ---- Буфер предпросмотра ----
+//
+// Предпросмотр строится заранее: документ разбирается в экранные
+// строки с готовыми стилями (pkg/mdrender), у каждой — номер исходной
+// строки. Одна исходная строка может дать несколько экранных (<br>,
+// перенос по ширине окна), так что прокрутка идёт по экранным строкам,
+// а при переключении режимов позиция переводится через номер исходной
+// строки. Буфер пересобирается только при изменении текста, стилей или
+// типографики, перенос — ещё и при изменении ширины окна.
+
+// Собранный буфер и то, из чего он собран
+type Cache struct {
+	content    string
+	styles     *mdrender.Styles
+	typography bool
+	rows       []mdrender.Line // по строке на исходную (и <br>)
+	width      int             // ширина переноса (0 — без переноса)
+	lines      []mdrender.Line // rows после переноса
+}
+
+// Экранные строки документа content, перенесённые по ширине width (0 —
+// без переноса). top — верхняя экранная строка окна; возвращается её
+// место в новом буфере: та же исходная строка.
+func (c *Cache) Lines(content string, styles *mdrender.Styles, opt mdrender.Options, width, top int) ([]mdrender.Line, int) {
+	old := c.lines
+	changed := false
+	if c.rows == nil || c.content != content || c.styles != styles || c.typography != opt.Typography {
+		c.content, c.styles, c.typography = content, styles, opt.Typography
+		c.rows = mdrender.Render(content, styles, opt)
+		changed = true
+	}
+	if changed || c.width != width || c.lines == nil {
+		c.width = width
+		c.lines = mdrender.Wrap(c.rows, width)
+		top = RemapRow(old, c.lines, top)
+	}
+	return c.lines, top
+}
+
+// Строка нового буфера на месте строки row старого: та же исходная
+// строка и, если её перенос стал короче, последняя её часть
+func RemapRow(old, lines []mdrender.Line, row int) int {
+	if len(old) == 0 || row <= 0 {
+		return max(row, 0)
+	}
+	row = min(row, len(old)-1)
+	src := old[row].Src
+	part := row - RowOf(old, src)
+	first := RowOf(lines, src)
+	for part > 0 && first+1 < len(lines) && lines[first+1].Src == src {
+		first++
+		part--
+	}
+	return first
+}
+
+// Первая экранная строка исходной строки src (или ближайшей после неё)
+func RowOf(lines []mdrender.Line, src int) int {
+	row := sort.Search(len(lines), func(k int) bool { return lines[k].Src >= src })
+	return min(row, max(len(lines)-1, 0))
+}
+
+// Есть ли в экранной строке руны исходной строки из [s, e)
+func HasPos(l mdrender.Line, s, e int) bool {
+	for _, c := range l.Cells {
+		if c.Pos >= s && c.Pos < e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package preview
+
+import (
+	"testing"
+
+	"github.com/StasKrav/eddy_tcell/pkg/mdrender"
+)
+
+// Экранные строки с исходными строками srcs
+func rows(srcs ...int) []mdrender.Line {
+	lines := make([]mdrender.Line, len(srcs))
+	for i, s := range srcs {
+		lines[i].Src = s
+	}
+	return lines
+}
+
+func TestRowOf(t *testing.T) {
+	lines := rows(0, 1, 1, 1, 3)
+	for _, tt := range []struct{ src, want int }{
+		{0, 0}, {1, 1}, {2, 4}, {3, 4}, {9, 4},
+	} {
+		if got := RowOf(lines, tt.src); got != tt.want {
+			t.Errorf("RowOf(%d) = %d, want %d", tt.src, got, tt.want)
+		}
+	}
+	if got := RowOf(nil, 3); got != 0 {
+		t.Errorf("RowOf(nil) = %d", got)
+	}
+}
+
+func TestRemapRow(t *testing.T) {
+	tests := []struct {
+		name       string
+		old, lines []mdrender.Line
+		row, want  int
+	}{
+		{"same", rows(0, 1, 2), rows(0, 1, 2), 2, 2},
+		{"line inserted above", rows(0, 1, 2), rows(0, 1, 1, 2), 2, 3},
+		{"part of wrapped line", rows(0, 1, 1, 1, 2), rows(0, 1, 1, 1, 1, 2), 3, 3},
+		{"wrap got shorter", rows(0, 1, 1, 1, 2), rows(0, 1, 2), 3, 1},
+		{"past the end", rows(0, 1), rows(0, 1), 7, 1},
+		{"top", rows(0, 1), rows(0, 0, 1), 0, 0},
+		{"no old buffer", nil, rows(0, 1, 2), 2, 2},
+	}
+	for _, tt := range tests {
+		if got := RemapRow(tt.old, tt.lines, tt.row); got != tt.want {
+			t.Errorf("%s: RemapRow = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	var c Cache
+	st := &mdrender.Styles{}
+	text := "one two three four\nfive"
+
+	lines, _ := c.Lines(text, st, mdrender.Options{}, 0, 0)
+	if len(lines) != 2 {
+		t.Fatalf("%d lines without wrapping, want 2", len(lines))
+	}
+	again, _ := c.Lines(text, st, mdrender.Options{}, 0, 0)
+	if &again[0] != &lines[0] {
+		t.Error("unchanged document was rebuilt")
+	}
+
+	// перенос по ширине: верхняя строка окна — та же исходная
+	lines, top := c.Lines(text, st, mdrender.Options{}, 8, 1)
+	if len(lines) != 4 || top != 3 || lines[top].Src != 1 {
+		t.Errorf("wrapped to 8: %d lines, top %d", len(lines), top)
+	}
+
+	lines, _ = c.Lines("# changed", st, mdrender.Options{}, 8, 0)
+	if len(lines) != 1 {
+		t.Errorf("after edit: %d lines, want 1", len(lines))
+	}
+}
+
+func TestHasPos(t *testing.T) {
+	l := mdrender.Line{Cells: []mdrender.Cell{{Pos: 2}, {Pos: 3}, {Pos: -1}}}
+	for _, tt := range []struct {
+		s, e int
+		want bool
+	}{
+		{0, 2, false}, {0, 3, true}, {3, 4, true}, {4, 9, false},
+	} {
+		if got := HasPos(l, tt.s, tt.e); got != tt.want {
+			t.Errorf("HasPos(%d, %d) = %v", tt.s, tt.e, got)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package theme
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// ---- Доступность ----
+//
+// [accessibility] в config.toml:
+//
+// no_color = "auto"  # "auto" — по переменной NO_COLOR, "on", "off"
+// min_contrast = 4.5 # минимальный контраст текста и фона (1–21, WCAG), 0 — не проверять
+//
+// Без цветов все цвета темы сбрасываются на цвета терминала, а выделение
+// передаётся инверсией, жирным и подчёркиванием. При min_contrast цвет
+// текста, слишком близкий к фону, сдвигается к белому или чёрному.
+// Контрастная тема — встроенная high-contrast (Alt+T).
+
+// Включён ли режим без цветов
+func NoColorMode(setting string) bool {
+	switch strings.ToLower(strings.TrimSpace(setting)) {
+	case "on", "true", "yes":
+		return true
+	case "off", "false", "no":
+		return false
+	}
+	// https://no-color.org: любое непустое значение
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Копия темы без цветов: фон выделенных элементов заменяется инверсией
+func (t *Theme) Monochrome() *Theme {
+	c := t.Clone()
+	mono := func(s *StyleSpec) {
+		if s.BG != "" {
+			s.Reverse = true
+		}
+		s.FG, s.BG = "", ""
+	}
+	ui := &c.UI
+	ui.Background, ui.Foreground, ui.Accent, ui.Cursor, ui.SelectionBG = "", "", "", "", ""
+	for _, p := range []*PanelStyle{&ui.LeftPanel, &ui.RightPanel} {
+		*p = PanelStyle{SelectedBold: p.SelectedBold}
+	}
+	ui.Scrollbar = ScrollbarStyle{}
+	for _, s := range []*StyleSpec{
+		&ui.Statusbar, &ui.Ruler,
+		&ui.FileList.FileItem, &ui.FileList.FileItemSelected, &ui.FileList.DirItem,
+		&ui.FileList.DirItemSelected, &ui.FileList.Cursor,
+		&ui.Notify.Info, &ui.Notify.Success, &ui.Notify.Warning, &ui.Notify.Error,
+		&ui.Dialog.Body, &ui.Dialog.Border, &ui.Dialog.Selected, &ui.Dialog.Input,
+		&ui.Spell, &ui.Bracket,
+		&ui.Conflict.Marker, &ui.Conflict.Ours, &ui.Conflict.Theirs,
+	} {
+		mono(s)
+	}
+	// строку курсора инверсией не выделяем — только убираем фон
+	ui.CursorLine = StyleSpec{}
+	ui.Dialog.Selected.Reverse = true
+	ui.Spell.Underline = true
+	ui.Bracket.Bold, ui.Bracket.Underline = true, true
+	ui.Conflict.Marker.Reverse, ui.Conflict.Theirs.Italic = true, true
+	for k, s := range ui.StatusSegments {
+		mono(&s)
+		ui.StatusSegments[k] = s
+	}
+
+	md := &c.Markdown
+	for _, s := range []*StyleSpec{&md.H1, &md.H2, &md.H3, &md.InlineCode, &md.CodeBlock,
+		&md.Link, &md.ListMarker, &md.Blockquote, &md.Math, &md.Table.Header, &md.HR} {
+		mono(s)
+	}
+	md.Math.Italic = true
+	md.CodeBlock.Reverse = false
+	md.H1.Bold, md.H1.Underline = true, true
+	md.H2.Bold, md.H3.Bold = true, true
+	md.Link.Underline = true
+	md.Table.Header.Bold = true
+	md.Table.Border = ""
+	// заголовок выноски — инверсией, тело — как обычный текст
+	for _, cs := range md.Callout.ByKind() {
+		mono(&cs.Title)
+		cs.Title.Bold = true
+		cs.Body = StyleSpec{}
+	}
+
+	for k, ft := range c.Filetype {
+		ft.FG, ft.BG = "", ""
+		for tk, s := range ft.Tokens {
+			mono(&s)
+			ft.Tokens[tk] = s
+		}
+		c.Filetype[k] = ft
+	}
+	return c
+}
+
+// Относительная яркость по WCAG
+func luminance(r, g, b int) float64 {
+	lin := func(c int) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*lin(r) + 0.7152*lin(g) + 0.0722*lin(b)
+}
+
+// Контраст двух цветов (1..21); ok=false — цвет терминала, он неизвестен
+func contrastRatio(fg, bg string) (float64, bool) {
+	f, b := ParseColor(fg), ParseColor(bg)
+	if f.Hex() < 0 || b.Hex() < 0 {
+		return 0, false
+	}
+	fr, fgG, fb := f.RGB()
+	br, bgG, bb := b.RGB()
+	l1, l2 := luminance(int(fr), int(fgG), int(fb)), luminance(int(br), int(bgG), int(bb))
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05), true
+}
+
+// Сдвинуть цвет текста к белому или чёрному (что контрастнее с фоном),
+// пока контраст не станет не меньше min
+func ensureContrast(fg, bg string, min float64) string {
+	ratio, ok := contrastRatio(fg, bg)
+	if !ok || ratio >= min {
+		return fg
+	}
+	r, g, b := ParseColor(fg).RGB()
+	// чёрный или белый: что контрастнее с фоном (их контрасты дают в произведении 21)
+	target := int32(255)
+	if black, _ := contrastRatio("#000000", bg); black > 21/black {
+		target = 0
+	}
+	for step := 1; step <= 20; step++ {
+		k := float64(step) / 20
+		mix := func(c int32) int { return int(math.Round(float64(c) + float64(target-c)*k)) }
+		c := fmt.Sprintf("#%02x%02x%02x", mix(r), mix(g), mix(b))
+		if ratio, _ := contrastRatio(c, bg); ratio >= min {
+			return c
+		}
+	}
+	return fmt.Sprintf("#%02x%02x%02x", target, target, target)
+}
+
+// Копия темы, где у каждой пары текст/фон контраст не меньше min.
+// Пустые цвета берутся, как при отрисовке, из foreground/background.
+func (t *Theme) WithContrast(min float64) *Theme {
+	c := t.Clone()
+	ui := &c.UI
+	fix := func(fg *string, bg string) {
+		f := *fg
+		if f == "" {
+			f = ui.Foreground
+		}
+		if bg == "" {
+			bg = ui.Background
+		}
+		if nf := ensureContrast(f, bg, min); nf != f {
+			*fg = nf
+		}
+	}
+	fixSpec := func(s *StyleSpec) { fix(&s.FG, s.BG) }
+
+	fix(&ui.Foreground, ui.Background)
+	for _, p := range []*PanelStyle{&ui.LeftPanel, &ui.RightPanel} {
+		fix(&p.FG, p.BG)
+		if p.SelectedBG != "" {
+			fix(&p.SelectedFG, p.SelectedBG)
+		}
+	}
+	for _, s := range []*StyleSpec{
+		&ui.Statusbar,
+		&ui.Notify.Info, &ui.Notify.Success, &ui.Notify.Warning, &ui.Notify.Error,
+		&ui.Dialog.Body,
+	} {
+		fixSpec(s)
+	}
+	// выделенный пункт и поле ввода лежат на фоне окна
+	for _, s := range []*StyleSpec{&ui.Dialog.Selected, &ui.Dialog.Input} {
+		bg := s.BG
+		if bg == "" {
+			bg = ui.Dialog.Body.BG
+		}
+		fix(&s.FG, bg)
+	}
+	for k, s := range ui.StatusSegments {
+		fixSpec(&s)
+		ui.StatusSegments[k] = s
+	}
+	md := &c.Markdown
+	for _, s := range []*StyleSpec{&md.H1, &md.H2, &md.H3, &md.InlineCode, &md.CodeBlock,
+		&md.Link, &md.ListMarker, &md.Blockquote, &md.Math, &md.Table.Header} {
+		fixSpec(s)
+	}
+	for _, cs := range md.Callout.ByKind() {
+		fixSpec(&cs.Title)
+		fixSpec(&cs.Body)
+	}
+	return c
+}
@@ -0,0 +1,143 @@
+package theme
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ---- Импорт схем base16 ----
+//
+// Файл схемы base16 (*.yaml, *.yml) можно указать вместо theme.toml:
+// в настройке theme или в inherit файла темы. Поддерживаются старый
+// формат (base00: "181818" на верхнем уровне) и новый (palette: с
+// вложенными ключами). 16 цветов раскладываются по элементам интерфейса
+// по рекомендациям base16: 00–07 — от фона к тексту, 08–0F — акценты.
+
+var base16HexRe = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+// Разобрать схему: имя и цвета base00…base0F в виде "#rrggbb"
+func ParseBase16(path string) (string, [16]string, error) {
+	var colors [16]string
+	name := ""
+	f, err := os.Open(path)
+	if err != nil {
+		return "", colors, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		// комментарий в конце строки (у значений без кавычек)
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		value = strings.Trim(value, `"'`)
+
+		switch {
+		case key == "scheme" || key == "name":
+			name = value
+		case len(key) == 6 && strings.HasPrefix(strings.ToLower(key), "base"):
+			var idx int
+			if _, err := fmt.Sscanf(strings.ToLower(key[4:]), "%x", &idx); err != nil || idx > 15 {
+				continue
+			}
+			if !base16HexRe.MatchString(value) {
+				return "", colors, fmt.Errorf("%s:%d: %s: bad color %q", path, n, key, value)
+			}
+			colors[idx] = "#" + strings.ToLower(strings.TrimPrefix(value, "#"))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", colors, err
+	}
+	for i, c := range colors {
+		if c == "" {
+			return "", colors, fmt.Errorf("%s: base%02X is missing", path, i)
+		}
+	}
+	return name, colors, nil
+}
+
+// Тема из схемы base16 поверх base (переносятся не задаваемые схемой ключи)
+func loadBase16(path string, base *Theme) (*Theme, error) {
+	_, c, err := ParseBase16(path)
+	if err != nil {
+		return nil, err
+	}
+	t := base.Clone()
+	ui := &t.UI
+	ui.Background, ui.Foreground = c[0x00], c[0x05]
+	ui.Accent, ui.Cursor, ui.SelectionBG = c[0x0D], c[0x0A], c[0x02]
+	ui.LeftPanel = PanelStyle{
+		FG: c[0x05], BG: c[0x00],
+		SelectedFG: c[0x00], SelectedBG: c[0x0D], SelectedBold: true,
+		DirFG: c[0x0D], SelectedDirFG: c[0x00],
+	}
+	ui.RightPanel = PanelStyle{FG: c[0x05], BG: c[0x00]}
+	ui.Statusbar = StyleSpec{FG: c[0x04], BG: c[0x01]}
+	ui.Scrollbar = ScrollbarStyle{Track: c[0x01], Thumb: c[0x03]}
+	ui.CursorLine = StyleSpec{BG: c[0x01]}
+	ui.Ruler = StyleSpec{FG: c[0x01]}
+	ui.Bracket = StyleSpec{BG: c[0x02], Bold: true}
+	ui.Conflict = ConflictTheme{
+		Marker: StyleSpec{FG: c[0x03], Bold: true},
+		Ours:   StyleSpec{FG: c[0x0B]},
+		Theirs: StyleSpec{FG: c[0x0D]},
+	}
+	ui.Notify = NotifyTheme{
+		Info:    StyleSpec{FG: c[0x05], BG: c[0x02]},
+		Success: StyleSpec{FG: c[0x00], BG: c[0x0B]},
+		Warning: StyleSpec{FG: c[0x00], BG: c[0x0A]},
+		Error:   StyleSpec{FG: c[0x07], BG: c[0x08], Bold: true},
+	}
+	ui.Dialog = DialogTheme{
+		Body:     StyleSpec{FG: c[0x05], BG: c[0x01]},
+		Border:   StyleSpec{FG: c[0x0D]},
+		Selected: StyleSpec{FG: c[0x00], BG: c[0x0D]},
+		Input:    StyleSpec{FG: c[0x06], BG: c[0x02]},
+	}
+	ui.Spell = StyleSpec{FG: c[0x08], Underline: true}
+
+	md := &t.Markdown
+	md.H1 = StyleSpec{FG: c[0x08], Bold: true}
+	md.H2 = StyleSpec{FG: c[0x09], Bold: true}
+	md.H3 = StyleSpec{FG: c[0x0A], Bold: true}
+	md.InlineCode = StyleSpec{FG: c[0x0B], BG: c[0x01]}
+	md.CodeBlock = StyleSpec{FG: c[0x05], BG: c[0x01]}
+	md.Link = StyleSpec{FG: c[0x0D], Underline: true}
+	md.ListMarker = StyleSpec{FG: c[0x0E], Bold: true}
+	md.Blockquote = StyleSpec{FG: c[0x03], Italic: true}
+	md.Math = StyleSpec{FG: c[0x0E]}
+	for kind, base := range map[string]string{
+		"note": c[0x0D], "tip": c[0x0B], "important": c[0x0E], "warning": c[0x0A], "caution": c[0x08],
+	} {
+		*md.Callout.ByKind()[kind] = CalloutStyle{
+			Title: StyleSpec{FG: base, BG: c[0x01], Bold: true},
+			Body:  StyleSpec{BG: c[0x01]},
+		}
+	}
+	md.Table.Header = StyleSpec{FG: c[0x06], Bold: true}
+	md.Table.Border = c[0x03]
+	md.HR = StyleSpec{FG: c[0x03]}
+	return t, nil
+}
+
+// Файл схемы base16?
+func IsBase16File(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
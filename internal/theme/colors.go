@@ -0,0 +1,131 @@
+package theme
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Глубина цвета терминала ----
+//
+// Темы задают цвета в RGB. Если терминал не умеет truecolor, цвета
+// заранее сводятся к ближайшим из 256- или 16-цветной палитры.
+// Режим задаётся в config.toml: colors = "auto" | "truecolor" | "256" | "16".
+
+// Сколько цветов палитры использовать; 0 — truecolor, без ограничений
+var ColorLimit = 0
+
+// Палитры для сведения цветов (заполняются по требованию)
+var palettes = map[int][]tcell.Color{}
+
+// Определить число цветов по настройке и возможностям терминала
+func DetectColorLimit(setting string, screen tcell.Screen) int {
+	switch strings.ToLower(strings.TrimSpace(setting)) {
+	case "truecolor", "24bit":
+		return 0
+	case "256":
+		return 256
+	case "16":
+		return 16
+	case "8":
+		return 8
+	}
+	ct := strings.ToLower(os.Getenv("COLORTERM"))
+	if ct == "truecolor" || ct == "24bit" {
+		return 0
+	}
+	n := screen.Colors()
+	switch {
+	case n > 256:
+		return 0
+	case n >= 256:
+		return 256
+	case n >= 16:
+		return 16
+	case n > 0:
+		return 8
+	}
+	return 0
+}
+
+// Свести RGB-цвет к ближайшему цвету палитры
+func quantizeColor(c tcell.Color) tcell.Color {
+	if ColorLimit == 0 || !c.IsRGB() {
+		return c
+	}
+	pal, ok := palettes[ColorLimit]
+	if !ok {
+		pal = make([]tcell.Color, ColorLimit)
+		for i := range pal {
+			pal[i] = tcell.PaletteColor(i)
+		}
+		palettes[ColorLimit] = pal
+	}
+	return tcell.FindColor(c, pal)
+}
+
+// ---- Парсинг цвета (hex + числа + имена) ----
+func ParseColor(s string) tcell.Color {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return tcell.ColorDefault
+	}
+	lower := strings.ToLower(s)
+	if lower == "default" || lower == "terminal" || lower == "none" || lower == "transparent" {
+		return tcell.ColorDefault
+	}
+
+	// hex #RRGGBB или #RGB
+	if strings.HasPrefix(s, "#") {
+		hex := strings.TrimPrefix(s, "#")
+		if len(hex) == 3 {
+			// expand "abc" -> "aabbcc"
+			expanded := make([]byte, 6)
+			for i := 0; i < 3; i++ {
+				expanded[i*2] = hex[i]
+				expanded[i*2+1] = hex[i]
+			}
+			hex = string(expanded)
+		}
+		if len(hex) == 6 {
+			if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+				r := int32((v >> 16) & 0xFF)
+				g := int32((v >> 8) & 0xFF)
+				b := int32(v & 0xFF)
+				return quantizeColor(tcell.NewRGBColor(r, g, b))
+			}
+		}
+	}
+
+	// числовой код (0..255)
+	if n, err := strconv.Atoi(s); err == nil {
+		return tcell.Color(n)
+	}
+
+	// имена
+	switch lower {
+	case "black":
+		return tcell.ColorBlack
+	case "red":
+		return tcell.ColorRed
+	case "green":
+		return tcell.ColorGreen
+	case "yellow":
+		return tcell.ColorYellow
+	case "blue":
+		return tcell.ColorBlue
+	case "magenta", "purple":
+		return tcell.ColorPurple
+	case "cyan", "teal":
+		return tcell.ColorTeal
+	case "white":
+		return tcell.ColorWhite
+	case "gray", "grey":
+		return tcell.ColorGrey
+	}
+
+	return tcell.ColorDefault
+
+}
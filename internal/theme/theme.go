@@ -0,0 +1,262 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ---- Наследование тем ----
+//
+// Файл темы может начинаться с inherit = "имя" (встроенная тема) или
+// inherit = "путь/к/теме.toml" (относительно файла темы) и задавать
+// только отличающиеся ключи — остальное берётся из базовой темы.
+// Базой может быть и схема base16 (см. base16.go). Таблицы
+// [filetype.<расширение>] меняют цвета редактора для файлов этого типа.
+// Без inherit базой служит тема по умолчанию.
+
+// Встроенные темы по имени
+var Builtin = map[string]*Theme{
+	"default": &Default,
+	"dark":    &Default,
+	"light":   &DefaultLight,
+}
+
+// Максимальная глубина цепочки inherit
+const maxInherit = 8
+
+// Глубокая копия темы (чтобы декодирование не портило базовую)
+func (t *Theme) Clone() *Theme {
+	c := *t
+	if t.UI.StatusSegments != nil {
+		c.UI.StatusSegments = make(map[string]StyleSpec, len(t.UI.StatusSegments))
+		for k, v := range t.UI.StatusSegments {
+			c.UI.StatusSegments[k] = v
+		}
+	}
+	if t.Filetype != nil {
+		c.Filetype = make(map[string]FiletypeTheme, len(t.Filetype))
+		for k, v := range t.Filetype {
+			if v.Tokens != nil {
+				tokens := make(map[string]StyleSpec, len(v.Tokens))
+				for tk, tv := range v.Tokens {
+					tokens[tk] = tv
+				}
+				v.Tokens = tokens
+			}
+			c.Filetype[k] = v
+		}
+	}
+	return &c
+}
+
+// Копия темы для прозрачного фона: основной фон и фоны панелей не задаются,
+// остаются заливки элементов (строка статуса, окна, блоки кода)
+func (t *Theme) WithoutBackground() *Theme {
+	c := t.Clone()
+	c.UI.Background = ""
+	c.UI.LeftPanel.BG = ""
+	c.UI.RightPanel.BG = ""
+	for k, ft := range c.Filetype {
+		ft.BG = ""
+		c.Filetype[k] = ft
+	}
+	return c
+}
+
+// Ключ [filetype.*] для файла: расширение без точки в нижнем регистре
+func FiletypeKey(path string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Переопределения для файла по расширению (без точки, без учёта регистра)
+func (t *Theme) filetype(path string) (FiletypeTheme, bool) {
+	ext := FiletypeKey(path)
+	if ext == "" || t.Filetype == nil {
+		return FiletypeTheme{}, false
+	}
+	ft, ok := t.Filetype[ext]
+	return ft, ok
+}
+
+// Разобрать файл темы поверх базовой с учётом inherit
+func DecodeFile(path string, base *Theme, depth int) (*Theme, error) {
+	if depth > maxInherit {
+		return nil, fmt.Errorf("inherit chain is too long (cycle?) at %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeData(path, string(data), filepath.Dir(path), base, depth)
+}
+
+// Разобрать текст темы; name — для сообщений, dir — откуда считать
+// относительные пути inherit. Замечания (themeWarnings) возвращаются
+// вместе с темой, см. themecheck.go.
+func DecodeData(name, data, dir string, base *Theme, depth int) (*Theme, error) {
+	var head struct {
+		Inherit string `toml:"inherit"`
+	}
+	if _, err := toml.Decode(data, &head); err != nil {
+		return nil, themeParseError(name, err)
+	}
+	var warnings Warnings
+	if head.Inherit != "" {
+		parent, err := Resolve(head.Inherit, dir, base, depth+1)
+		if w, ok := err.(Warnings); ok {
+			warnings = append(warnings, w...)
+			err = nil
+		}
+		if err != nil && depth == 0 {
+			return nil, fmt.Errorf("inherit %q: %v", head.Inherit, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		base = parent
+	}
+	t := base.Clone()
+	md, err := toml.Decode(data, t)
+	if err != nil {
+		return nil, themeParseError(name, err)
+	}
+	warnings = append(warnings, checkTheme(name, data, md, t, base)...)
+	if len(warnings) > 0 {
+		return t, warnings
+	}
+	return t, nil
+}
+
+// Найти тему по имени встроенной или по пути к файлу
+func Resolve(ref, dir string, base *Theme, depth int) (*Theme, error) {
+	if t, ok := Builtin[strings.ToLower(ref)]; ok {
+		return t, nil
+	}
+	path := ref
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if filepath.Ext(path) == "" {
+		path += ".toml"
+	}
+	if IsBase16File(path) {
+		return loadBase16(path, base)
+	}
+	return DecodeFile(path, base, depth)
+}
+
+// Обойти все значения темы (string и bool) в порядке объявления полей;
+// ключи — как в файле темы
+func walk(t *Theme, fn func(key string, v reflect.Value)) {
+	var walk func(v reflect.Value, key string)
+	walk = func(v reflect.Value, key string) {
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				tag := strings.Split(v.Type().Field(i).Tag.Get("toml"), ",")[0]
+				if tag != "" && tag != "-" {
+					walk(v.Field(i), strings.TrimPrefix(key+"."+tag, "."))
+				}
+			}
+		case reflect.Map:
+			keys := v.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, mk := range keys {
+				walk(v.MapIndex(mk), key+"."+mk.String())
+			}
+		default:
+			fn(key, v)
+		}
+	}
+	walk(reflect.ValueOf(t).Elem(), "")
+}
+
+// Тема в виде плоского списка "ключ" → значение (string или bool)
+func Flatten(t *Theme) map[string]interface{} {
+	res := map[string]interface{}{}
+	walk(t, func(key string, v reflect.Value) {
+		res[key] = v.Interface()
+	})
+	return res
+}
+
+// Ключи темы в порядке объявления полей
+func Keys(t *Theme) []string {
+	var keys []string
+	walk(t, func(key string, _ reflect.Value) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Задать значение по ключу из flattenTheme; false — ключа нет или тип другой
+func SetValue(t *Theme, key string, value interface{}) bool {
+	var set func(v reflect.Value, path []string) bool
+	set = func(v reflect.Value, path []string) bool {
+		if len(path) == 0 {
+			nv := reflect.ValueOf(value)
+			if nv.Type() != v.Type() {
+				return false
+			}
+			v.Set(nv)
+			return true
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				if strings.Split(v.Type().Field(i).Tag.Get("toml"), ",")[0] == path[0] {
+					return set(v.Field(i), path[1:])
+				}
+			}
+		case reflect.Map:
+			mk := reflect.ValueOf(path[0])
+			if v.IsNil() || !v.MapIndex(mk).IsValid() {
+				return false
+			}
+			// значения карты неадресуемы: правим копию и кладём обратно
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(mk))
+			if !set(elem, path[1:]) {
+				return false
+			}
+			v.SetMapIndex(mk, elem)
+			return true
+		}
+		return false
+	}
+	return set(reflect.ValueOf(t).Elem(), strings.Split(key, "."))
+}
+
+// Ключи, которые отличаются у двух тем (по алфавиту)
+func Diff(a, b *Theme) []string {
+	fa, fb := Flatten(a), Flatten(b)
+	var keys []string
+	for k, v := range fb {
+		if fa[k] != v {
+			keys = append(keys, k)
+		}
+	}
+	for k := range fa {
+		if _, ok := fb[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Светлая ли тема (по яркости фона)
+func (t *Theme) IsLight() bool {
+	c := ParseColor(t.UI.Background)
+	if c.Hex() < 0 {
+		return false
+	}
+	r, g, b := c.RGB()
+	return 0.2126*float64(r)+0.7152*float64(g)+0.0722*float64(b) > 127
+}
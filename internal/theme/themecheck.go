@@ -0,0 +1,164 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ---- Проверка файла темы ----
+//
+// Ошибка разбора TOML не даёт загрузить тему и показывается с файлом и
+// строкой. Неизвестные ключи и неверные цвета тему не ломают: такие
+// значения берутся из базовой темы, а замечания (файл:строка: ключ)
+// показываются в уведомлениях.
+
+// Замечания к теме: тема загружена, но часть значений пропущена
+type Warnings []string
+
+func (w Warnings) Error() string {
+	return strings.Join(w, "; ")
+}
+
+// Ошибки несовпадения типов toml пишет текстом: toml: line 2 (last key "a.b"): …
+var tomlTypeErrRe = regexp.MustCompile(`^toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// Ошибка разбора с файлом, строкой и ключом
+func themeParseError(name string, err error) error {
+	var pe toml.ParseError
+	if !errors.As(err, &pe) {
+		if m := tomlTypeErrRe.FindStringSubmatch(err.Error()); m != nil {
+			return fmt.Errorf("%s:%s: %s (key %s)", name, m[1], m[3], m[2])
+		}
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	msg := fmt.Sprintf("%s:%d: %s", name, pe.Position.Line, pe.Message)
+	if pe.LastKey != "" {
+		msg += " (key " + pe.LastKey + ")"
+	}
+	return errors.New(msg)
+}
+
+// Допустимое ли значение цвета (те же формы, что понимает parseColor)
+func ValidColor(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "default", "terminal", "none", "transparent",
+		"black", "red", "green", "yellow", "blue", "magenta", "purple",
+		"cyan", "teal", "white", "gray", "grey":
+		return true
+	}
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil && (len(hex) == 3 || len(hex) == 6)
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Номер строки, где в тексте темы задан ключ или таблица (0 — не найден)
+func keyLine(data string, key toml.Key) int {
+	want := strings.Join(key, ".")
+	table := ""
+	clean := func(s string) string {
+		parts := strings.Split(s, ".")
+		for i, p := range parts {
+			parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+		}
+		return strings.Join(parts, ".")
+	}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			table = clean(strings.Trim(line, "[] \t"))
+			if table == want {
+				return i + 1
+			}
+			continue
+		}
+		k, _, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		full := clean(k)
+		if table != "" {
+			full = table + "." + full
+		}
+		if full == want {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Проверить декодированную тему: неизвестные ключи и неверные цвета
+// (только заданные в этом файле). Неверные цвета возвращаются к base.
+func checkTheme(name, data string, md toml.MetaData, t, base *Theme) Warnings {
+	var w Warnings
+	where := func(key toml.Key) string {
+		if n := keyLine(data, key); n > 0 {
+			return fmt.Sprintf("%s:%d", name, n)
+		}
+		return name
+	}
+
+	// неизвестные ключи: про таблицу сообщаем один раз, без её содержимого
+	reported := map[string]bool{}
+	for _, key := range md.Undecoded() {
+		if len(key) == 1 && key[0] == "inherit" {
+			continue
+		}
+		skip := false
+		for i := 1; i < len(key); i++ {
+			if reported[strings.Join(key[:i], ".")] {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+		reported[key.String()] = true
+		w = append(w, fmt.Sprintf("%s: unknown key %s", where(key), key))
+	}
+
+	// цвета: все строковые поля темы
+	var walk func(v, b reflect.Value, key toml.Key)
+	walk = func(v, b reflect.Value, key toml.Key) {
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				tag := strings.Split(v.Type().Field(i).Tag.Get("toml"), ",")[0]
+				if tag == "" || tag == "-" {
+					continue
+				}
+				walk(v.Field(i), b.Field(i), append(key[:len(key):len(key)], tag))
+			}
+		case reflect.Map:
+			for _, mk := range v.MapKeys() {
+				// значения карты неадресуемы: правим копию и кладём обратно
+				elem := reflect.New(v.Type().Elem()).Elem()
+				elem.Set(v.MapIndex(mk))
+				belem := reflect.New(v.Type().Elem()).Elem()
+				if b.IsValid() && !b.IsNil() && b.MapIndex(mk).IsValid() {
+					belem.Set(b.MapIndex(mk))
+				}
+				walk(elem, belem, append(key[:len(key):len(key)], mk.String()))
+				v.SetMapIndex(mk, elem)
+			}
+		case reflect.String:
+			if !md.IsDefined(key...) || ValidColor(v.String()) {
+				return
+			}
+			w = append(w, fmt.Sprintf("%s: %s: bad color %q", where(key), key, v.String()))
+			v.SetString(b.String())
+		}
+	}
+	walk(reflect.ValueOf(t).Elem(), reflect.ValueOf(base).Elem(), nil)
+	return w
+}
@@ -0,0 +1,126 @@
+package theme
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestValidColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"", true},
+		{"default", true},
+		{"Transparent", true},
+		{" grey ", true},
+		{"#fff", true},
+		{"#12AB56", true},
+		{"0", true},
+		{"255", true},
+		{"#12", false},
+		{"#1234567", false},
+		{"#ggg", false},
+		{"256", false},
+		{"-1", false},
+		{"bogus", false},
+	}
+	for _, tt := range tests {
+		if got := ValidColor(tt.s); got != tt.want {
+			t.Errorf("ValidColor(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestKeyLine(t *testing.T) {
+	data := strings.Join([]string{
+		`inherit = "light"`,
+		`[ui]`,
+		`# accent = "#000"`,
+		`accent = "#fff"`,
+		`[ui.scrollbar]`,
+		`track = "x"`,
+		`[ "ui" . "file_list" ]`,
+		`"fg" = "red"`,
+	}, "\n")
+	tests := []struct {
+		key  toml.Key
+		want int
+	}{
+		{toml.Key{"inherit"}, 1},
+		{toml.Key{"ui"}, 2},
+		{toml.Key{"ui", "accent"}, 4},
+		{toml.Key{"ui", "scrollbar"}, 5},
+		{toml.Key{"ui", "scrollbar", "track"}, 6},
+		{toml.Key{"ui", "file_list", "fg"}, 8},
+		{toml.Key{"accent"}, 0},
+		{toml.Key{"ui", "cursor"}, 0},
+	}
+	for _, tt := range tests {
+		if got := keyLine(data, tt.key); got != tt.want {
+			t.Errorf("keyLine(%v) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeDataWarnings(t *testing.T) {
+	data := strings.Join([]string{
+		`[ui]`,
+		`accent = "#zzz"`,
+		`cursor = "#ffcc00"`,
+		`colour = "red"`,
+		`[nope]`,
+		`a = 1`,
+		`b = 2`,
+		`[ui.scrollbar]`,
+		`thumb = "300"`,
+	}, "\n")
+	th, err := DecodeData("t.toml", data, "", &Default, 0)
+	var w Warnings
+	if !errors.As(err, &w) {
+		t.Fatalf("DecodeData error = %v, want Warnings", err)
+	}
+	want := Warnings{
+		"t.toml:4: unknown key ui.colour",
+		"t.toml:5: unknown key nope",
+		`t.toml:2: ui.accent: bad color "#zzz"`,
+		`t.toml:9: ui.scrollbar.thumb: bad color "300"`,
+	}
+	slices.Sort(w)
+	slices.Sort(want)
+	if !slices.Equal(w, want) {
+		t.Errorf("warnings:\n got %q\nwant %q", w, want)
+	}
+	if th.UI.Accent != Default.UI.Accent {
+		t.Errorf("accent = %q, want base %q", th.UI.Accent, Default.UI.Accent)
+	}
+	if th.UI.Scrollbar.Thumb != Default.UI.Scrollbar.Thumb {
+		t.Errorf("thumb = %q, want base %q", th.UI.Scrollbar.Thumb, Default.UI.Scrollbar.Thumb)
+	}
+	if th.UI.Cursor != "#ffcc00" {
+		t.Errorf("cursor = %q, want #ffcc00", th.UI.Cursor)
+	}
+}
+
+func TestDecodeDataErrors(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"clean", "[ui]\naccent = \"#fff\"\n", ""},
+		{"syntax", "[ui]\naccent = \"#fff\"\ncursor = \n", "t.toml:3: "},
+		{"type", "[ui]\naccent = 5\n", "t.toml:2: "},
+	}
+	for _, tt := range tests {
+		_, err := DecodeData("t.toml", tt.data, "", &Default, 0)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.want != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.want)):
+			t.Errorf("%s: error = %v, want prefix %q", tt.name, err, tt.want)
+		}
+	}
+}
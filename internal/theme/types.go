@@ -0,0 +1,420 @@
+// Package theme — модель темы eddy: типы theme.toml, встроенные темы,
+// наследование (inherit), схемы base16, проверка файла, доступность и
+// разбор цветов под возможности терминала. Готовые стили tcell для
+// отрисовки собираются в основном пакете (resolved.go).
+package theme
+
+// ---- Структура темы (TOML) ----
+//
+// Файл темы: ~/.config/eddy/theme.toml
+//
+// Пример (минимальный):
+//
+// inherit = "light"   # необязательно: базовая тема (см. theme.go)
+//
+// [ui]
+// background = "#0f1117"
+// foreground = "#c9d1d9"
+// accent = "#58a6ff"
+// cursor = "#ffcc00"
+// selection_bg = "#223244"
+//
+// [ui.left_panel]
+// fg = "#c9d1d9"
+// bg = "#111217"
+// selected_fg = "#0f1724"
+// selected_bg = "#58a6ff"
+// selected_bold = true
+//
+// [ui.right_panel]
+// fg = "#c9d1d9"
+// bg = "#0f1117"
+//
+// [ui.statusbar]
+// fg = "#9aa4b2"
+// bg = "#0b1220"
+//
+// [ui.scrollbar]
+// track = "#1c2128"
+// thumb = "#3b4252"
+//
+// [ui.cursorline]
+// bg = "#161b22"
+//
+// [ui.ruler]
+// fg = "#21262d"
+//
+// [ui.notify.error]
+// fg = "#ffffff"
+// bg = "#b42318"
+//
+// [ui.dialog.body]
+// fg = "#c9d1d9"
+// bg = "#161b22"
+//
+// [ui.spell]
+// fg = "#ff7b72"
+// underline = true
+//
+// [ui.bracket]
+// bg = "#30363d"
+// bold = true
+//
+// [markdown.h1]
+// fg = "#ff7ab6"
+// bold = true
+//
+// [markdown.inline_code]
+// fg = "#111827"
+// bg = "#f8fafc"
+//
+// …и т.д.
+//
+// Полная схема реализована в типах ниже.
+type BorderStyle struct {
+	FG string `toml:"fg"`
+}
+
+// Заголовки панелей.
+type TitleTheme struct {
+	FG, BG                  string
+	Bold, Italic, Underline bool
+}
+
+type UITitleTheme struct {
+	Background string
+	Foreground string
+	Accent     string
+	Border     BorderStyle
+
+	TitleLeftFiles    TitleTheme
+	TitleRightEditor  TitleTheme
+	TitleRightPreview TitleTheme
+
+	// ...
+}
+
+// Элементы левой панели (file list)
+type FileListTheme struct {
+	FileItem         StyleSpec `toml:"file_item"`
+	FileItemSelected StyleSpec `toml:"file_item_selected"`
+	DirItem          StyleSpec `toml:"dir_item"`
+	DirItemSelected  StyleSpec `toml:"dir_item_selected"`
+	Cursor           StyleSpec `toml:"cursor"`
+}
+
+// StyleSpec описывает стиль для одного элемента
+type StyleSpec struct {
+	FG        string `toml:"fg" json:"fg"`
+	BG        string `toml:"bg" json:"bg"`
+	Bold      bool   `toml:"bold" json:"bold"`
+	Italic    bool   `toml:"italic" json:"italic"`
+	Underline bool   `toml:"underline" json:"underline"`
+	Reverse   bool   `toml:"reverse" json:"reverse"`
+}
+
+// PanelStyle — отдельный тип для панелей, с опциями для выделения
+type PanelStyle struct {
+	FG            string `toml:"fg"`
+	BG            string `toml:"bg"`
+	SelectedFG    string `toml:"selected_fg"`
+	SelectedBG    string `toml:"selected_bg"`
+	SelectedBold  bool   `toml:"selected_bold"`
+	DirFG         string `toml:"dir_fg"`
+	SelectedDirFG string `toml:"selected_dir_fg"`
+}
+
+// ScrollbarStyle — цвета полосы прокрутки
+type ScrollbarStyle struct {
+	Track string `toml:"track"`
+	Thumb string `toml:"thumb"`
+}
+
+// DialogTheme — стили модальных окон
+type DialogTheme struct {
+	Body     StyleSpec `toml:"body"`
+	Border   StyleSpec `toml:"border"`
+	Selected StyleSpec `toml:"selected"`
+	Input    StyleSpec `toml:"input"`
+}
+
+// NotifyTheme — стили уведомлений по уровням
+type NotifyTheme struct {
+	Info    StyleSpec `toml:"info"`
+	Success StyleSpec `toml:"success"`
+	Warning StyleSpec `toml:"warning"`
+	Error   StyleSpec `toml:"error"`
+}
+
+// UITheme — общие цвета приложения
+type UITheme struct {
+	Background  string `toml:"background"`
+	Foreground  string `toml:"foreground"`
+	Accent      string `toml:"accent"`
+	Cursor      string `toml:"cursor"`
+	SelectionBG string `toml:"selection_bg"`
+	// Не закрашивать фон: вместо background и фонов панелей — фон терминала
+	Transparent bool           `toml:"transparent"`
+	LeftPanel   PanelStyle     `toml:"left_panel"`
+	RightPanel  PanelStyle     `toml:"right_panel"`
+	Statusbar   StyleSpec      `toml:"statusbar"`
+	FileList    FileListTheme  `toml:"file_list"`
+	Scrollbar   ScrollbarStyle `toml:"scrollbar"`
+	CursorLine  StyleSpec      `toml:"cursorline"`
+	Ruler       StyleSpec      `toml:"ruler"`
+	// Стили отдельных сегментов статусной строки (по имени сегмента)
+	StatusSegments map[string]StyleSpec `toml:"status_segments"`
+	Notify         NotifyTheme          `toml:"notify"`
+	Dialog         DialogTheme          `toml:"dialog"`
+	// Слова с орфографическими ошибками (см. spell.go)
+	Spell StyleSpec `toml:"spell"`
+	// Парные скобки у курсора (см. brackets.go)
+	Bracket StyleSpec `toml:"bracket"`
+	// Блоки конфликтов слияния (см. conflict.go)
+	Conflict ConflictTheme `toml:"conflict"`
+}
+
+// ConflictTheme — строки-маркеры и две стороны конфликта
+type ConflictTheme struct {
+	Marker StyleSpec `toml:"marker"`
+	Ours   StyleSpec `toml:"ours"`
+	Theirs StyleSpec `toml:"theirs"`
+}
+
+// FileListTheme — стили для элементов левой панели (списка файлов)
+// type FileListTheme struct {
+// 	FileItem         StyleSpec `toml:"file_item"`
+// 	FileItemSelected StyleSpec `toml:"file_item_selected"`
+// 	DirItem          StyleSpec `toml:"dir_item"`
+// 	DirItemSelected  StyleSpec `toml:"dir_item_selected"`
+// 	Cursor           StyleSpec `toml:"cursor"`
+// }
+
+// MarkdownTheme — стили Markdown
+type MarkdownTheme struct {
+	H1         StyleSpec `toml:"h1"`
+	H2         StyleSpec `toml:"h2"`
+	H3         StyleSpec `toml:"h3"`
+	InlineCode StyleSpec `toml:"inline_code"`
+	CodeBlock  StyleSpec `toml:"codeblock"`
+	Link       StyleSpec `toml:"link"`
+	ListMarker StyleSpec `toml:"list_marker"`
+	Blockquote StyleSpec `toml:"blockquote"`
+	// Формулы $…$ и $$…$$ (см. pkg/mdrender/math.go)
+	Math StyleSpec `toml:"math"`
+	// Выноски > [!NOTE] (см. pkg/mdrender/callout.go)
+	Callout CalloutTheme `toml:"callout"`
+	Table   struct {
+		Header StyleSpec `toml:"header"`
+		Border string    `toml:"border"`
+	} `toml:"table"`
+	HR StyleSpec `toml:"hr"`
+}
+
+// FiletypeTheme — переопределения для файлов с данным расширением
+type FiletypeTheme struct {
+	FG string `toml:"fg"`
+	BG string `toml:"bg"`
+	// Цвета токенов подсветки синтаксиса: keyword, string, comment…
+	Tokens map[string]StyleSpec `toml:"tokens"`
+}
+
+// Theme — корневая структура
+type Theme struct {
+	UI       UITheme       `toml:"ui"`
+	Markdown MarkdownTheme `toml:"markdown"`
+	// Переопределения по расширению файла: [filetype.go], [filetype.yaml]
+	Filetype map[string]FiletypeTheme `toml:"filetype"`
+}
+
+// дефолтная тема (fallback)
+var Default = Theme{
+	UI: UITheme{
+		Background:  "#0f1117",
+		Foreground:  "#c9d1d9",
+		Accent:      "#88d4ab",
+		Cursor:      "#ffcc00",
+		SelectionBG: "#223244",
+		LeftPanel: PanelStyle{
+			FG:           "#444444",
+			BG:           "#111217",
+			SelectedFG:   "#111111",
+			SelectedBG:   "#999999",
+			SelectedBold: true,
+		},
+		RightPanel: PanelStyle{
+			FG: "#c9d1d9",
+			BG: "#0f1117",
+		},
+		Statusbar: StyleSpec{
+			FG: "#9aa4b2",
+			BG: "#0b1220",
+		},
+		Scrollbar: ScrollbarStyle{
+			Track: "#1c2128",
+			Thumb: "#3b4252",
+		},
+		CursorLine: StyleSpec{
+			BG: "#161b22",
+		},
+		Ruler: StyleSpec{
+			FG: "#21262d",
+		},
+		Notify: NotifyTheme{
+			Info:    StyleSpec{FG: "#c9d1d9", BG: "#1f2937"},
+			Success: StyleSpec{FG: "#0f1117", BG: "#88d4ab"},
+			Warning: StyleSpec{FG: "#0f1117", BG: "#ffd166"},
+			Error:   StyleSpec{FG: "#ffffff", BG: "#b42318", Bold: true},
+		},
+		Dialog: DialogTheme{
+			Body:     StyleSpec{FG: "#c9d1d9", BG: "#161b22"},
+			Border:   StyleSpec{FG: "#88d4ab"},
+			Selected: StyleSpec{FG: "#0f1117", BG: "#88d4ab"},
+			Input:    StyleSpec{FG: "#e6edf3", BG: "#21262d"},
+		},
+		Spell:   StyleSpec{FG: "#ff7b72", Underline: true},
+		Bracket: StyleSpec{BG: "#30363d", Bold: true},
+		Conflict: ConflictTheme{
+			Marker: StyleSpec{FG: "#8b949e", Bold: true},
+			Ours:   StyleSpec{BG: "#12261e"},
+			Theirs: StyleSpec{BG: "#0c2d6b"},
+		},
+	},
+	Markdown: MarkdownTheme{
+		H1: StyleSpec{FG: "#ff7ab6", Bold: false},
+		H2: StyleSpec{FG: "#ff9f43"},
+		H3: StyleSpec{FG: "#ffd166", Bold: true},
+		InlineCode: StyleSpec{
+			FG: "#111827", BG: "#333234",
+		},
+		CodeBlock: StyleSpec{
+			FG: "#ff9999", BG: "#333234",
+		},
+		Link:       StyleSpec{FG: "#58a6ff", Underline: true},
+		ListMarker: StyleSpec{FG: "#9aa4b2", Bold: true},
+		Blockquote: StyleSpec{FG: "#94a3b8", Italic: true},
+		Math:       StyleSpec{FG: "#d2a8ff"},
+		Callout: CalloutTheme{
+			Note:      CalloutStyle{Title: StyleSpec{FG: "#58a6ff", BG: "#0d2238", Bold: true}, Body: StyleSpec{BG: "#0d2238"}},
+			Tip:       CalloutStyle{Title: StyleSpec{FG: "#3fb950", BG: "#0f2a1a", Bold: true}, Body: StyleSpec{BG: "#0f2a1a"}},
+			Important: CalloutStyle{Title: StyleSpec{FG: "#a371f7", BG: "#221a3a", Bold: true}, Body: StyleSpec{BG: "#221a3a"}},
+			Warning:   CalloutStyle{Title: StyleSpec{FG: "#d29922", BG: "#2d2410", Bold: true}, Body: StyleSpec{BG: "#2d2410"}},
+			Caution:   CalloutStyle{Title: StyleSpec{FG: "#f85149", BG: "#3a1618", Bold: true}, Body: StyleSpec{BG: "#3a1618"}},
+		},
+		Table: struct {
+			Header StyleSpec `toml:"header"`
+			Border string    `toml:"border"`
+		}{
+			Header: StyleSpec{FG: "#e6edf3"},
+			Border: "#3b4252",
+		},
+		HR: StyleSpec{FG: "#3b4252"},
+	},
+}
+
+// дефолтная тема для светлого фона терминала (см. termbg.go)
+var DefaultLight = Theme{
+	UI: UITheme{
+		Background:  "#ffffff",
+		Foreground:  "#24292f",
+		Accent:      "#1a7f37",
+		Cursor:      "#0969da",
+		SelectionBG: "#ddf4ff",
+		LeftPanel: PanelStyle{
+			FG:           "#57606a",
+			BG:           "#f6f8fa",
+			SelectedFG:   "#ffffff",
+			SelectedBG:   "#57606a",
+			SelectedBold: true,
+		},
+		RightPanel: PanelStyle{
+			FG: "#24292f",
+			BG: "#ffffff",
+		},
+		Statusbar: StyleSpec{
+			FG: "#57606a",
+			BG: "#eaeef2",
+		},
+		Scrollbar: ScrollbarStyle{
+			Track: "#eaeef2",
+			Thumb: "#afb8c1",
+		},
+		CursorLine: StyleSpec{
+			BG: "#f6f8fa",
+		},
+		Ruler: StyleSpec{
+			FG: "#d0d7de",
+		},
+		Notify: NotifyTheme{
+			Info:    StyleSpec{FG: "#24292f", BG: "#ddf4ff"},
+			Success: StyleSpec{FG: "#ffffff", BG: "#1a7f37"},
+			Warning: StyleSpec{FG: "#24292f", BG: "#fff8c5"},
+			Error:   StyleSpec{FG: "#ffffff", BG: "#cf222e", Bold: true},
+		},
+		Dialog: DialogTheme{
+			Body:     StyleSpec{FG: "#24292f", BG: "#f6f8fa"},
+			Border:   StyleSpec{FG: "#1a7f37"},
+			Selected: StyleSpec{FG: "#ffffff", BG: "#1a7f37"},
+			Input:    StyleSpec{FG: "#24292f", BG: "#ffffff"},
+		},
+		Spell:   StyleSpec{FG: "#cf222e", Underline: true},
+		Bracket: StyleSpec{BG: "#d0d7de", Bold: true},
+		Conflict: ConflictTheme{
+			Marker: StyleSpec{FG: "#57606a", Bold: true},
+			Ours:   StyleSpec{BG: "#dafbe1"},
+			Theirs: StyleSpec{BG: "#ddf4ff"},
+		},
+	},
+	Markdown: MarkdownTheme{
+		H1:         StyleSpec{FG: "#bf3989", Bold: true},
+		H2:         StyleSpec{FG: "#bc4c00", Bold: true},
+		H3:         StyleSpec{FG: "#9a6700", Bold: true},
+		InlineCode: StyleSpec{FG: "#24292f", BG: "#eaeef2"},
+		CodeBlock:  StyleSpec{FG: "#953800", BG: "#f6f8fa"},
+		Link:       StyleSpec{FG: "#0969da", Underline: true},
+		ListMarker: StyleSpec{FG: "#57606a", Bold: true},
+		Blockquote: StyleSpec{FG: "#57606a", Italic: true},
+		Math:       StyleSpec{FG: "#8250df"},
+		Callout: CalloutTheme{
+			Note:      CalloutStyle{Title: StyleSpec{FG: "#0969da", BG: "#ddf4ff", Bold: true}, Body: StyleSpec{BG: "#ddf4ff"}},
+			Tip:       CalloutStyle{Title: StyleSpec{FG: "#1a7f37", BG: "#dafbe1", Bold: true}, Body: StyleSpec{BG: "#dafbe1"}},
+			Important: CalloutStyle{Title: StyleSpec{FG: "#8250df", BG: "#fbefff", Bold: true}, Body: StyleSpec{BG: "#fbefff"}},
+			Warning:   CalloutStyle{Title: StyleSpec{FG: "#9a6700", BG: "#fff8c5", Bold: true}, Body: StyleSpec{BG: "#fff8c5"}},
+			Caution:   CalloutStyle{Title: StyleSpec{FG: "#cf222e", BG: "#ffebe9", Bold: true}, Body: StyleSpec{BG: "#ffebe9"}},
+		},
+		Table: struct {
+			Header StyleSpec `toml:"header"`
+			Border string    `toml:"border"`
+		}{
+			Header: StyleSpec{FG: "#24292f", Bold: true},
+			Border: "#d0d7de",
+		},
+		HR: StyleSpec{FG: "#d0d7de"},
+	},
+}
+
+// CalloutStyle — заголовок и тело выноски одного типа
+type CalloutStyle struct {
+	Title StyleSpec `toml:"title"`
+	Body  StyleSpec `toml:"body"`
+}
+
+// CalloutTheme — стили выносок по типам GitHub
+type CalloutTheme struct {
+	Note      CalloutStyle `toml:"note"`
+	Tip       CalloutStyle `toml:"tip"`
+	Important CalloutStyle `toml:"important"`
+	Warning   CalloutStyle `toml:"warning"`
+	Caution   CalloutStyle `toml:"caution"`
+}
+
+// Стили по имени типа
+func (c *CalloutTheme) ByKind() map[string]*CalloutStyle {
+	return map[string]*CalloutStyle{
+		"note":      &c.Note,
+		"tip":       &c.Tip,
+		"important": &c.Important,
+		"warning":   &c.Warning,
+		"caution":   &c.Caution,
+	}
+}
@@ -0,0 +1,83 @@
+// Package ui — примитивы рисования поверх tcell.Screen, не зависящие от
+// состояния редактора: строка, графемы, рамка и полоса прокрутки.
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// Вывести строку с колонки x, не выходя за maxX. Возвращает конечную колонку.
+func PutString(s tcell.Screen, x, y, maxX int, text string, style tcell.Style) int {
+	for _, r := range text {
+		w := runewidth.RuneWidth(r)
+		if x+w > maxX {
+			break
+		}
+		s.SetContent(x, y, r, nil, style)
+		x += w
+	}
+	return x
+}
+
+// Нарисовать руны графемами с колонки x, не шире maxW. Возвращает занятую ширину.
+func PutGraphemes(s tcell.Screen, x, y, maxW int, runes []rune, style tcell.Style) int {
+	col := 0
+	for _, g := range editor.Graphemes(runes) {
+		if col+g.Width > maxW {
+			break
+		}
+		s.SetContent(x+col, y, runes[g.Start], runes[g.Start+1:g.Start+g.N], style)
+		col += g.Width
+	}
+	return col
+}
+
+// Рамка с заголовком; внутренняя область заливается стилем fill
+func Box(s tcell.Screen, x, y, w, h int, title string, border, fill tcell.Style) {
+	for row := y; row < y+h; row++ {
+		for col := x; col < x+w; col++ {
+			ch := ' '
+			style := fill
+			switch {
+			case row == y && col == x:
+				ch, style = '┌', border
+			case row == y && col == x+w-1:
+				ch, style = '┐', border
+			case row == y+h-1 && col == x:
+				ch, style = '└', border
+			case row == y+h-1 && col == x+w-1:
+				ch, style = '┘', border
+			case row == y || row == y+h-1:
+				ch, style = '─', border
+			case col == x || col == x+w-1:
+				ch, style = '│', border
+			}
+			s.SetContent(col, row, ch, nil, style)
+		}
+	}
+	col := x + 2
+	for _, r := range runewidth.Truncate(title, w-4, "…") {
+		s.SetContent(col, y, r, nil, border.Bold(true))
+		col += runewidth.RuneWidth(r)
+	}
+}
+
+// Вертикальная полоса прокрутки в колонке x начиная со строки y.
+// total — всего строк, visible — видимых, offset — первая видимая строка.
+func Scrollbar(s tcell.Screen, x, y, height, total, visible, offset int, track, thumb tcell.Style) {
+	if height < 1 || total <= visible {
+		return
+	}
+	thumbSize := max(height*visible/total, 1)
+	thumbPos := min((height-thumbSize)*offset/(total-visible), height-thumbSize)
+	for i := 0; i < height; i++ {
+		if i >= thumbPos && i < thumbPos+thumbSize {
+			s.SetContent(x, y+i, '┃', nil, thumb)
+		} else {
+			s.SetContent(x, y+i, '│', nil, track)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// Экран w×h для проверок
+func newScreen(t *testing.T, w, h int) tcell.SimulationScreen {
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetSize(w, h)
+	return s
+}
+
+// Строка экрана y
+func row(s tcell.SimulationScreen, y int) string {
+	cells, w, _ := s.GetContents()
+	var b strings.Builder
+	for x := 0; x < w; x++ {
+		c := cells[y*w+x]
+		if len(c.Runes) == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteString(string(c.Runes))
+		x += runewidth.StringWidth(string(c.Runes)) - 1 // широкий символ занимает две ячейки
+	}
+	return b.String()
+}
+
+func TestPutString(t *testing.T) {
+	tests := []struct {
+		text, want string
+		end        int
+	}{
+		{"abc", "abc   ", 3},
+		{"abcdefgh", "abcde ", 5},
+		{"界界界", "界界  ", 4},
+	}
+	for _, tt := range tests {
+		s := newScreen(t, 6, 1)
+		if end := PutString(s, 0, 0, 5, tt.text, tcell.StyleDefault); end != tt.end {
+			t.Errorf("PutString(%q) end = %d, want %d", tt.text, end, tt.end)
+		}
+		s.Show()
+		if got := row(s, 0); got != tt.want {
+			t.Errorf("PutString(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPutGraphemes(t *testing.T) {
+	s := newScreen(t, 6, 1)
+	if w := PutGraphemes(s, 1, 0, 3, []rune("é界x"), tcell.StyleDefault); w != 3 {
+		t.Errorf("width = %d, want 3", w)
+	}
+	s.Show()
+	if got := row(s, 0); got != " é界  " {
+		t.Errorf("PutGraphemes = %q", got)
+	}
+}
+
+func TestBox(t *testing.T) {
+	s := newScreen(t, 10, 3)
+	Box(s, 0, 0, 10, 3, "a long title", tcell.StyleDefault, tcell.StyleDefault)
+	s.Show()
+	want := []string{"┌─a lon…─┐", "│        │", "└────────┘"}
+	for y, w := range want {
+		if got := row(s, y); got != w {
+			t.Errorf("row %d = %q, want %q", y, got, w)
+		}
+	}
+}
+
+func TestScrollbar(t *testing.T) {
+	tests := []struct {
+		total, visible, offset int
+		want                   string
+	}{
+		{10, 10, 0, "    "},
+		{8, 4, 0, "┃┃││"},
+		{8, 4, 4, "││┃┃"},
+		{100, 4, 50, "│┃││"},
+	}
+	for _, tt := range tests {
+		s := newScreen(t, 1, 4)
+		Scrollbar(s, 0, 0, 4, tt.total, tt.visible, tt.offset, tcell.StyleDefault, tcell.StyleDefault)
+		s.Show()
+		var got string
+		for y := 0; y < 4; y++ {
+			got += row(s, y)
+		}
+		if got != tt.want {
+			t.Errorf("Scrollbar(%d, %d, %d) = %q, want %q", tt.total, tt.visible, tt.offset, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Таблица привязок клавиш ----
+//
+// Привязки берутся из реестра команд (commands.go): из него строится
+// справка (?), поэтому при изменении привязок справка остаётся актуальной.
+
+// Привязка клавиши
+type keyBinding struct {
+	keys    string // нормализованное имя, как возвращает keyName
+	context string // раздел справки (ключ каталога)
+	desc    string // ключ каталога сообщений
+	alias   bool   // дополнительная клавиша команды (в справке не показывается)
+}
+
+// Разделы справки в порядке показа
+var keyContexts = []string{
+	"help.ctx.navigation",
+	"help.ctx.panels",
+	"help.ctx.editing",
+	"help.ctx.preview",
+	"help.ctx.windows",
+	"help.ctx.other",
+	"help.ctx.plugins",
+}
+
+// Привязки для справки: по одной на каждую клавишу команд реестра
+func (a *App) keymap() []keyBinding {
+	var res []keyBinding
+	for _, c := range a.commands {
+		if c.context == "" {
+			continue
+		}
+		for i, k := range c.keys {
+			res = append(res, keyBinding{k, c.context, c.desc, i > 0})
+		}
+	}
+	return res
+}
+
+// Нормализованное имя клавиши: "Ctrl+S", "Alt+m", "F2", "Ctrl+Left", "?"
+func keyName(ev *tcell.EventKey) string {
+	mods := ev.Modifiers()
+	var name string
+	if ev.Key() == tcell.KeyRune {
+		name = string(ev.Rune())
+		if ev.Rune() == ' ' {
+			name = "Space"
+		}
+		// Shift для символов уже учтён в самом символе
+		mods &^= tcell.ModShift
+	} else {
+		name = tcell.KeyNames[ev.Key()]
+		switch {
+		case strings.HasPrefix(name, "Ctrl-"):
+			name = "Ctrl+" + strings.TrimPrefix(name, "Ctrl-")
+			mods &^= tcell.ModCtrl
+		case ev.Key() == tcell.KeyBackspace2:
+			name = "Backspace"
+		case ev.Key() == tcell.KeyEsc:
+			name = "Esc"
+		}
+	}
+	prefix := ""
+	if mods&tcell.ModCtrl != 0 {
+		prefix += "Ctrl+"
+	}
+	if mods&tcell.ModAlt != 0 {
+		prefix += "Alt+"
+	}
+	if mods&tcell.ModShift != 0 {
+		prefix += "Shift+"
+	}
+	return prefix + name
+}
+
+// Найти привязки для имени клавиши
+func (a *App) lookupKey(name string) []keyBinding {
+	var res []keyBinding
+	for _, b := range a.keymap() {
+		if b.keys == name {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// Строки справки, сгруппированные по разделам
+func (a *App) helpLines() []string {
+	keymap := a.keymap()
+	width := 0
+	for _, b := range keymap {
+		if b.alias {
+			continue
+		}
+		if w := runewidth.StringWidth(b.keys); w > width {
+			width = w
+		}
+	}
+	var lines []string
+	for _, ctx := range keyContexts {
+		var section []string
+		for _, b := range keymap {
+			if b.context == ctx && !b.alias {
+				section = append(section, "  "+runewidth.FillRight(b.keys, width)+"  "+tr(b.desc))
+			}
+		}
+		if len(section) == 0 {
+			continue // например, плагинов нет
+		}
+		lines = append(lines, tr(ctx)+":")
+		lines = append(append(lines, section...), "")
+	}
+	return append(lines, strings.Split(tr("help.notes"), "\n")...)
+}
+
+// ---- Окно справки ----
+//
+// / — поиск по справке, Ctrl+K — какая команда висит на клавише,
+// Esc — закрыть (или сбросить поиск).
+
+type helpOverlay struct {
+	all       []string
+	lines     []string
+	scroll    int
+	searching bool
+	lookup    bool
+	query     inputLine
+	found     string // результат поиска клавиши
+}
+
+// Показ справки
+func (a *App) showHelp() {
+	h := &helpOverlay{all: a.helpLines()}
+	h.lines = h.all
+	a.pushOverlay(h)
+}
+
+// Отфильтровать строки справки по запросу
+func (h *helpOverlay) applyFilter() {
+	q := strings.ToLower(h.query.String())
+	if q == "" {
+		h.lines = h.all
+		return
+	}
+	h.lines = nil
+	for _, l := range h.all {
+		if strings.Contains(strings.ToLower(l), q) {
+			h.lines = append(h.lines, l)
+		}
+	}
+	h.scroll = 0
+}
+
+func (h *helpOverlay) rect(a *App) (x, y, w, hh int) {
+	return a.centeredRect(80, a.height-2)
+}
+
+func (h *helpOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, hh := h.rect(a)
+	ui.Box(a.screen, x, y, w, hh, " "+tr("ui.help")+" ", st.border, st.body)
+
+	visible := hh - 3
+	h.clamp(visible)
+	for i := 0; i < visible && h.scroll+i < len(h.lines); i++ {
+		line := h.lines[h.scroll+i]
+		style := st.body
+		if strings.HasSuffix(line, ":") && !strings.HasPrefix(line, " ") {
+			style = st.border.Bold(true)
+		}
+		ui.PutString(a.screen, x+2, y+1+i, x+w-3, line, style)
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, len(h.lines), visible, h.scroll)
+
+	// нижняя строка: поиск, поиск клавиши или подсказка
+	by := y + hh - 2
+	switch {
+	case h.searching:
+		col := ui.PutString(a.screen, x+2, by, x+w-2, "/", st.dim)
+		a.drawInputLine(&h.query, col, by, x+w-2-col, st.input)
+	case h.lookup:
+		ui.PutString(a.screen, x+2, by, x+w-2, tr("help.press_key"), st.dim)
+	case h.found != "":
+		ui.PutString(a.screen, x+2, by, x+w-2, h.found, st.selected)
+	default:
+		ui.PutString(a.screen, x+2, by, x+w-2, tr("help.hint"), st.dim)
+	}
+}
+
+func (h *helpOverlay) clamp(visible int) {
+	if h.scroll > len(h.lines)-visible {
+		h.scroll = len(h.lines) - visible
+	}
+	if h.scroll < 0 {
+		h.scroll = 0
+	}
+}
+
+func (h *helpOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	// режим поиска клавиши: следующее нажатие описывается, а не выполняется
+	if h.lookup {
+		h.lookup = false
+		name := keyName(ev)
+		bs := a.lookupKey(name)
+		if len(bs) == 0 {
+			h.found = trf("help.unbound", name)
+		} else {
+			descs := make([]string, len(bs))
+			for i, b := range bs {
+				descs[i] = tr(b.desc)
+			}
+			h.found = name + " — " + strings.Join(descs, "; ")
+		}
+		return false
+	}
+
+	if h.searching {
+		switch ev.Key() {
+		case tcell.KeyEscape:
+			h.searching = false
+			h.query.set("")
+			h.applyFilter()
+		case tcell.KeyEnter:
+			h.searching = false
+		default:
+			if h.query.handleKey(ev) {
+				h.applyFilter()
+			}
+		}
+		return false
+	}
+
+	_, _, _, hh := h.rect(a)
+	page := hh - 3
+	h.found = ""
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		// сначала сбрасываем фильтр, потом закрываем
+		if h.query.String() != "" {
+			h.query.set("")
+			h.applyFilter()
+			return false
+		}
+		return true
+	case tcell.KeyCtrlK:
+		h.lookup = true
+	case tcell.KeyUp:
+		h.scroll--
+	case tcell.KeyDown:
+		h.scroll++
+	case tcell.KeyPgUp:
+		h.scroll -= page
+	case tcell.KeyPgDn:
+		h.scroll += page
+	case tcell.KeyHome:
+		h.scroll = 0
+	case tcell.KeyEnd:
+		h.scroll = len(h.lines)
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case '/':
+			h.searching = true
+		case 'q', '?':
+			return true
+		}
+	}
+	h.clamp(page)
+	return false
+}
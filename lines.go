@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Операции над строками (Alt+S) ----
+//
+// Сортировка по возрастанию и убыванию, удаление повторов и обратный
+// порядок для строк выделения, а без выделения — для абзаца под
+// курсором (строки до ближайших пустых): удобно для списков Markdown.
+// После операции строки остаются выделенными, так что их можно сразу
+// обработать ещё раз; Alt+. повторяет операцию на новом месте.
+
+// Операции в порядке меню
+var lineOps = []struct {
+	name, desc string
+	apply      func([]string) []string
+}{
+	{"lines.sortAsc", "lines.sort_asc", func(ls []string) []string { return editor.SortLines(ls, false) }},
+	{"lines.sortDesc", "lines.sort_desc", func(ls []string) []string { return editor.SortLines(ls, true) }},
+	{"lines.unique", "lines.unique", editor.UniqueLines},
+	{"lines.reverse", "lines.reverse", editor.ReverseLines},
+}
+
+// Строки, над которыми работает операция: выделение (строка, где
+// выделение кончается в начале, не входит) или абзац под курсором
+func (a *App) lineRange() (int, int) {
+	lines := a.getLines()
+	if r, ok := a.view.selection(); ok {
+		end := r.ey
+		if r.ex == 0 && end > r.sy {
+			end--
+		}
+		return r.sy, end
+	}
+	start, end := a.view.editY, a.view.editY
+	if strings.TrimSpace(lines[start]) == "" {
+		return start, end
+	}
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	for end < len(lines)-1 && strings.TrimSpace(lines[end+1]) != "" {
+		end++
+	}
+	return start, end
+}
+
+// Применить операцию и выделить получившиеся строки
+func (a *App) applyLineOp(apply func([]string) []string) {
+	lines := a.getLines()
+	start, end := a.lineRange()
+	res := apply(lines[start : end+1])
+	newLines := append([]string{}, lines[:start]...)
+	newLines = append(newLines, res...)
+	newLines = append(newLines, lines[end+1:]...)
+	a.setLines(newLines)
+
+	last := start + len(res) - 1
+	a.view.selecting = true
+	a.view.selY, a.view.selX = start, 0
+	a.view.editY, a.view.editX = last, len([]rune(newLines[last]))
+	a.ensureCursorVisible()
+}
+
+// Выполнить операцию по имени (для реестра команд)
+func (a *App) runLineOp(name string) {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	for _, op := range lineOps {
+		if op.name == name {
+			a.repeatable(func() { a.applyLineOp(op.apply) })
+			return
+		}
+	}
+}
+
+// Alt+S: меню операций над строками
+func (a *App) linesMenu() {
+	if a.activePanel != "right" || a.view.mode != "edit" {
+		return
+	}
+	start, end := a.lineRange()
+	items := make([]listItem, len(lineOps))
+	for i, op := range lineOps {
+		items[i] = listItem{label: tr(op.desc), value: op.name}
+	}
+	a.pick(trf("lines.title", end-start+1), items, func(item listItem) {
+		a.runLineOp(item.value)
+	})
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLineOps(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		cursor  pos
+		sel     *pos // начало выделения (nil — без выделения)
+		op      string
+		want    string
+		selFrom int // выделенные после операции строки (-1 — выделения нет)
+		selTo   int
+	}{
+		{"paragraph", "# List\n\nc\na\nb\n\nz", pos{3, 0}, nil, "lines.sortAsc", "# List\n\na\nb\nc\n\nz", 2, 4},
+		{"selection", "c\nb\na\nd", pos{2, 1}, &pos{0, 0}, "lines.sortAsc", "a\nb\nc\nd", 0, 2},
+		{"selection ends at line start", "c\nb\na", pos{2, 0}, &pos{0, 0}, "lines.reverse", "b\nc\na", 0, 1},
+		{"blank line", "b\n\na", pos{1, 0}, nil, "lines.reverse", "b\n\na", -1, -1},
+		{"unique shrinks", "x\nx\ny", pos{0, 0}, nil, "lines.unique", "x\ny", 0, 1},
+	}
+	for _, tt := range tests {
+		a := newTestApp(t, nil)
+		a.view.buf.Content = tt.text
+		a.activePanel, a.view.mode = "right", "edit"
+		if tt.sel != nil {
+			a.view.selecting = true
+			a.view.selY, a.view.selX = tt.sel.y, tt.sel.x
+		}
+		a.view.editY, a.view.editX = tt.cursor.y, tt.cursor.x
+		a.runLineOp(tt.op)
+		if got := a.view.buf.Content; got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+		r, ok := a.view.selection()
+		if tt.selFrom < 0 {
+			if ok {
+				t.Errorf("%s: unexpected selection %+v", tt.name, r)
+			}
+			continue
+		}
+		if !ok || r.sy != tt.selFrom || r.ey != tt.selTo {
+			t.Errorf("%s: selection %+v %v, want lines %d-%d", tt.name, r, ok, tt.selFrom, tt.selTo)
+		}
+	}
+}
+
+// Позиция курсора
+type pos struct{ y, x int }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ---- Проверка относительных ссылок ----
+//
+// Alt+l — ссылки текущего документа, Alt+L — все Markdown-файлы в текущей
+// папке (рекурсивно). Ищутся [текст](путь) и ![alt](путь), указывающие на
+// несуществующие файлы; внешние адреса и якоря (#…) не проверяются.
+// Выбор ссылки в списке открывает файл на нужной строке.
+
+var mdLinkRe = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// Битая ссылка
+type brokenLink struct {
+	file   string
+	line   int // с 1
+	col    int // руна, с 0
+	target string
+}
+
+// Найти битые ссылки в тексте файла path
+func findBrokenLinks(path, content string) []brokenLink {
+	var res []brokenLink
+	dir := filepath.Dir(path)
+	lines := strings.Split(content, "\n")
+	code := codeBlockLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		for _, m := range mdLinkRe.FindAllStringSubmatchIndex(line, -1) {
+			target := line[m[2]:m[3]]
+			p, ok := linkFilePath(dir, target)
+			if !ok {
+				continue
+			}
+			if _, err := os.Stat(p); err != nil {
+				res = append(res, brokenLink{
+					file:   path,
+					line:   i + 1,
+					col:    len([]rune(line[:m[0]])),
+					target: target,
+				})
+			}
+		}
+	}
+	return res
+}
+
+// Путь к файлу, на который указывает ссылка; false — проверять не нужно
+func linkFilePath(dir, target string) (string, bool) {
+	if strings.HasPrefix(target, "#") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") {
+		return "", false
+	}
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if t, err := url.PathUnescape(target); err == nil {
+		target = t
+	}
+	if target == "" {
+		return "", false
+	}
+	if filepath.IsAbs(target) {
+		return target, true
+	}
+	return filepath.Join(dir, filepath.FromSlash(target)), true
+}
+
+// Проверить текущий документ
+func (a *App) checkLinks() {
+	if a.view.buf.path == "" {
+		a.notify(levelWarning, "%s", tr("links.no_file"))
+		return
+	}
+	a.showBrokenLinks(findBrokenLinks(a.view.buf.path, a.view.buf.Content))
+}
+
+// Проверить все Markdown-файлы в текущей папке
+func (a *App) checkLinksInDir() {
+	var links []brokenLink
+	for _, path := range markdownFiles(a.currentDir) {
+		if content, ok := a.noteContent(path); ok {
+			links = append(links, findBrokenLinks(path, content)...)
+		}
+	}
+	a.showBrokenLinks(links)
+}
+
+// Список битых ссылок с переходом к выбранной
+func (a *App) showBrokenLinks(links []brokenLink) {
+	if len(links) == 0 {
+		a.notify(levelSuccess, "%s", tr("links.none"))
+		return
+	}
+	items := make([]listItem, len(links))
+	for i, l := range links {
+		name := l.file
+		if rel, err := filepath.Rel(a.currentDir, l.file); err == nil {
+			name = rel
+		}
+		items[i] = listItem{
+			label:  l.target,
+			detail: fmt.Sprintf("%s:%d", name, l.line),
+			value:  fmt.Sprint(i),
+		}
+	}
+	a.pick(trf("links.title", len(links)), items, func(item listItem) {
+		var i int
+		fmt.Sscan(item.value, &i)
+		l := links[i]
+		if l.file != a.view.buf.path {
+			a.openFile(l.file)
+		}
+		a.view.mode = "edit"
+		a.gotoLine(l.line)
+		a.view.editX = l.col
+		a.clampCursor()
+		a.ensureCursorVisible()
+	})
+}
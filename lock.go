@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ---- Блокировка файлов от одновременной правки ----
+//
+// Открывая файл, eddy записывает метку <хэш пути>.lock (pid, хост,
+// время) в ~/.local/state/eddy/locks и удаляет её, когда файл больше не
+// открыт ни в одном окне или при выходе. Если метку держит другой запущенный экземпляр,
+// файл открывается только для чтения с вопросом, забрать ли блокировку.
+// Экземпляр, у которого блокировку забрали, спросит подтверждение перед
+// сохранением. Метки завершившихся процессов на этом же хосте не мешают.
+
+// Метка блокировки
+type fileLock struct {
+	Path  string    `json:"path"`
+	PID   int       `json:"pid"`
+	Host  string    `json:"host"`
+	Since time.Time `json:"since"`
+}
+
+// Файл метки для документа
+func lockPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return filepath.Join(stateDir(), "locks", contentHash(abs)[:32]+".lock")
+}
+
+// Блокируются только обычные локальные файлы
+func lockable(p string) bool {
+	return p != "" && !isRemote(p) && !inArchive(p) && !isWebURL(p)
+}
+
+// Действующая блокировка другого экземпляра или nil
+func foreignLock(path string) *fileLock {
+	data, err := os.ReadFile(lockPath(path))
+	if err != nil {
+		return nil
+	}
+	var l fileLock
+	if json.Unmarshal(data, &l) != nil {
+		return nil
+	}
+	host, _ := os.Hostname()
+	if l.Host == host && (l.PID == os.Getpid() || !processAlive(l.PID)) {
+		return nil // своя или осталась от завершившегося процесса
+	}
+	return &l
+}
+
+// Записать свою метку
+func (a *App) takeLock(buf *buffer) {
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(fileLock{Path: buf.path, PID: os.Getpid(), Host: host, Since: time.Now()})
+	p := lockPath(buf.path)
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err == nil {
+		err = os.WriteFile(p, data, 0644)
+	}
+	if err != nil {
+		a.debugf("lock %s: %v", buf.path, err)
+		return
+	}
+	buf.locked = true
+}
+
+// Заблокировать только что открытый файл или предупредить о чужой блокировке
+func (a *App) lockBuffer(buf *buffer) {
+	if buf.readOnly || buf.locked || !lockable(buf.path) {
+		return
+	}
+	l := foreignLock(buf.path)
+	if l == nil {
+		a.takeLock(buf)
+		return
+	}
+	buf.readOnly = true
+	a.confirm(trf("lock.busy", filepath.Base(buf.path), l.Host, l.PID, l.Since.Format("02.01 15:04")), func() {
+		buf.readOnly = false
+		a.takeLock(buf)
+	})
+}
+
+// Убрать свою метку (если её не забрал другой экземпляр)
+func (a *App) unlock(buf *buffer) {
+	if !buf.locked {
+		return
+	}
+	buf.locked = false
+	if foreignLock(buf.path) == nil {
+		os.Remove(lockPath(buf.path))
+	}
+}
+
+// Снять блокировки буферов, которые больше не показаны ни в одном окне
+func (a *App) releaseUnused(bufs ...*buffer) {
+	for _, buf := range bufs {
+		shown := false
+		for _, v := range a.views {
+			shown = shown || v.buf == buf
+		}
+		if !shown {
+			a.unlock(buf)
+		}
+	}
+}
+
+// При выходе: снять все свои блокировки
+func (a *App) releaseLocks() {
+	for _, v := range a.views {
+		a.unlock(v.buf)
+	}
+}
+
+// Блокировку забрал другой экземпляр: перед сохранением спросить
+// подтверждение. Возвращает true, если сохранение отложено до ответа.
+func (a *App) confirmStolenLock(buf *buffer, save func()) bool {
+	if !buf.locked {
+		return false
+	}
+	l := foreignLock(buf.path)
+	if l == nil {
+		return false
+	}
+	a.confirm(trf("lock.taken", filepath.Base(buf.path), l.Host, l.PID), func() {
+		a.takeLock(buf)
+		save()
+	})
+	return true
+}
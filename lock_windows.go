@@ -0,0 +1,16 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// Жив ли процесс с этим pid: в Windows FindProcess открывает процесс
+// и не находит завершившийся
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Имя программы: используется для каталогов состояния
+const appName = "eddy"
+
+// ---- Отладочный лог в файл ----
+//
+// Включается флагом --debug. Пишет в $XDG_STATE_HOME/eddy/log
+// (по умолчанию ~/.local/state/eddy/log) и никогда — в stdout,
+// т.к. экраном владеет tcell.
+
+var (
+	logger   *log.Logger // nil — логирование выключено
+	logMu    sync.Mutex
+	logLevel = levelDebug
+)
+
+// Каталог состояния приложения: $XDG_STATE_HOME/eddy или ~/.local/state/eddy
+func stateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(home, ".local", "state", appName)
+}
+
+// Путь к файлу лога
+func logPath() string {
+	return filepath.Join(stateDir(), "log")
+}
+
+// Открыть файл лога (дописывание). Возвращает файл для закрытия при выходе.
+func initLogger() (*os.File, error) {
+	path := logPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create log dir: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open log: %v", err)
+	}
+
+	logMu.Lock()
+	logger = log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	logMu.Unlock()
+
+	logf(levelInfo, "started, pid %d", os.Getpid())
+	return f, nil
+}
+
+// Записать сообщение в лог, если он включён и уровень достаточен
+func logf(level msgLevel, format string, args ...interface{}) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	if logger == nil || level < logLevel {
+		return
+	}
+	logger.Printf("%s %s", level.tag(), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ---- Плагины на Lua ----
+//
+// Кроме *.toml (plugins.go), в ~/.config/eddy/plugins/ можно положить
+// сценарий *.lua. Его выполняет встроенный интерпретатор Lua 5.1
+// (github.com/yuin/gopher-lua) при запуске редактора; через таблицу
+// eddy сценарий регистрирует команды, клавиши и обработчики событий, а
+// команды читают и правят буфер активного окна:
+//
+//	eddy.command{name = "x.y", title = "…", key = "Alt+U", run = function() … end}
+//	eddy.bind(key, command)         eddy.run(command) → true, если команда есть
+//	eddy.on(event, function(file) … end)   -- open, save, theme-reload
+//	eddy.notify(text)
+//	eddy.file(), eddy.dir()         -- файл активного окна и папка панели
+//	eddy.text(), eddy.set_text(s)
+//	eddy.line_count(), eddy.line(n), eddy.set_line(n, s)
+//	eddy.cursor() → line, col       eddy.set_cursor(line, col)   -- с 1, в символах
+//	eddy.selection(), eddy.insert(s) -- вставка заменяет выделение
+//
+// Пример:
+//
+//	eddy.command{name = "line.upper", title = "Uppercase line", key = "Alt+U", run = function()
+//	  local n = eddy.cursor()
+//	  eddy.set_line(n, eddy.line(n):upper())
+//	end}
+//	eddy.on("save", function(file) eddy.notify("saved " .. file) end)
+//
+// У каждого файла свой интерпретатор. Всё выполняется в главном цикле:
+// правка команды видна сразу и отменяется одним шагом. Один вызов
+// длится не дольше scriptTimeout, зациклившийся сценарий прерывается с
+// ошибкой. Ошибка при загрузке — сообщение plugin.failed, при вызове
+// команды или обработчика — как у плагинов *.toml.
+
+const scriptTimeout = time.Second
+
+// Сценарий плагина
+type luaPlugin struct {
+	name  string
+	L     *lua.LState
+	depth int // вложенность вызовов (команда сценария может вызвать другую)
+}
+
+// Загрузить сценарий и выполнить его
+func (a *App) loadLuaPlugin(path string) error {
+	p := &luaPlugin{name: strings.TrimSuffix(filepath.Base(path), ".lua"), L: lua.NewState()}
+	p.L.SetGlobal("eddy", a.luaAPI(p))
+	fn, err := p.L.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	return p.call(fn)
+}
+
+// Вызвать функцию сценария с ограничением по времени
+func (p *luaPlugin) call(fn *lua.LFunction, args ...lua.LValue) error {
+	if p.depth == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), scriptTimeout)
+		defer cancel()
+		p.L.SetContext(ctx)
+		defer p.L.RemoveContext()
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	err := p.L.CallByParam(lua.P{Fn: fn, Protect: true}, args...)
+	var apiErr *lua.ApiError
+	if errors.As(err, &apiErr) {
+		return errors.New(apiErr.Object.String()) // без трассировки стека
+	}
+	return err
+}
+
+// Выполнить команду сценария
+func (a *App) runLuaCommand(p *luaPlugin, name string, fn *lua.LFunction) {
+	if err := p.call(fn); err != nil {
+		a.notify(levelError, tr("plugin.command_failed"), name, err)
+	}
+}
+
+// Таблица eddy для сценария
+func (a *App) luaAPI(p *luaPlugin) *lua.LTable {
+	api := p.L.NewTable()
+	p.L.SetFuncs(api, map[string]lua.LGFunction{
+		"command": func(L *lua.LState) int {
+			spec := L.CheckTable(1)
+			name := lua.LVAsString(spec.RawGetString("name"))
+			fn, ok := spec.RawGetString("run").(*lua.LFunction)
+			if name == "" || !ok {
+				L.ArgError(1, "name and run are required")
+			}
+			c := &command{name: name, context: "help.ctx.plugins", desc: lua.LVAsString(spec.RawGetString("title")), run: func(a *App) { a.runLuaCommand(p, name, fn) }}
+			if c.desc == "" {
+				c.desc = name
+			}
+			if key := lua.LVAsString(spec.RawGetString("key")); key != "" {
+				c.keys = []string{key}
+			}
+			a.registerCommand(c)
+			return 0
+		},
+		"bind": func(L *lua.LState) int {
+			if err := a.bindKey(L.CheckString(1), L.CheckString(2)); err != nil {
+				L.RaiseError("%v", err)
+			}
+			return 0
+		},
+		"run": func(L *lua.LState) int {
+			L.Push(lua.LBool(a.runCommand(L.CheckString(1))))
+			return 1
+		},
+		"on": func(L *lua.LState) int {
+			event, fn := L.CheckString(1), L.CheckFunction(2)
+			if !oneOf(event, eventOpen, eventSave, eventThemeReload) {
+				L.ArgError(1, "unknown event "+event)
+			}
+			if a.hooks == nil {
+				a.hooks = map[string][]hook{}
+			}
+			a.hooks[event] = append(a.hooks[event], hook{source: p.name, fn: func(file string) error {
+				return p.call(fn, lua.LString(file))
+			}})
+			return 0
+		},
+		"notify": func(L *lua.LState) int {
+			a.notify(levelInfo, "%s", L.CheckString(1))
+			return 0
+		},
+		"file": func(L *lua.LState) int {
+			L.Push(lua.LString(a.view.buf.path))
+			return 1
+		},
+		"dir": func(L *lua.LState) int {
+			L.Push(lua.LString(a.currentDir))
+			return 1
+		},
+		"text": func(L *lua.LState) int {
+			L.Push(lua.LString(a.view.buf.Content))
+			return 1
+		},
+		"set_text": func(L *lua.LState) int {
+			text := L.CheckString(1)
+			a.luaWritable(L)
+			a.view.clearSelection()
+			a.setLines(strings.Split(text, "\n"))
+			a.clampCursor()
+			a.ensureCursorVisible()
+			return 0
+		},
+		"line_count": func(L *lua.LState) int {
+			L.Push(lua.LNumber(len(a.view.buf.CachedLines())))
+			return 1
+		},
+		"line": func(L *lua.LState) int {
+			lines := a.view.buf.CachedLines()
+			n := L.CheckInt(1)
+			if n < 1 || n > len(lines) {
+				L.Push(lua.LNil)
+				return 1
+			}
+			L.Push(lua.LString(lines[n-1]))
+			return 1
+		},
+		"set_line": func(L *lua.LState) int {
+			n, text := L.CheckInt(1), L.CheckString(2)
+			lines := a.view.buf.Lines()
+			if n < 1 || n > len(lines) {
+				L.ArgError(1, "no such line")
+			}
+			a.luaWritable(L)
+			if lines[n-1] != text {
+				lines[n-1] = text
+				a.setLines(lines)
+				a.clampCursor()
+			}
+			return 0
+		},
+		"cursor": func(L *lua.LState) int {
+			L.Push(lua.LNumber(a.view.editY + 1))
+			L.Push(lua.LNumber(a.view.editX + 1))
+			return 2
+		},
+		"set_cursor": func(L *lua.LState) int {
+			a.view.clearSelection()
+			a.view.editY, a.view.editX = L.CheckInt(1)-1, L.OptInt(2, 1)-1
+			a.clampCursor()
+			a.ensureCursorVisible()
+			return 0
+		},
+		"selection": func(L *lua.LState) int {
+			L.Push(lua.LString(a.view.selectedText()))
+			return 1
+		},
+		"insert": func(L *lua.LState) int {
+			text := L.CheckString(1)
+			a.luaWritable(L)
+			a.deleteSelection()
+			a.insertText(text)
+			return 0
+		},
+	})
+	return api
+}
+
+// Прервать сценарий, если буфер только для чтения
+func (a *App) luaWritable(L *lua.LState) {
+	if a.view.buf.readOnly {
+		L.RaiseError("%s", tr("readonly.blocked"))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLuaCommands(t *testing.T) {
+	a := newTestApp(t, map[string]string{"upper.lua": `
+eddy.command{name = "line.upper", title = "Uppercase line", key = "Alt+U", run = function()
+  local n = eddy.cursor()
+  eddy.set_line(n, eddy.line(n):upper())
+end}
+eddy.command{name = "stamp", run = function()
+  eddy.insert("[" .. eddy.line_count() .. "]")
+  eddy.set_cursor(1, 1)
+end}
+eddy.bind("Alt+S", "stamp")
+`})
+	a.view.buf.Content = "one\ntwo"
+	a.view.editY, a.view.editX = 1, 3
+
+	if !a.runCommand("line.upper") {
+		t.Fatal("line.upper is not registered")
+	}
+	if got := a.view.buf.Content; got != "one\nTWO" {
+		t.Errorf("after line.upper: %q", got)
+	}
+	if c := a.command("line.upper"); c.desc != "Uppercase line" || len(c.keys) != 1 || c.keys[0] != "Alt+U" {
+		t.Errorf("line.upper = %+v", c)
+	}
+
+	a.runCommand("stamp")
+	if got := a.view.buf.Content; got != "one\nTWO[2]" {
+		t.Errorf("after stamp: %q", got)
+	}
+	if a.view.editY != 0 || a.view.editX != 0 {
+		t.Errorf("cursor after stamp: %d,%d", a.view.editY, a.view.editX)
+	}
+	if bs := a.bindings["Alt+S"]; len(bs) == 0 || bs[len(bs)-1].name != "stamp" {
+		t.Error("Alt+S is not bound to stamp")
+	}
+}
+
+func TestLuaHooks(t *testing.T) {
+	a := newTestApp(t, map[string]string{"hooks.lua": `
+eddy.on("save", function(file) eddy.notify("saved " .. file) end)
+`})
+	a.view.buf.path = "/x/a.md"
+	a.fireHooks(eventSave)
+	if got := lastMessage(a); got != "saved /x/a.md" {
+		t.Errorf("message after save hook = %q", got)
+	}
+}
+
+func TestLuaErrors(t *testing.T) {
+	a := newTestApp(t, map[string]string{
+		"bad.lua": `eddy.on("close", function() end)`,
+		"loop.lua": `
+eddy.command{name = "loop", run = function() while true do end end}
+eddy.command{name = "edit", run = function() eddy.set_text("x") end}
+`,
+	})
+	if len(a.plugins) != 1 || !strings.HasSuffix(a.plugins[0], "loop.lua") {
+		t.Errorf("loaded plugins = %q", a.plugins)
+	}
+
+	a.runCommand("loop")
+	if got := lastMessage(a); !strings.Contains(got, "loop") {
+		t.Errorf("message after runaway command = %q", got)
+	}
+
+	a.view.buf.Content = "keep"
+	a.view.buf.readOnly = true
+	a.runCommand("edit")
+	if a.view.buf.Content != "keep" {
+		t.Error("script edited a read-only buffer")
+	}
+}
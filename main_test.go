@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Редактор на виртуальном экране с отдельной папкой настроек;
+// plugins — файлы в её plugins/ (имя — текст)
+func newTestApp(t *testing.T, plugins map[string]string) *App {
+	dir := t.TempDir()
+	old := configDirFlag
+	configDirFlag = dir
+	t.Cleanup(func() { configDirFlag = old })
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	if err := os.Mkdir(filepath.Join(dir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, text := range plugins {
+		if err := os.WriteFile(filepath.Join(dir, "plugins", name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	return newApp(screen, false, true)
+}
+
+// Последнее уведомление
+func lastMessage(a *App) string {
+	h := a.messageHistory()
+	if len(h) == 0 {
+		return ""
+	}
+	return h[len(h)-1].text
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Слишком маленький терминал ----
+//
+// Меньше minScreenWidth×minScreenHeight раскладка не помещается (ширины
+// окон уходят в минус), поэтому вместо неё во весь экран выводится
+// сообщение с нужным размером. Клавиши, кроме Ctrl+Q, и мышь до
+// увеличения окна игнорируются: действовать вслепую опасно.
+
+const (
+	minScreenWidth  = 40
+	minScreenHeight = 10
+)
+
+// Меньше ли экран минимального
+func (a *App) tooSmall() bool {
+	w, h := a.screen.Size()
+	return w < minScreenWidth || h < minScreenHeight
+}
+
+// Сообщение о размере по центру экрана
+func (a *App) drawTooSmall() {
+	w, h := a.screen.Size()
+	style := a.getStyles().Text
+	lines := []string{
+		tr("ui.too_small"),
+		trf("ui.too_small_need", minScreenWidth, minScreenHeight, w, h),
+	}
+	y := max((h-len(lines))/2, 0)
+	for i, line := range lines {
+		runes := []rune(line)
+		x := max((w-runewidth.StringWidth(line))/2, 0)
+		ui.PutGraphemes(a.screen, x, y+i, w-x, runes, style)
+	}
+}
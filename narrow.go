@@ -0,0 +1,46 @@
+package main
+
+// ---- Узкий терминал ----
+//
+// Уже narrowScreenWidth колонок две панели рядом превращаются в полоски,
+// поэтому показывается одна: список файлов, пока активна левая панель,
+// иначе редактор. Ширина обеих — во весь экран. Ctrl+B (и Ctrl+←/→)
+// переключает панели; открытый файл сразу показывается в редакторе.
+// leftWidth при этом не меняется и восстанавливается на широком экране.
+
+const narrowScreenWidth = 70
+
+// Узкий ли терминал
+func (a *App) narrow() bool {
+	w, _ := a.screen.Size()
+	return w < narrowScreenWidth
+}
+
+// Ширина, в которой рисуется список файлов (0 — не виден)
+func (a *App) listWidth() int {
+	switch {
+	case a.leftWidth == 0:
+		return 0
+	case !a.narrow():
+		return a.leftWidth
+	case a.activePanel == "left":
+		w, _ := a.screen.Size()
+		return w
+	}
+	return 0
+}
+
+// Виден ли редактор (в узком терминале его закрывает список файлов)
+func (a *App) editorShown() bool {
+	w, _ := a.screen.Size()
+	return a.listWidth() < w
+}
+
+// Ctrl+B в узком терминале: список файлов <-> редактор
+func (a *App) toggleNarrowPanel() {
+	if a.listWidth() > 0 {
+		a.setActivePanel("right")
+	} else {
+		a.setActivePanel("left")
+	}
+}
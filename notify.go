@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Уведомления (toast) ----
+//
+// Короткие сообщения об ошибках и результатах операций. Показываются
+// над статусной строкой и исчезают сами по истечении времени.
+
+// Уровень важности уведомления
+type msgLevel int
+
+const (
+	levelDebug msgLevel = iota // только в истории, без всплывающего сообщения
+	levelInfo
+	levelSuccess
+	levelWarning
+	levelError
+)
+
+// Короткая метка уровня для истории сообщений
+func (l msgLevel) tag() string {
+	switch l {
+	case levelDebug:
+		return "DBG"
+	case levelSuccess:
+		return "OK "
+	case levelWarning:
+		return "WRN"
+	case levelError:
+		return "ERR"
+	}
+	return "INF"
+}
+
+// Сколько сообщений хранится в истории
+const messageHistorySize = 500
+
+// Время показа уведомления в зависимости от уровня
+func (l msgLevel) duration() time.Duration {
+	switch l {
+	case levelWarning:
+		return 5 * time.Second
+	case levelError:
+		return 8 * time.Second
+	}
+	return 3 * time.Second
+}
+
+// Уведомление (текущее или из истории)
+type notification struct {
+	level   msgLevel
+	text    string
+	created time.Time
+	expires time.Time
+}
+
+// Добавить сообщение в историю (кольцевой буфер)
+func (a *App) recordMessage(n *notification) {
+	logf(n.level, "%s", n.text)
+
+	a.msgMu.Lock()
+	defer a.msgMu.Unlock()
+	a.history = append(a.history, n)
+	if len(a.history) > messageHistorySize {
+		a.history = a.history[len(a.history)-messageHistorySize:]
+	}
+}
+
+// Копия истории сообщений
+func (a *App) messageHistory() []*notification {
+	a.msgMu.Lock()
+	defer a.msgMu.Unlock()
+	return append([]*notification(nil), a.history...)
+}
+
+// Записать отладочное сообщение (только в историю)
+func (a *App) debugf(format string, args ...interface{}) {
+	a.recordMessage(&notification{
+		level:   levelDebug,
+		text:    fmt.Sprintf(format, args...),
+		created: time.Now(),
+	})
+}
+
+// Показать уведомление. Безопасно вызывать из любой горутины.
+func (a *App) notify(level msgLevel, format string, args ...interface{}) {
+	now := time.Now()
+	n := &notification{
+		level:   level,
+		text:    fmt.Sprintf(format, args...),
+		created: now,
+		expires: now.Add(level.duration()),
+	}
+	a.recordMessage(n)
+
+	a.msgMu.Lock()
+	a.message = n
+	a.msgMu.Unlock()
+
+	if a.screen == nil {
+		return
+	}
+	// перерисовать сразу и ещё раз, когда уведомление истечёт
+	_ = a.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	a.after(level.duration())
+}
+
+// Текущее (не истёкшее) уведомление или nil
+func (a *App) currentMessage() *notification {
+	a.msgMu.Lock()
+	defer a.msgMu.Unlock()
+	if a.message != nil && time.Now().After(a.message.expires) {
+		a.message = nil
+	}
+	return a.message
+}
+
+// Отрисовка уведомления в строке над статусной
+func (a *App) drawNotification() {
+	n := a.currentMessage()
+	if n == nil {
+		return
+	}
+	y := a.height - 2
+	style := a.getStyles().notify(n.level)
+
+	text := " " + runewidth.Truncate(n.text, a.width-2, "…") + " "
+	col := 0
+	for _, r := range text {
+		a.screen.SetContent(col, y, r, nil, style)
+		col += runewidth.RuneWidth(r)
+	}
+	// добиваем строку фоном уведомления
+	for ; col < a.width; col++ {
+		a.screen.SetContent(col, y, ' ', nil, style)
+	}
+}
+
+// ---- Просмотр истории сообщений (аналог :messages в Vim) ----
+
+type messagesOverlay struct {
+	scroll int
+}
+
+// Открыть историю сообщений (прокрутка к последним)
+func (a *App) openMessages() {
+	a.pushOverlay(&messagesOverlay{scroll: len(a.messageHistory())})
+}
+
+func (m *messagesOverlay) rect(a *App) (x, y, w, h int) {
+	return a.centeredRect(a.width-4, a.height-2)
+}
+
+// Отрисовка окна истории сообщений поверх интерфейса
+func (m *messagesOverlay) draw(a *App) {
+	styles := a.getStyles()
+	st := styles.Dialog
+	x, y, w, h := m.rect(a)
+	if w < 10 || h < 3 {
+		return
+	}
+	ui.Box(a.screen, x, y, w, h, " "+tr("ui.messages")+" ", st.border, st.body)
+
+	history := a.messageHistory()
+	visible := h - 2
+	m.clamp(len(history), visible)
+
+	for i := 0; i < visible && m.scroll+i < len(history); i++ {
+		n := history[m.scroll+i]
+		tagStyle := st.body.Foreground(bgOf(styles.notify(n.level)))
+		if n.level == levelDebug || n.level == levelInfo {
+			tagStyle = st.dim
+		}
+		col := ui.PutString(a.screen, x+2, y+1+i, x+w-3, n.created.Format("15:04:05")+" ", st.body)
+		col = ui.PutString(a.screen, col, y+1+i, x+w-3, n.level.tag(), tagStyle.Bold(true))
+		ui.PutString(a.screen, col+1, y+1+i, x+w-3, n.text, st.body)
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, len(history), visible, m.scroll)
+}
+
+// Ограничить прокрутку истории
+func (m *messagesOverlay) clamp(total, visible int) {
+	if m.scroll > total-visible {
+		m.scroll = total - visible
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+}
+
+// Клавиши в окне истории сообщений
+func (m *messagesOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	_, _, _, h := m.rect(a)
+	page := h - 2
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyUp:
+		m.scroll--
+	case tcell.KeyDown:
+		m.scroll++
+	case tcell.KeyPgUp:
+		m.scroll -= page
+	case tcell.KeyPgDn:
+		m.scroll += page
+	case tcell.KeyHome:
+		m.scroll = 0
+	case tcell.KeyEnd:
+		m.scroll = len(a.messageHistory())
+	case tcell.KeyRune:
+		if ev.Rune() == 'q' {
+			return true
+		}
+	}
+	m.clamp(len(a.messageHistory()), page)
+	return false
+}
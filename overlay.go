@@ -0,0 +1,498 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Модальные окна поверх интерфейса ----
+//
+// Окна складываются в стек a.overlays. Верхнее окно получает клавиатуру
+// из главного цикла (Run), рисуются все окна по порядку поверх панелей.
+// Геометрия пересчитывается на каждом кадре, поэтому resize не ломает окна.
+
+// Модальное окно
+type overlay interface {
+	draw(a *App)
+	// handleKey возвращает true, если окно нужно закрыть
+	handleKey(a *App, ev *tcell.EventKey) bool
+}
+
+// Открыть окно поверх текущих
+func (a *App) pushOverlay(o overlay) {
+	a.overlays = append(a.overlays, o)
+}
+
+// Закрыть верхнее окно
+func (a *App) popOverlay() {
+	if len(a.overlays) > 0 {
+		a.overlays = a.overlays[:len(a.overlays)-1]
+	}
+}
+
+// Передать клавишу верхнему окну. false — окон нет.
+func (a *App) handleOverlayKey(ev *tcell.EventKey) bool {
+	if len(a.overlays) == 0 {
+		return false
+	}
+	top := a.overlays[len(a.overlays)-1]
+	if top.handleKey(a, ev) {
+		// окно могло открыть другое поверх себя — удаляем именно его
+		for i := len(a.overlays) - 1; i >= 0; i-- {
+			if a.overlays[i] == top {
+				a.overlays = append(a.overlays[:i], a.overlays[i+1:]...)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// Нарисовать все окна
+func (a *App) drawOverlays() {
+	for _, o := range a.overlays {
+		o.draw(a)
+	}
+}
+
+// Стили окон из темы
+type dialogStyles struct {
+	body, border, selected, input, dim tcell.Style
+}
+
+func (a *App) dialogStyles() dialogStyles {
+	return a.getStyles().Dialog
+}
+
+// Прямоугольник по центру экрана, не больше экрана
+func (a *App) centeredRect(w, h int) (x, y, rw, rh int) {
+	if w > a.width-2 {
+		w = a.width - 2
+	}
+	if h > a.height-2 {
+		h = a.height - 2
+	}
+	return (a.width - w) / 2, (a.height - h) / 2, w, h
+}
+
+// ---- Поле ввода ----
+
+// Редактируемая строка ввода (общая для prompt и фильтра списка)
+type inputLine struct {
+	text []rune
+	pos  int
+}
+
+func newInputLine(initial string) inputLine {
+	r := []rune(initial)
+	return inputLine{text: r, pos: len(r)}
+}
+
+func (in *inputLine) String() string {
+	return string(in.text)
+}
+
+func (in *inputLine) set(s string) {
+	in.text = []rune(s)
+	in.pos = len(in.text)
+}
+
+// Обработать клавишу редактирования. true — строка изменилась или сдвинулся курсор.
+func (in *inputLine) handleKey(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if in.pos > 0 {
+			in.text = append(in.text[:in.pos-1], in.text[in.pos:]...)
+			in.pos--
+		}
+	case tcell.KeyDelete:
+		if in.pos < len(in.text) {
+			in.text = append(in.text[:in.pos], in.text[in.pos+1:]...)
+		}
+	case tcell.KeyLeft:
+		if in.pos > 0 {
+			in.pos--
+		}
+	case tcell.KeyRight:
+		if in.pos < len(in.text) {
+			in.pos++
+		}
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		in.pos = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		in.pos = len(in.text)
+	case tcell.KeyCtrlU:
+		in.text = in.text[in.pos:]
+		in.pos = 0
+	case tcell.KeyRune:
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			return false
+		}
+		r := ev.Rune()
+		in.text = append(in.text[:in.pos], append([]rune{r}, in.text[in.pos:]...)...)
+		in.pos++
+	default:
+		return false
+	}
+	return true
+}
+
+// Нарисовать строку ввода шириной w и показать терминальный курсор
+func (a *App) drawInputLine(in *inputLine, x, y, w int, style tcell.Style) {
+	for i := 0; i < w; i++ {
+		a.screen.SetContent(x+i, y, ' ', nil, style)
+	}
+	// прокручиваем так, чтобы курсор был виден
+	start := 0
+	for runesDisplayWidth(in.text[start:], in.pos-start) >= w && start < in.pos {
+		start++
+	}
+	ui.PutString(a.screen, x, y, x+w, string(in.text[start:]), style)
+	a.screen.ShowCursor(x+runesDisplayWidth(in.text[start:], in.pos-start), y)
+}
+
+// ---- Запрос строки (prompt) ----
+
+type promptOverlay struct {
+	title    string
+	input    inputLine
+	history  historyNav
+	onSubmit func(text string)
+}
+
+// Запросить строку у пользователя
+func (a *App) prompt(title, initial string, onSubmit func(text string)) {
+	a.pushOverlay(&promptOverlay{title: title, input: newInputLine(initial), onSubmit: onSubmit})
+}
+
+// Запросить строку с историей вида kind (см. history.go)
+func (a *App) promptHistory(kind, title, initial string, onSubmit func(text string)) {
+	a.pushOverlay(&promptOverlay{title: title, input: newInputLine(initial), history: newHistoryNav(a, kind), onSubmit: onSubmit})
+}
+
+func (p *promptOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := a.centeredRect(60, 3)
+	ui.Box(a.screen, x, y, w, h, " "+p.title+" ", st.border, st.body)
+	a.drawInputLine(&p.input, x+2, y+1, w-4, st.input)
+}
+
+func (p *promptOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyEnter:
+		// закрываем до вызова, чтобы обработчик мог открыть новое окно
+		a.popOverlay()
+		if p.history.kind != "" {
+			a.addHistory(p.history.kind, p.input.String())
+		}
+		p.onSubmit(p.input.String())
+		return false
+	}
+	if p.history.handleKey(a, ev, &p.input) {
+		return false
+	}
+	p.input.handleKey(ev)
+	return false
+}
+
+// ---- Подтверждение ----
+
+type confirmOverlay struct {
+	message string
+	onYes   func()
+}
+
+// Спросить подтверждение (y/Enter — да, n/Esc — нет)
+func (a *App) confirm(message string, onYes func()) {
+	a.pushOverlay(&confirmOverlay{message: message, onYes: onYes})
+}
+
+// Спросить подтверждение, только если ask (настройки [confirm])
+func (a *App) confirmIf(ask bool, message string, onYes func()) {
+	if !ask {
+		onYes()
+		return
+	}
+	a.confirm(message, onYes)
+}
+
+func (c *confirmOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	w := runewidth.StringWidth(c.message) + 6
+	if w < 30 {
+		w = 30
+	}
+	x, y, w, h := a.centeredRect(w, 5)
+	ui.Box(a.screen, x, y, w, h, " ? ", st.border, st.body)
+	ui.PutString(a.screen, x+2, y+1, x+w-2, c.message, st.body)
+	ui.PutString(a.screen, x+2, y+3, x+w-2, tr("confirm.hint"), st.dim)
+}
+
+func (c *confirmOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyEnter:
+		a.popOverlay()
+		c.onYes()
+		return false
+	case tcell.KeyRune:
+		switch unicode.ToLower(ev.Rune()) {
+		case 'y', 'д':
+			a.popOverlay()
+			c.onYes()
+			return false
+		case 'n', 'н':
+			return true
+		}
+	}
+	return false
+}
+
+// ---- Список с фильтром (picker) ----
+
+// Элемент списка
+type listItem struct {
+	label  string
+	detail string // дополнительный текст справа (приглушённый)
+	value  string
+}
+
+type listOverlay struct {
+	title    string
+	items    []listItem
+	filter   inputLine
+	filtered []int // индексы items, прошедшие фильтр
+	selected int   // индекс в filtered
+	scroll   int
+	onSelect func(item listItem)
+	// onChange вызывается при смене выделенного элемента (например, для предпросмотра)
+	onChange func(item listItem)
+	// onCancel вызывается при закрытии по Esc
+	onCancel func()
+}
+
+// Показать список для выбора; ввод текста фильтрует элементы
+func (a *App) pick(title string, items []listItem, onSelect func(item listItem)) *listOverlay {
+	l := &listOverlay{title: title, items: items, onSelect: onSelect}
+	l.applyFilter()
+	a.pushOverlay(l)
+	return l
+}
+
+// Нечёткое совпадение: все символы шаблона встречаются в строке по порядку
+func fuzzyMatch(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	p := []rune(strings.ToLower(pattern))
+	i := 0
+	for _, r := range strings.ToLower(s) {
+		if r == p[i] {
+			i++
+			if i == len(p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l *listOverlay) applyFilter() {
+	l.filtered = l.filtered[:0]
+	for i, it := range l.items {
+		if fuzzyMatch(l.filter.String(), it.label) {
+			l.filtered = append(l.filtered, i)
+		}
+	}
+	if l.selected >= len(l.filtered) {
+		l.selected = len(l.filtered) - 1
+	}
+	if l.selected < 0 {
+		l.selected = 0
+	}
+}
+
+// Текущий выбранный элемент
+func (l *listOverlay) current() (listItem, bool) {
+	if len(l.filtered) == 0 {
+		return listItem{}, false
+	}
+	return l.items[l.filtered[l.selected]], true
+}
+
+// Размер окна списка
+func (l *listOverlay) rect(a *App) (x, y, w, h int) {
+	w = 40
+	for _, it := range l.items {
+		if iw := runewidth.StringWidth(it.label) + runewidth.StringWidth(it.detail) + 8; iw > w {
+			w = iw
+		}
+	}
+	if w > 100 {
+		w = 100
+	}
+	return a.centeredRect(w, len(l.items)+4)
+}
+
+func (l *listOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := l.rect(a)
+	ui.Box(a.screen, x, y, w, h, " "+l.title+" ", st.border, st.body)
+
+	visible := h - 4
+	if l.selected < l.scroll {
+		l.scroll = l.selected
+	}
+	if l.selected >= l.scroll+visible {
+		l.scroll = l.selected - visible + 1
+	}
+
+	for i := 0; i < visible && l.scroll+i < len(l.filtered); i++ {
+		idx := l.scroll + i
+		it := l.items[l.filtered[idx]]
+		style, dim := st.body, st.dim
+		if idx == l.selected {
+			style, dim = st.selected, st.selected
+			for cx := x + 1; cx < x+w-1; cx++ {
+				a.screen.SetContent(cx, y+1+i, ' ', nil, style)
+			}
+		}
+		end := ui.PutString(a.screen, x+2, y+1+i, x+w-3, it.label, style)
+		if it.detail != "" {
+			dx := x + w - 3 - runewidth.StringWidth(it.detail)
+			if dx > end+1 {
+				ui.PutString(a.screen, dx, y+1+i, x+w-3, it.detail, dim)
+			}
+		}
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, len(l.filtered), visible, l.scroll)
+
+	// строка фильтра внизу окна
+	ui.PutString(a.screen, x+2, y+h-2, x+w-2, "> ", st.dim)
+	a.drawInputLine(&l.filter, x+4, y+h-2, w-6, st.input)
+}
+
+func (l *listOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	prev := l.selected
+	_, _, _, h := l.rect(a)
+	page := h - 4
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		if l.onCancel != nil {
+			l.onCancel()
+		}
+		return true
+	case tcell.KeyEnter:
+		it, ok := l.current()
+		if !ok {
+			return false
+		}
+		a.popOverlay()
+		l.onSelect(it)
+		return false
+	case tcell.KeyUp, tcell.KeyCtrlP:
+		l.selected--
+	case tcell.KeyDown, tcell.KeyCtrlN:
+		l.selected++
+	case tcell.KeyPgUp:
+		l.selected -= page
+	case tcell.KeyPgDn:
+		l.selected += page
+	default:
+		if l.filter.handleKey(ev) {
+			l.applyFilter()
+		}
+	}
+	if l.selected >= len(l.filtered) {
+		l.selected = len(l.filtered) - 1
+	}
+	if l.selected < 0 {
+		l.selected = 0
+	}
+	if l.selected != prev && l.onChange != nil {
+		if it, ok := l.current(); ok {
+			l.onChange(it)
+		}
+	}
+	return false
+}
+
+// ---- Просмотр текста ----
+
+type textViewOverlay struct {
+	title  string
+	lines  []string
+	scroll int
+	// стиль строки (например, для diff); nil — обычный текст
+	lineStyle func(line string) tcell.Style
+}
+
+// Показать прокручиваемый текст (Esc/q — закрыть)
+func (a *App) showText(title, text string) {
+	a.pushOverlay(&textViewOverlay{title: title, lines: strings.Split(text, "\n")})
+}
+
+func (t *textViewOverlay) rect(a *App) (x, y, w, h int) {
+	return a.centeredRect(a.width-4, a.height-2)
+}
+
+func (t *textViewOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := t.rect(a)
+	ui.Box(a.screen, x, y, w, h, " "+t.title+" ", st.border, st.body)
+	visible := h - 2
+	t.clamp(visible)
+	for i := 0; i < visible && t.scroll+i < len(t.lines); i++ {
+		style := st.body
+		if t.lineStyle != nil {
+			style = t.lineStyle(t.lines[t.scroll+i])
+		}
+		line := strings.ReplaceAll(t.lines[t.scroll+i], "\t", "    ")
+		ui.PutString(a.screen, x+2, y+1+i, x+w-3, line, style)
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, len(t.lines), visible, t.scroll)
+}
+
+func (t *textViewOverlay) clamp(visible int) {
+	if t.scroll > len(t.lines)-visible {
+		t.scroll = len(t.lines) - visible
+	}
+	if t.scroll < 0 {
+		t.scroll = 0
+	}
+}
+
+func (t *textViewOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	_, _, _, h := t.rect(a)
+	page := h - 2
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyUp:
+		t.scroll--
+	case tcell.KeyDown:
+		t.scroll++
+	case tcell.KeyPgUp:
+		t.scroll -= page
+	case tcell.KeyPgDn:
+		t.scroll += page
+	case tcell.KeyHome:
+		t.scroll = 0
+	case tcell.KeyEnd:
+		t.scroll = len(t.lines)
+	case tcell.KeyRune:
+		if ev.Rune() == 'q' {
+			return true
+		}
+	}
+	t.clamp(page)
+	return false
+}
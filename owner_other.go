@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "io/fs"
+
+// Владелец файла: на этой системе не показываем
+func fileOwner(info fs.FileInfo) string {
+	return "—"
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ---- Ширина панели файлов ----
+//
+// panel.width в config.toml — число колонок ("30") или процент ширины
+// терминала ("25%"); процент пересчитывается при каждом изменении
+// размера окна. Alt+> и Alt+< меняют ширину до конца сеанса в тех же
+// единицах: на 1% или на 2 колонки, config.toml не трогается.
+
+const (
+	minPanelWidth   = 12
+	minPanelPercent = 5
+	maxPanelPercent = 75
+)
+
+// Ширина панели: n колонок или n процентов
+type panelSize struct {
+	n       int
+	percent bool
+}
+
+// Разобрать "30" или "25%"
+func parsePanelSize(s string) (panelSize, error) {
+	s = strings.TrimSpace(s)
+	p := panelSize{percent: strings.HasSuffix(s, "%")}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(s, "%")))
+	switch {
+	case err != nil:
+		return p, fmt.Errorf("%q: not a number", s)
+	case p.percent && (n < minPanelPercent || n > maxPanelPercent):
+		return p, fmt.Errorf("%q: percent must be %d..%d", s, minPanelPercent, maxPanelPercent)
+	case !p.percent && n < minPanelWidth:
+		return p, fmt.Errorf("%q: at least %d columns", s, minPanelWidth)
+	}
+	p.n = n
+	return p, nil
+}
+
+// Ширина в колонках при ширине терминала screen. Редактору остаётся
+// не меньше minScreenWidth колонок.
+func (p panelSize) columns(screen int) int {
+	w := p.n
+	if p.percent {
+		w = screen * p.n / 100
+	}
+	return max(min(w, screen-minScreenWidth), minPanelWidth)
+}
+
+// Применить panel.width из настроек
+func (a *App) applyPanelWidth() {
+	p, err := parsePanelSize(a.config.Panel.Width)
+	if err != nil {
+		a.notify(levelWarning, tr("config.panel_width_bad"), err)
+		p = panelSize{n: 30}
+	}
+	a.panelSize = p
+}
+
+// Пересчитать ширину панели под текущую ширину терминала
+func (a *App) updatePanelWidth() {
+	a.panelWidth = a.panelSize.columns(a.width)
+	if a.leftWidth > 0 {
+		a.leftWidth = a.panelWidth
+	}
+}
+
+// Alt+> / Alt+<: шире или уже на время сеанса
+func (a *App) resizePanel(dir int) {
+	if a.leftWidth == 0 || a.narrow() {
+		return
+	}
+	p := a.panelSize
+	if p.percent {
+		p.n = min(max(p.n+dir, minPanelPercent), maxPanelPercent)
+	} else {
+		// не растём дальше, чем позволяет columns
+		w, _ := a.screen.Size()
+		p.n = min(max(p.n+2*dir, minPanelWidth), max(w-minScreenWidth, minPanelWidth))
+	}
+	a.panelSize = p
+	a.resized()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Вставка из терминала (bracketed paste) ----
+//
+// Терминал обрамляет вставленный текст метками начала и конца. Клавиши
+// между ними не обрабатываются по одной, а копятся и вставляются в
+// редактор одной правкой через insertText: без перерисовки на каждый
+// символ и без обработки Enter, Tab и прочих клавиш как команд. Если
+// открыто модальное окно, текст передаётся ему построчно, без переводов
+// строк. В списке файлов и в предпросмотре вставка игнорируется.
+
+// Начало или конец вставки
+func (a *App) handlePaste(ev *tcell.EventPaste) {
+	if ev.Start() {
+		a.pasting = true
+		a.pasteBuf.Reset()
+		return
+	}
+	a.pasting = false
+	text := a.pasteBuf.String()
+	a.pasteBuf.Reset()
+	a.insertPasted(text)
+}
+
+// Клавиша внутри вставки: копим текст
+func (a *App) pasteKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		a.pasteBuf.WriteRune(ev.Rune())
+	case tcell.KeyEnter, tcell.KeyLF:
+		a.pasteBuf.WriteByte('\n')
+	case tcell.KeyTab:
+		a.pasteBuf.WriteByte('\t')
+	}
+}
+
+// Вставить накопленный текст
+func (a *App) insertPasted(text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	if text == "" {
+		return
+	}
+	if len(a.overlays) > 0 {
+		// поля ввода однострочные: переводы строк заменяем пробелами
+		for _, r := range strings.ReplaceAll(text, "\n", " ") {
+			a.handleOverlayKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+		return
+	}
+	if a.activePanel != "right" || a.view.mode != "edit" {
+		return
+	}
+	a.pasteText(text)
+}
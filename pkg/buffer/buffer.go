@@ -0,0 +1,50 @@
+// Package buffer — текст, который правит редактор eddy: содержимое,
+// признак изменения, история отмены и правки по позиции курсора.
+// Позиция — номер строки и индекс руны в ней; курсор встаёт только на
+// границы графем. Экрана пакет не касается: отрисовку строит
+// вызывающий по CachedLines и LineInfo.
+package buffer
+
+import (
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Буфер ----
+//
+// Content можно читать и присваивать напрямую (загрузка файла, замена по
+// всему тексту); правки у курсора — через Insert и Delete*. История
+// отмены (Undo) сравнивает текст с прошлой проверкой, поэтому учитывает
+// любые изменения Content, а не только сделанные методами буфера.
+
+// Grapheme — графема строки: первая руна, число рун и ширина в колонках
+type Grapheme = editor.Grapheme
+
+// Buffer — текст с историей отмены
+type Buffer struct {
+	Content  string
+	Modified bool     // изменён после открытия или записи
+	Undo     *History // история отмены (nil — не ведётся)
+
+	cache lineCache
+}
+
+// New — буфер с текстом content и пустой историей отмены
+func New(content string) *Buffer {
+	return &Buffer{Content: content, Undo: NewHistory(content)}
+}
+
+// Lines — строки буфера (новый срез, хотя бы одна строка)
+func (b *Buffer) Lines() []string {
+	if b.Content == "" {
+		return []string{""}
+	}
+	return strings.Split(b.Content, "\n")
+}
+
+// SetLines — заменить текст строками
+func (b *Buffer) SetLines(lines []string) {
+	b.Content = strings.Join(lines, "\n")
+	b.Modified = true
+}
@@ -0,0 +1,158 @@
+package buffer
+
+import "testing"
+
+// Позиция курсора
+type pos struct{ y, x int }
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		content string
+		at      pos
+		text    string
+		want    string
+		cursor  pos
+	}{
+		{"", pos{0, 0}, "abc", "abc", pos{0, 3}},
+		{"hello", pos{0, 5}, " world", "hello world", pos{0, 11}},
+		{"ac", pos{0, 1}, "b", "abc", pos{0, 2}},
+		{"ad", pos{0, 1}, "b\nc", "ab\ncd", pos{1, 1}},
+		{"one\ntwo", pos{1, 0}, "x\n", "one\nx\ntwo", pos{2, 0}},
+		{"привет", pos{0, 3}, "-", "при-вет", pos{0, 4}},
+		// позиция за пределами текста прижимается к нему
+		{"ab", pos{5, 9}, "c", "abc", pos{0, 3}},
+		// внутри графемы — к её началу
+		{"e\u0301x", pos{0, 1}, "a", "ae\u0301x", pos{0, 1}},
+		{"ab", pos{0, 1}, "", "ab", pos{0, 1}},
+	}
+	for _, tt := range tests {
+		b := &Buffer{Content: tt.content}
+		y, x := b.Insert(tt.at.y, tt.at.x, tt.text)
+		if b.Content != tt.want || (pos{y, x}) != tt.cursor {
+			t.Errorf("Insert(%q at %v, %q) = %q %v, want %q %v", tt.content, tt.at, tt.text, b.Content, pos{y, x}, tt.want, tt.cursor)
+		}
+		if b.Modified != (tt.text != "") {
+			t.Errorf("Insert(%q, %q): Modified = %v", tt.content, tt.text, b.Modified)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		content string
+		at      pos
+		forward bool
+		want    string
+		cursor  pos
+	}{
+		{"abc", pos{0, 2}, false, "ac", pos{0, 1}},
+		{"abc", pos{0, 0}, false, "abc", pos{0, 0}},
+		{"ab\ncd", pos{1, 0}, false, "abcd", pos{0, 2}},
+		{"abc", pos{0, 1}, true, "ac", pos{0, 1}},
+		{"ab\ncd", pos{0, 2}, true, "abcd", pos{0, 2}},
+		{"ab", pos{0, 2}, true, "ab", pos{0, 2}},
+		// графема удаляется целиком
+		{"xe\u0301", pos{0, 3}, false, "x", pos{0, 1}},
+		{"👍🏽!", pos{0, 0}, true, "!", pos{0, 0}},
+		{"ab", pos{3, 0}, false, "ab", pos{3, 0}},
+	}
+	for _, tt := range tests {
+		b := &Buffer{Content: tt.content}
+		var y, x int
+		if tt.forward {
+			y, x = b.DeleteForward(tt.at.y, tt.at.x)
+		} else {
+			y, x = b.DeleteBackward(tt.at.y, tt.at.x)
+		}
+		if b.Content != tt.want || (pos{y, x}) != tt.cursor {
+			t.Errorf("delete(%q at %v, forward=%v) = %q %v, want %q %v", tt.content, tt.at, tt.forward, b.Content, pos{y, x}, tt.want, tt.cursor)
+		}
+		if b.Modified != (tt.want != tt.content) {
+			t.Errorf("delete(%q at %v): Modified = %v", tt.content, tt.at, b.Modified)
+		}
+	}
+}
+
+func TestTextAndDeleteRange(t *testing.T) {
+	tests := []struct {
+		content    string
+		start, end pos
+		text, rest string
+	}{
+		{"hello world", pos{0, 0}, pos{0, 5}, "hello", " world"},
+		{"one\ntwo\nthree", pos{0, 1}, pos{2, 2}, "ne\ntwo\nth", "oree"},
+		{"ab\ncd", pos{0, 2}, pos{1, 0}, "\n", "abcd"},
+		{"ёжик", pos{0, 1}, pos{0, 3}, "жи", "ёк"},
+	}
+	for _, tt := range tests {
+		b := &Buffer{Content: tt.content}
+		if got := b.Text(tt.start.y, tt.start.x, tt.end.y, tt.end.x); got != tt.text {
+			t.Errorf("Text(%q, %v-%v) = %q, want %q", tt.content, tt.start, tt.end, got, tt.text)
+		}
+		y, x := b.DeleteRange(tt.start.y, tt.start.x, tt.end.y, tt.end.x)
+		if b.Content != tt.rest || (pos{y, x}) != tt.start {
+			t.Errorf("DeleteRange(%q, %v-%v) = %q %v, want %q %v", tt.content, tt.start, tt.end, b.Content, pos{y, x}, tt.rest, tt.start)
+		}
+	}
+}
+
+func TestLineCache(t *testing.T) {
+	b := &Buffer{Content: "one two\nthree"}
+	if got := b.CachedLines(); len(got) != 2 || got[1] != "three" {
+		t.Fatalf("CachedLines = %q", got)
+	}
+	if n := b.WordCount(); n != 3 {
+		t.Errorf("WordCount = %d, want 3", n)
+	}
+	first := b.LineInfo(0)
+	if string(first.Runes) != "one two" || len(first.Graphemes) != 7 {
+		t.Errorf("LineInfo(0) = %q, %d graphemes", string(first.Runes), len(first.Graphemes))
+	}
+
+	// присваивание Content сбрасывает разбиение и счётчик слов
+	b.Content = "one two\nthree four\nfive"
+	if got := b.CachedLines(); len(got) != 3 || got[2] != "five" {
+		t.Fatalf("after assignment CachedLines = %q", got)
+	}
+	if n := b.WordCount(); n != 5 {
+		t.Errorf("after assignment WordCount = %d, want 5", n)
+	}
+	if got := string(b.LineInfo(1).Runes); got != "three four" {
+		t.Errorf("after assignment LineInfo(1) = %q", got)
+	}
+	// неизменённая строка переходит из прошлого поколения
+	if b.LineInfo(0) != first {
+		t.Error("unchanged line was recomputed")
+	}
+
+	// правка методами тоже видна кэшу
+	b.Insert(2, 4, "!")
+	if got := string(b.LineInfo(2).Runes); got != "five!" {
+		t.Errorf("after Insert LineInfo(2) = %q", got)
+	}
+}
+
+func TestLineInfoWidth(t *testing.T) {
+	b := &Buffer{Content: "a界e\u0301b"}
+	li := b.LineInfo(0)
+	for _, tt := range []struct{ upto, want int }{{0, 0}, {1, 1}, {2, 3}, {4, 4}, {5, 5}} {
+		if got := li.Width(tt.upto); got != tt.want {
+			t.Errorf("Width(%d) = %d, want %d", tt.upto, got, tt.want)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	b := &Buffer{Content: "ab\ne\u0301"}
+	for _, tt := range []struct{ in, want pos }{
+		{pos{-1, -1}, pos{0, 0}},
+		{pos{0, 9}, pos{0, 2}},
+		{pos{7, 0}, pos{1, 0}},
+		{pos{1, 1}, pos{1, 0}},
+		{pos{1, 2}, pos{1, 2}},
+	} {
+		if y, x := b.Clamp(tt.in.y, tt.in.x); (pos{y, x}) != tt.want {
+			t.Errorf("Clamp(%v) = %v, want %v", tt.in, pos{y, x}, tt.want)
+		}
+	}
+}
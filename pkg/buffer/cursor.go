@@ -0,0 +1,113 @@
+package buffer
+
+import (
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Правки у курсора ----
+//
+// Методы принимают позицию (строка y, руна x) и возвращают позицию
+// курсора после правки. Удаление работает с графемой целиком; в начале
+// строки Backspace склеивает её с предыдущей, в конце Delete — со
+// следующей. Modified ставится, только если текст изменился.
+
+// Clamp — ближайшая допустимая позиция: в пределах текста и на границе графемы
+func (b *Buffer) Clamp(y, x int) (int, int) {
+	lines := b.CachedLines()
+	y = min(max(y, 0), len(lines)-1)
+	runes := b.LineInfo(y).Runes
+	x = min(max(x, 0), len(runes))
+	return y, editor.SnapGrapheme(runes, x)
+}
+
+// Insert — вставить текст (возможно многострочный) в позицию y, x
+func (b *Buffer) Insert(y, x int, text string) (int, int) {
+	y, x = b.Clamp(y, x)
+	if text == "" {
+		return y, x
+	}
+	lines := b.Lines()
+	runes := []rune(lines[y])
+	head := string(runes[:x])
+	tail := string(runes[x:])
+
+	ins := strings.Split(text, "\n")
+	ins[0] = head + ins[0]
+	last := len(ins) - 1
+	x = len([]rune(ins[last]))
+	ins[last] += tail
+
+	out := append([]string{}, lines[:y]...)
+	out = append(out, ins...)
+	out = append(out, lines[y+1:]...)
+	b.SetLines(out)
+	return y + last, x
+}
+
+// DeleteBackward — Backspace: графема перед позицией или перевод строки
+func (b *Buffer) DeleteBackward(y, x int) (int, int) {
+	lines := b.Lines()
+	if y < 0 || y >= len(lines) {
+		return y, x
+	}
+	line := lines[y]
+	runes := []rune(line)
+	switch {
+	case x > 0 && x <= len(runes):
+		from := editor.PrevGrapheme(runes, x)
+		lines[y] = string(append(runes[:from], runes[x:]...))
+		b.SetLines(lines)
+		return y, from
+	case x == 0 && y > 0:
+		prev := lines[y-1]
+		lines[y-1] = prev + line
+		b.SetLines(append(lines[:y], lines[y+1:]...))
+		return y - 1, len([]rune(prev))
+	}
+	return y, x
+}
+
+// DeleteForward — Delete: графема под позицией или перевод строки
+func (b *Buffer) DeleteForward(y, x int) (int, int) {
+	lines := b.Lines()
+	if y < 0 || y >= len(lines) {
+		return y, x
+	}
+	line := lines[y]
+	runes := []rune(line)
+	switch {
+	case x < len(runes):
+		lines[y] = string(append(runes[:x], runes[editor.NextGrapheme(runes, x):]...))
+		b.SetLines(lines)
+	case y < len(lines)-1:
+		lines[y] = line + lines[y+1]
+		b.SetLines(append(lines[:y+1], lines[y+2:]...))
+	}
+	return y, x
+}
+
+// Text — текст от позиции sy, sx до ey, ex (конец не включается)
+func (b *Buffer) Text(sy, sx, ey, ex int) string {
+	lines := b.CachedLines()
+	if sy == ey {
+		return string(b.LineInfo(sy).Runes[sx:ex])
+	}
+	parts := []string{string(b.LineInfo(sy).Runes[sx:])}
+	parts = append(parts, lines[sy+1:ey]...)
+	parts = append(parts, string(b.LineInfo(ey).Runes[:ex]))
+	return strings.Join(parts, "\n")
+}
+
+// DeleteRange — удалить текст от sy, sx до ey, ex; курсор встаёт на начало
+func (b *Buffer) DeleteRange(sy, sx, ey, ex int) (int, int) {
+	lines := b.Lines()
+	head := []rune(lines[sy])[:sx]
+	tail := []rune(lines[ey])[ex:]
+	out := append([]string{}, lines[:sy]...)
+	out = append(out, string(head)+string(tail))
+	out = append(out, lines[ey+1:]...)
+	b.SetLines(out)
+	return sy, sx
+}
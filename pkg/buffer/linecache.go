@@ -0,0 +1,81 @@
+package buffer
+
+import (
+	"strings"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Кэш строк для отрисовки ----
+//
+// Кадр редактора разбивал весь файл на строки и переводил каждую видимую
+// строку в руны и графемы по нескольку раз. Буфер хранит разбиение и для
+// каждой строки — руны с графемами. Content можно менять присваиванием,
+// поэтому разбиение сверяется с ним при каждом запросе.
+// Сведения о строках хранятся по их тексту: после правки пересчитываются
+// только изменённые строки, остальные переходят из прошлого поколения.
+// Кэш только для чтения — кто правит строки, берёт копию через Lines().
+
+// LineInfo — руны и графемы строки
+type LineInfo struct {
+	Runes     []rune
+	Graphemes []editor.Grapheme
+}
+
+// Кэш разбиения содержимого буфера
+type lineCache struct {
+	content string
+	lines   []string
+	words   int                  // -1 — ещё не посчитано
+	info    map[string]*LineInfo // строки, запрошенные после правки
+	prev    map[string]*LineInfo // строки до неё
+}
+
+// CachedLines — строки буфера из кэша (не изменять!)
+func (b *Buffer) CachedLines() []string {
+	c := &b.cache
+	if c.lines == nil || c.content != b.Content {
+		c.content = b.Content
+		c.lines = strings.Split(b.Content, "\n")
+		c.words = -1
+		c.prev, c.info = c.info, map[string]*LineInfo{}
+	}
+	return c.lines
+}
+
+// LineInfo — руны и графемы строки y (не изменять!)
+func (b *Buffer) LineInfo(y int) *LineInfo {
+	line := b.CachedLines()[y]
+	c := &b.cache
+	if li := c.info[line]; li != nil {
+		return li
+	}
+	li := c.prev[line]
+	if li == nil {
+		runes := []rune(line)
+		li = &LineInfo{Runes: runes, Graphemes: editor.Graphemes(runes)}
+	}
+	c.info[line] = li
+	return li
+}
+
+// Width — ширина на экране первых upto рун
+func (li *LineInfo) Width(upto int) int {
+	w := 0
+	for _, g := range li.Graphemes {
+		if g.Start >= upto {
+			break
+		}
+		w += g.Width
+	}
+	return w
+}
+
+// WordCount — число слов (для строки состояния)
+func (b *Buffer) WordCount() int {
+	b.CachedLines()
+	if b.cache.words < 0 {
+		b.cache.words = len(strings.Fields(b.Content))
+	}
+	return b.cache.words
+}
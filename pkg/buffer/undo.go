@@ -0,0 +1,219 @@
+package buffer
+
+import (
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---- История отмены ----
+//
+// Record сравнивает текст с последним запомненным: изменение
+// записывается как замена участка текста — позиция, удалённый и
+// вставленный текст, курсор до и после. Так отменяются любые правки без
+// отдельного учёта в каждой команде. Набор подряд и удаление подряд
+// объединяются в один шаг, пока нет паузы дольше GroupPause и пока не
+// начато новое слово. Объём истории ограничен MaxBytes: старые шаги
+// отбрасываются.
+
+// Entry — шаг отмены: на позиции Pos (в байтах) текст Removed заменён на Inserted
+type Entry struct {
+	Pos      int    `json:"pos"`
+	Removed  string `json:"removed"`
+	Inserted string `json:"inserted"`
+	// Курсор до и после правки: строка, руна
+	BeforeY int `json:"before_y"`
+	BeforeX int `json:"before_x"`
+	AfterY  int `json:"after_y"`
+	AfterX  int `json:"after_x"`
+
+	when time.Time
+}
+
+// Память, занимаемая шагом (текст и служебные поля)
+func (e *Entry) size() int {
+	return len(e.Removed) + len(e.Inserted) + 64
+}
+
+// History — история отмены буфера
+type History struct {
+	GroupPause time.Duration // набор с паузой не дольше — один шаг
+	MaxBytes   int           // предел объёма (0 — без предела)
+
+	undo, redo []*Entry
+	// текст и курсор на момент последней проверки
+	base         string
+	baseY, baseX int
+	// объём undo и redo (см. size)
+	bytes int
+}
+
+// NewHistory — пустая история для текста content
+func NewHistory(content string) *History {
+	return &History{base: content}
+}
+
+// Changed — изменился ли текст с последней проверки
+func (h *History) Changed(content string) bool {
+	return content != h.base
+}
+
+// Record — записать изменение текста с последней проверки; y, x — курсор
+// после правки. Без изменений только запоминается курсор.
+func (h *History) Record(content string, y, x int) {
+	if content == h.base {
+		h.baseY, h.baseX = y, x
+		return
+	}
+	e := diff(h.base, content)
+	e.BeforeY, e.BeforeX = h.baseY, h.baseX
+	e.AfterY, e.AfterX = y, x
+	e.when = time.Now()
+	h.base = content
+	h.baseY, h.baseX = y, x
+
+	h.clearRedo()
+	if n := len(h.undo); n > 0 {
+		last := h.undo[n-1]
+		size := last.size()
+		if last.merge(e, h.GroupPause) {
+			// шаг вырос: учитываем прибавку, как при добавлении
+			h.bytes += last.size() - size
+			h.trim()
+			return
+		}
+	}
+	h.push(e)
+}
+
+// Undo — текст content без последнего шага и курсор до него; false — шагов нет
+func (h *History) Undo(content string) (string, int, int, bool) {
+	if len(h.undo) == 0 {
+		return content, 0, 0, false
+	}
+	e := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, e)
+	content = content[:e.Pos] + e.Removed + content[e.Pos+len(e.Inserted):]
+	h.base = content
+	return content, e.BeforeY, e.BeforeX, true
+}
+
+// Redo — вернуть отменённый шаг; false — возвращать нечего
+func (h *History) Redo(content string) (string, int, int, bool) {
+	if len(h.redo) == 0 {
+		return content, 0, 0, false
+	}
+	e := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, e)
+	content = content[:e.Pos] + e.Inserted + content[e.Pos+len(e.Removed):]
+	h.base = content
+	return content, e.AfterY, e.AfterX, true
+}
+
+// Last — последний шаг отмены (nil — нет)
+func (h *History) Last() *Entry {
+	if len(h.undo) == 0 {
+		return nil
+	}
+	return h.undo[len(h.undo)-1]
+}
+
+// Steps — шаги отмены и возврата (для сохранения)
+func (h *History) Steps() (undo, redo []*Entry) {
+	return h.undo, h.redo
+}
+
+// Restore — подставить сохранённые шаги (текст должен быть тем же)
+func (h *History) Restore(undo, redo []*Entry) {
+	h.undo, h.redo = undo, redo
+	h.bytes = 0
+	for _, e := range append(append([]*Entry{}, undo...), redo...) {
+		h.bytes += e.size()
+	}
+}
+
+// Разница двух текстов как одна замена: общие начало и конец отбрасываются
+func diff(old, cur string) *Entry {
+	p := 0
+	for p < len(old) && p < len(cur) && old[p] == cur[p] {
+		p++
+	}
+	// не режем многобайтовую руну
+	for p > 0 && p < len(old) && !utf8.RuneStart(old[p]) {
+		p--
+	}
+	s := 0
+	for s < len(old)-p && s < len(cur)-p && old[len(old)-1-s] == cur[len(cur)-1-s] {
+		s++
+	}
+	for s > 0 && !utf8.RuneStart(old[len(old)-s]) {
+		s--
+	}
+	return &Entry{Pos: p, Removed: old[p : len(old)-s], Inserted: cur[p : len(cur)-s]}
+}
+
+// Одна руна, а не вставка или замена
+func singleRune(s string) bool {
+	return utf8.RuneCountInString(s) == 1
+}
+
+// Объединить следующую правку с этой (набор или удаление подряд)
+func (e *Entry) merge(next *Entry, pause time.Duration) bool {
+	if next.when.Sub(e.when) > pause {
+		return false
+	}
+	switch {
+	case next.Removed == "" && e.Removed == "" && singleRune(next.Inserted):
+		// набор подряд; новое слово (не пробел после пробела) — новый шаг
+		if next.Pos != e.Pos+len(e.Inserted) {
+			return false
+		}
+		last, _ := utf8.DecodeLastRuneInString(e.Inserted)
+		r, _ := utf8.DecodeRuneInString(next.Inserted)
+		if unicode.IsSpace(last) && !unicode.IsSpace(r) {
+			return false
+		}
+		e.Inserted += next.Inserted
+	case next.Inserted == "" && e.Inserted == "" && singleRune(next.Removed):
+		switch next.Pos {
+		case e.Pos - len(next.Removed): // Backspace
+			e.Pos = next.Pos
+			e.Removed = next.Removed + e.Removed
+		case e.Pos: // Delete
+			e.Removed += next.Removed
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	e.AfterY, e.AfterX = next.AfterY, next.AfterX
+	e.when = next.when
+	return true
+}
+
+// Добавить шаг, отбрасывая самые старые сверх MaxBytes
+func (h *History) push(e *Entry) {
+	h.undo = append(h.undo, e)
+	h.bytes += e.size()
+	h.trim()
+}
+
+// Отбросить самые старые шаги сверх MaxBytes (последний остаётся)
+func (h *History) trim() {
+	drop := 0
+	for h.MaxBytes > 0 && h.bytes > h.MaxBytes && drop < len(h.undo)-1 {
+		h.bytes -= h.undo[drop].size()
+		drop++
+	}
+	h.undo = h.undo[drop:]
+}
+
+func (h *History) clearRedo() {
+	for _, e := range h.redo {
+		h.bytes -= e.size()
+	}
+	h.redo = nil
+}
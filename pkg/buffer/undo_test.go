@@ -0,0 +1,198 @@
+package buffer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		old, cur          string
+		pos               int
+		removed, inserted string
+	}{
+		{"abc", "abXc", 2, "", "X"},
+		{"abc", "ac", 1, "b", ""},
+		{"hello world", "hello there", 6, "world", "there"},
+		{"", "new", 0, "", "new"},
+		{"aaa", "aaaa", 3, "", "a"},
+		// общий префикс не режет многобайтовую руну
+		{"жа", "жб", 2, "а", "б"},
+		{"ёж", "ëж", 0, "ё", "ë"},
+	}
+	for _, tt := range tests {
+		e := diff(tt.old, tt.cur)
+		if e.Pos != tt.pos || e.Removed != tt.removed || e.Inserted != tt.inserted {
+			t.Errorf("diff(%q, %q) = {%d %q %q}, want {%d %q %q}", tt.old, tt.cur, e.Pos, e.Removed, e.Inserted, tt.pos, tt.removed, tt.inserted)
+		}
+		if got := tt.old[:e.Pos] + e.Inserted + tt.old[e.Pos+len(e.Removed):]; got != tt.cur {
+			t.Errorf("diff(%q, %q) applied = %q", tt.old, tt.cur, got)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	now := time.Now()
+	pause := time.Second
+	tests := []struct {
+		name       string
+		prev, next Entry
+		later      time.Duration
+		ok         bool
+		want       Entry
+	}{
+		{"typing", Entry{Pos: 0, Inserted: "ab"}, Entry{Pos: 2, Inserted: "c"}, 0, true, Entry{Pos: 0, Inserted: "abc"}},
+		{"space after word", Entry{Pos: 0, Inserted: "ab"}, Entry{Pos: 2, Inserted: " "}, 0, true, Entry{Pos: 0, Inserted: "ab "}},
+		{"new word", Entry{Pos: 0, Inserted: "ab "}, Entry{Pos: 3, Inserted: "c"}, 0, false, Entry{}},
+		{"pause", Entry{Pos: 0, Inserted: "ab"}, Entry{Pos: 2, Inserted: "c"}, 2 * time.Second, false, Entry{}},
+		{"elsewhere", Entry{Pos: 0, Inserted: "ab"}, Entry{Pos: 7, Inserted: "c"}, 0, false, Entry{}},
+		{"paste", Entry{Pos: 0, Inserted: "ab"}, Entry{Pos: 2, Inserted: "cd"}, 0, false, Entry{}},
+		{"backspace", Entry{Pos: 5, Removed: "e"}, Entry{Pos: 4, Removed: "d"}, 0, true, Entry{Pos: 4, Removed: "de"}},
+		{"delete", Entry{Pos: 5, Removed: "e"}, Entry{Pos: 5, Removed: "f"}, 0, true, Entry{Pos: 5, Removed: "ef"}},
+		{"typing then delete", Entry{Pos: 0, Inserted: "ab"}, Entry{Pos: 1, Removed: "b"}, 0, false, Entry{}},
+	}
+	for _, tt := range tests {
+		prev, next := tt.prev, tt.next
+		prev.when = now
+		next.when = now.Add(tt.later)
+		next.AfterX = 42
+		ok := prev.merge(&next, pause)
+		if ok != tt.ok {
+			t.Errorf("%s: merge = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if prev.Pos != tt.want.Pos || prev.Removed != tt.want.Removed || prev.Inserted != tt.want.Inserted {
+			t.Errorf("%s: merged {%d %q %q}, want {%d %q %q}", tt.name, prev.Pos, prev.Removed, prev.Inserted, tt.want.Pos, tt.want.Removed, tt.want.Inserted)
+		}
+		if prev.AfterX != 42 {
+			t.Errorf("%s: cursor after merge not taken from the next edit", tt.name)
+		}
+	}
+}
+
+func TestHistoryUndoRedo(t *testing.T) {
+	h := NewHistory("")
+	h.GroupPause = time.Hour
+	text := ""
+	for i, r := range "ab cd" {
+		text += string(r)
+		h.Record(text, 0, i+1)
+	}
+	// "ab " и "cd" — два шага
+	if undo, _ := h.Steps(); len(undo) != 2 {
+		t.Fatalf("%d undo steps, want 2", len(undo))
+	}
+
+	text, y, x, ok := h.Undo(text)
+	if !ok || text != "ab " || y != 0 || x != 3 {
+		t.Fatalf("Undo = %q %d,%d %v, want %q 0,3", text, y, x, ok, "ab ")
+	}
+	text, _, x, _ = h.Undo(text)
+	if text != "" || x != 0 {
+		t.Fatalf("second Undo = %q %d", text, x)
+	}
+	if _, _, _, ok := h.Undo(text); ok {
+		t.Fatal("Undo on empty history succeeded")
+	}
+	text, _, x, _ = h.Redo(text)
+	if text != "ab " || x != 3 {
+		t.Fatalf("Redo = %q %d", text, x)
+	}
+
+	// новая правка после отмены сбрасывает возврат
+	text += "X"
+	h.Record(text, 0, 4)
+	if _, _, _, ok := h.Redo(text); ok {
+		t.Fatal("Redo after a new edit succeeded")
+	}
+	if h.Changed(text) {
+		t.Error("Changed right after Record")
+	}
+	if last := h.Last(); last == nil || last.Inserted != "X" {
+		t.Errorf("Last = %+v", last)
+	}
+}
+
+func TestHistoryMaxBytes(t *testing.T) {
+	h := NewHistory("")
+	h.MaxBytes = 3 * (10 + 64)
+	text := ""
+	for i := 0; i < 10; i++ {
+		text += "0123456789"
+		h.Record(text, 0, len(text))
+	}
+	undo, _ := h.Steps()
+	if len(undo) != 3 {
+		t.Fatalf("%d steps kept, want 3", len(undo))
+	}
+	// остаются самые новые шаги
+	for range 3 {
+		text, _, _, _ = h.Undo(text)
+	}
+	if len(text) != 70 {
+		t.Errorf("after undoing kept steps len = %d, want 70", len(text))
+	}
+}
+
+func TestHistoryRestore(t *testing.T) {
+	h := NewHistory("abc")
+	h.Record("abcd", 0, 4)
+	undo, redo := h.Steps()
+
+	h2 := NewHistory("abcd")
+	h2.Restore(undo, redo)
+	text, _, x, ok := h2.Undo("abcd")
+	if !ok || text != "abc" || x != 0 {
+		t.Errorf("Undo after Restore = %q %d %v", text, x, ok)
+	}
+}
+
+func TestHistoryMaxBytesGrouped(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(text string) string // правка одной клавишей
+		mark func(text string) string // правка в другом месте
+		init string
+	}{
+		{"typing", func(s string) string { return s + "x" }, func(s string) string { return "#" + s }, ""},
+		{"backspace", func(s string) string { return s[:len(s)-1] }, func(s string) string { return "#" + s }, strings.Repeat("x", 2000)},
+		{"delete", func(s string) string { return s[1:] }, func(s string) string { return s + "#" }, strings.Repeat("x", 2000)},
+	}
+	for _, tt := range tests {
+		h := NewHistory(tt.init)
+		h.GroupPause = time.Hour
+		h.MaxBytes = 3 * (100 + 64)
+		text := tt.init
+		// пять серий по 100 клавиш; серии разделяет правка в другом месте
+		for range 5 {
+			for range 100 {
+				text = tt.edit(text)
+				h.Record(text, 0, 0)
+			}
+			text = tt.mark(text)
+			h.Record(text, 0, 0)
+		}
+		undo, _ := h.Steps()
+		total := 0
+		for _, e := range undo {
+			total += e.size()
+		}
+		if h.bytes != total {
+			t.Errorf("%s: counted %d bytes, steps take %d", tt.name, h.bytes, total)
+		}
+		if total > h.MaxBytes {
+			t.Errorf("%s: %d steps take %d bytes, over the %d cap", tt.name, len(undo), total, h.MaxBytes)
+		}
+		// старые серии отброшены, последняя осталась целиком
+		if len(undo) >= 10 {
+			t.Errorf("%s: %d steps kept, old ones were not dropped", tt.name, len(undo))
+		}
+		if last := undo[len(undo)-2]; len(last.Inserted)+len(last.Removed) != 100 {
+			t.Errorf("%s: last run is %+v, want 100 keys in one step", tt.name, last)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mdrender
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Выноски (callouts) ----
+//
+// Цитата, первая строка которой — > [!NOTE], > [!WARNING] и т. п. (как в
+// GitHub и Obsidian), показывается выноской: строка-заголовок цвета
+// типа и тонированное тело до конца цитаты. Текст после [!TYPE] — свой
+// заголовок вместо названия типа; +/- (сворачивание в Obsidian)
+// пропускается. Стили — Styles.Callout по типу.
+
+// CalloutStyles — стили выноски: строка-заголовок и тело
+type CalloutStyles struct {
+	Title, Body tcell.Style
+}
+
+var calloutRe = regexp.MustCompile(`^\s*>\s?\[!(\w+)\][+-]?\s*(.*)$`)
+
+// Типы Obsidian, сведённые к пяти типам GitHub
+var calloutAliases = map[string]string{
+	"info": "note", "abstract": "note", "summary": "note", "tldr": "note",
+	"todo": "note", "quote": "note", "cite": "note",
+	"hint": "tip", "success": "tip", "check": "tip", "done": "tip",
+	"example": "important", "question": "warning", "help": "warning",
+	"faq": "warning", "attention": "warning", "danger": "caution",
+	"error": "caution", "failure": "caution", "fail": "caution",
+	"missing": "caution", "bug": "caution",
+}
+
+// Значок типа в строке-заголовке
+var calloutIcons = map[string]string{
+	"note": "ℹ", "tip": "★", "important": "!", "warning": "⚠", "caution": "✖",
+}
+
+// Строка-заголовок выноски: тип (один из пяти) и свой заголовок
+func parseCallout(line string) (kind, title string, ok bool) {
+	m := calloutRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	kind = strings.ToLower(m[1])
+	if alias, ok := calloutAliases[kind]; ok {
+		kind = alias
+	}
+	if _, ok := calloutIcons[kind]; !ok {
+		kind = "note"
+	}
+	return kind, strings.TrimSpace(m[2]), true
+}
+
+// Строка тела цитаты без маркера >; false — цитата кончилась
+func calloutBody(line string) (string, bool) {
+	rest := strings.TrimLeft(line, " ")
+	if !strings.HasPrefix(rest, ">") {
+		return "", false
+	}
+	rest = rest[1:]
+	return strings.TrimPrefix(rest, " "), true
+}
+
+// Стили предпросмотра на фоне тела выноски (у кода — свой фон)
+func (m Styles) onBackground(bg tcell.Color) Styles {
+	m.Link = m.Link.Background(bg)
+	m.ListMarker = m.ListMarker.Background(bg)
+	m.Math = m.Math.Background(bg)
+	return m
+}
+
+// Строка-заголовок выноски (фон — на всю ширину)
+func calloutTitleLine(src int, st CalloutStyles, kind, title string, opt *Options) Line {
+	if title == "" {
+		title = opt.label("callout." + kind)
+	}
+	l := Line{Src: src, Fill: st.Title, Filled: true}
+	l.put([]rune(" "+calloutIcons[kind]+" "+title), nil, st.Title)
+	return l
+}
@@ -0,0 +1,41 @@
+package mdrender
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// ---- Экранирование и HTML-сущности ----
+//
+// Как в CommonMark: \ перед знаком препинания ASCII показывает сам знак
+// без особого смысла (\* — звёздочка, а не курсив, \` — не код), а
+// &amp;, &mdash;, &nbsp;, &#169; и &#x2014; показываются символом. В коде
+// (`…` и блоки ```) и формулах всё остаётся как есть.
+
+// Знаки, которые можно экранировать
+const mdEscapable = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+var mdEntityRe = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+
+// Экранирован ли знак runes[i+1] обратной косой чертой runes[i]
+func isMDEscape(runes []rune, i int) bool {
+	return runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(mdEscapable, runes[i+1])
+}
+
+// HTML-сущность с runes[i]: текст и длина в рунах (0 — не сущность)
+func mdEntity(runes []rune, i int) (string, int) {
+	if runes[i] != '&' {
+		return "", 0
+	}
+	end := min(i+40, len(runes))
+	m := mdEntityRe.FindString(string(runes[i:end]))
+	if m == "" {
+		return "", 0
+	}
+	s := html.UnescapeString(m)
+	if s == m || s == "" {
+		return "", 0 // неизвестная сущность — как есть
+	}
+	return s, len([]rune(m))
+}
@@ -0,0 +1,84 @@
+package mdrender
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// ---- HTML в тексте Markdown ----
+//
+// Теги известных элементов HTML не печатаются: <br> переносит строку,
+// <img> показывается заменителем [image: alt], <b>/<strong>,
+// <i>/<em>, <u>, <code>/<kbd> меняют стиль, <summary> помечается ▸,
+// комментарии <!-- … --> скрываются, остальные теги (<details>, <div>,
+// <span>…) просто убираются. <T> и <https://…> тегами не считаются.
+
+var (
+	htmlTagRe     = regexp.MustCompile(`^<(/?)([A-Za-z][A-Za-z0-9]*)((?:\s[^<>]*)?)/?>`)
+	htmlCommentRe = regexp.MustCompile(`^<!--.*?-->`)
+	htmlAttrRe    = regexp.MustCompile(`([A-Za-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// Элементы, теги которых убираются из предпросмотра
+var htmlElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "big": true, "br": true, "center": true,
+	"code": true, "dd": true, "del": true, "details": true, "div": true, "dl": true,
+	"dt": true, "em": true, "font": true, "hr": true, "i": true, "img": true,
+	"ins": true, "kbd": true, "mark": true, "p": true, "picture": true, "pre": true,
+	"s": true, "samp": true, "small": true, "source": true, "span": true, "strike": true,
+	"strong": true, "sub": true, "summary": true, "sup": true, "table": true, "tbody": true,
+	"td": true, "th": true, "thead": true, "tr": true, "tt": true, "u": true, "var": true,
+	"video": true,
+}
+
+// Тег HTML с runes[i]
+type htmlTag struct {
+	name  string // в нижнем регистре; "" — комментарий
+	close bool
+	attrs string
+	n     int // длина в рунах
+}
+
+// Разобрать тег или комментарий, начинающийся с runes[i]
+func parseHTMLTag(runes []rune, i int) (htmlTag, bool) {
+	if runes[i] != '<' {
+		return htmlTag{}, false
+	}
+	s := string(runes[i:])
+	if m := htmlCommentRe.FindString(s); m != "" {
+		return htmlTag{n: len([]rune(m))}, true
+	}
+	m := htmlTagRe.FindStringSubmatch(s)
+	if m == nil || !htmlElements[strings.ToLower(m[2])] {
+		return htmlTag{}, false
+	}
+	return htmlTag{
+		name:  strings.ToLower(m[2]),
+		close: m[1] == "/",
+		attrs: m[3],
+		n:     len([]rune(m[0])),
+	}, true
+}
+
+// Значение атрибута тега
+func (t htmlTag) attr(name string) string {
+	for _, m := range htmlAttrRe.FindAllStringSubmatch(t.attrs, -1) {
+		if strings.EqualFold(m[1], name) {
+			return html.UnescapeString(m[2] + m[3] + m[4])
+		}
+	}
+	return ""
+}
+
+// Заменитель картинки <img>
+func (t htmlTag) imagePlaceholder(opt *Options) string {
+	alt := t.attr("alt")
+	if alt == "" {
+		alt = t.attr("src")
+	}
+	if alt == "" {
+		return "[" + opt.label("preview.image") + "]"
+	}
+	return "[" + opt.label("preview.image") + ": " + alt + "]"
+}
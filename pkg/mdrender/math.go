@@ -0,0 +1,57 @@
+package mdrender
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ---- Формулы ----
+//
+// $…$ внутри строки и $$…$$ (в одной строке или блоком между строками
+// из одного $$) показываются стилем Styles.Math, без разбора
+// разметки: _ и * в формулах — индексы и умножение, а не курсив.
+// Правила как в pandoc: после открывающего $ и перед закрывающим нет
+// пробела, за закрывающим не идёт цифра ($5 и $10 — не формула), \$ —
+// обычный знак доллара.
+
+// Строка-граница блока формулы
+func isMathFence(line string) bool {
+	return strings.TrimSpace(line) == "$$"
+}
+
+// Формула, начинающаяся с runes[i]: границы содержимого [from, to) и
+// индекс последней руны закрывающего разделителя
+func mathSpan(runes []rune, i int) (from, to, end int, ok bool) {
+	if runes[i] != '$' || (i > 0 && runes[i-1] == '\\') {
+		return 0, 0, 0, false
+	}
+	// $$…$$
+	if i+1 < len(runes) && runes[i+1] == '$' {
+		for j := i + 2; j+1 < len(runes); j++ {
+			if runes[j] == '$' && runes[j+1] == '$' && runes[j-1] != '\\' {
+				if j == i+2 {
+					return 0, 0, 0, false
+				}
+				return i + 2, j, j + 1, true
+			}
+		}
+		return 0, 0, 0, false
+	}
+	// $…$
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+		return 0, 0, 0, false
+	}
+	for j := i + 1; j < len(runes); j++ {
+		if runes[j] != '$' || runes[j-1] == '\\' {
+			continue
+		}
+		if unicode.IsSpace(runes[j-1]) {
+			continue
+		}
+		if j+1 < len(runes) && unicode.IsDigit(runes[j+1]) {
+			continue
+		}
+		return i + 1, j, j, true
+	}
+	return 0, 0, 0, false
+}
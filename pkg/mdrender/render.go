@@ -0,0 +1,368 @@
+// Package mdrender — разбор Markdown в экранные строки для терминала:
+// заголовки, списки, цитаты и выноски, код, ссылки, формулы, HTML-теги
+// и сущности, типографика. Каждая ячейка помнит исходную руну, так что
+// поиск и прокрутку можно вести по тексту документа. Вывода на экран в
+// пакете нет: строки рисует вызывающий.
+package mdrender
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/editor"
+)
+
+// ---- Экранные строки ----
+//
+// Документ разбирается в строки с готовыми стилями, у каждой — номер
+// исходной строки. Одна исходная строка может дать несколько экранных
+// (<br>, перенос по ширине — см. Wrap), поэтому прокрутку удобно вести
+// по экранным строкам, а позицию между режимами переводить через Src.
+
+// Cell — ячейка экранной строки: графема, её стиль и индекс руны в
+// исходной строке, из которой она получилась (-1 — добавлена при разборе)
+type Cell struct {
+	Rune  rune
+	Comb  []rune
+	Style tcell.Style
+	Width int
+	Pos   int
+}
+
+// Line — экранная строка
+type Line struct {
+	Src    int // исходная строка
+	Cells  []Cell
+	Fill   tcell.Style // фон строки на всю ширину (выноски)
+	Filled bool
+}
+
+// Styles — стили элементов документа
+type Styles struct {
+	Text tcell.Style // обычный текст (foreground без фона)
+
+	H1, H2, H3, InlineCode, CodeBlock, Link, ListMarker, Blockquote, Math tcell.Style
+	// выноски по типу (см. callout.go)
+	Callout map[string]CalloutStyles
+}
+
+// Options — настройки разбора
+type Options struct {
+	Typography bool // тире, кавычки и многоточие (см. typography.go)
+	// Label переводит подписи: callout.<тип> и preview.image; nil — по-английски
+	Label func(key string) string
+}
+
+// Подписи по умолчанию
+var defaultLabels = map[string]string{
+	"callout.note":      "Note",
+	"callout.tip":       "Tip",
+	"callout.important": "Important",
+	"callout.warning":   "Warning",
+	"callout.caution":   "Caution",
+	"preview.image":     "image",
+}
+
+func (o *Options) label(key string) string {
+	if o.Label != nil {
+		return o.Label(key)
+	}
+	return defaultLabels[key]
+}
+
+var (
+	// пункт списка: -, +, * или N.
+	listRe = regexp.MustCompile(`^\s*([-+*]|\d+\.)\s+`)
+	// горизонтальная линия
+	hrRe = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+)
+
+// Добавить текст в строку; pos — исходные индексы рун (nil — нет)
+func (l *Line) put(runes []rune, pos []int, style tcell.Style) {
+	for _, g := range editor.Graphemes(runes) {
+		p := -1
+		if pos != nil {
+			p = pos[g.Start]
+		}
+		l.Cells = append(l.Cells, Cell{runes[g.Start], runes[g.Start+1 : g.Start+g.N], style, g.Width, p})
+	}
+}
+
+// n раз один и тот же исходный индекс (замена вроде &amp; или <img>)
+func samePos(p, n int) []int {
+	pos := make([]int, n)
+	for k := range pos {
+		pos[k] = p
+	}
+	return pos
+}
+
+// Цвет фона стиля
+func bgOf(style tcell.Style) tcell.Color {
+	_, bg, _ := style.Decompose()
+	return bg
+}
+
+// Render — разобрать документ в экранные строки (по одной на исходную
+// строку и на каждый <br>); st — готовые стили, opt — настройки
+func Render(content string, st *Styles, opt Options) []Line {
+	mdStyles := *st
+	md := mdStyles
+	text := st.Text
+
+	inCodeBlock := false
+	inMathBlock := false
+	callout := "" // тип выноски, в теле которой строка (см. callout.go)
+	var out []Line
+	for i, line := range strings.Split(content, "\n") {
+		trim := strings.TrimRight(line, "\r\n")
+
+		// fence handling
+		if strings.HasPrefix(trim, "```") {
+			inCodeBlock = !inCodeBlock
+			// optionally show language after ```
+			out = append(out, Line{Src: i})
+			continue
+		}
+
+		// блок формулы между строками $$ (см. math.go)
+		if !inCodeBlock && isMathFence(trim) {
+			inMathBlock = !inMathBlock
+			out = append(out, Line{Src: i})
+			continue
+		}
+		if inMathBlock {
+			l := Line{Src: i}
+			runes := []rune(trim)
+			pos := make([]int, len(runes))
+			for k := range pos {
+				pos[k] = k
+			}
+			l.put(runes, pos, md.Math)
+			out = append(out, l)
+			continue
+		}
+
+		// default base style: используем общий foreground
+		baseStyle := text
+		cur := Line{Src: i}
+		off := 0 // сколько рун срезано слева от исходной строки
+
+		// выноска > [!NOTE]: заголовок, затем тонированное тело
+		md = mdStyles
+		if kind, title, ok := parseCallout(trim); ok && !inCodeBlock {
+			callout = kind
+			out = append(out, calloutTitleLine(i, mdStyles.Callout[kind], kind, title, &opt))
+			continue
+		}
+		if callout != "" {
+			if body, ok := calloutBody(trim); ok && !inCodeBlock {
+				cs := mdStyles.Callout[callout]
+				cur.Fill, cur.Filled = cs.Body, true
+				off += len([]rune(trim)) - len([]rune(body))
+				trim = body
+				baseStyle = cs.Body
+				md = mdStyles.onBackground(bgOf(cs.Body))
+			} else {
+				callout = ""
+			}
+		}
+
+		// decide line-level style and possibly trim prefixes
+		if inCodeBlock {
+			baseStyle = md.CodeBlock
+		} else if callout != "" {
+			// тело выноски: стиль уже выбран
+		} else if strings.HasPrefix(trim, "# ") {
+			trim = strings.TrimPrefix(trim, "# ")
+			off += 2
+			baseStyle = md.H1
+		} else if strings.HasPrefix(trim, "## ") {
+			trim = strings.TrimPrefix(trim, "## ")
+			off += 3
+			baseStyle = md.H2
+		} else if strings.HasPrefix(trim, "### ") {
+			trim = strings.TrimPrefix(trim, "### ")
+			off += 4
+			baseStyle = md.H3
+		} else if strings.HasPrefix(strings.TrimLeft(trim, " "), "> ") {
+			// blockquote, keep indentation
+			// remove one leading '>' if present after spaces
+			idx := strings.Index(trim, "> ")
+			if idx >= 0 {
+				rest := strings.TrimLeftFunc(trim[idx+2:], unicode.IsSpace)
+				off += len([]rune(trim)) - len([]rune(rest))
+				trim = strings.TrimRightFunc(rest, unicode.IsSpace)
+			}
+			baseStyle = md.Blockquote
+		} else if listRe.MatchString(trim) {
+			// don't strip marker completely; will color marker when rendering
+			baseStyle = md.ListMarker
+		}
+
+		// исходный индекс каждой руны
+		var pos []int
+		for k := range []rune(trim) {
+			pos = append(pos, off+k)
+		}
+		// тире, кавычки и многоточие (см. typography.go)
+		if opt.Typography && !inCodeBlock {
+			var from []int
+			trim, from = smartypants(trim)
+			for k, f := range from {
+				from[k] = pos[f]
+			}
+			pos = from
+		}
+
+		// render line rune-by-rune with inline parsing for `code`, *em* and links
+		runes := []rune(trim)
+		spans := editor.GraphemeSpans(runes)
+		inInlineCode := false
+		inEmphasis := false
+		escaped := false
+		var htmlBold, htmlItalic, htmlUnderline, htmlCode bool
+
+		for idx := 0; idx < len(runes); idx++ {
+			r := runes[idx]
+			esc := escaped
+			escaped = false
+
+			// \* — буквальный знак без разметки (см. escape.go)
+			if !esc && !inInlineCode && !inCodeBlock && isMDEscape(runes, idx) {
+				escaped = true
+				continue
+			}
+
+			// handle inline code delimiter `
+			if r == '`' && !inCodeBlock && !esc {
+				inInlineCode = !inInlineCode
+				continue // don't render the backtick itself
+			}
+
+			// теги HTML: <br>, <img>, <b>… (см. inlinehtml.go)
+			if r == '<' && !inInlineCode && !inCodeBlock && !esc {
+				if tag, ok := parseHTMLTag(runes, idx); ok {
+					at := pos[idx]
+					idx += tag.n - 1
+					switch tag.name {
+					case "br":
+						// продолжение — новой экранной строкой той же исходной
+						out = append(out, cur)
+						cur = Line{Src: i, Fill: cur.Fill, Filled: cur.Filled}
+					case "img":
+						text := []rune(tag.imagePlaceholder(&opt))
+						cur.put(text, samePos(at, len(text)), md.Link)
+					case "summary":
+						if !tag.close {
+							cur.put([]rune("▸ "), samePos(at, 2), baseStyle)
+						}
+					case "b", "strong":
+						htmlBold = !tag.close
+					case "i", "em":
+						htmlItalic = !tag.close
+					case "u", "ins":
+						htmlUnderline = !tag.close
+					case "code", "kbd", "samp", "tt":
+						htmlCode = !tag.close
+					}
+					continue
+				}
+			}
+
+			// формула $…$ или $$…$$: целиком, без разбора разметки
+			if r == '$' && !inInlineCode && !inCodeBlock && !esc {
+				if from, to, end, ok := mathSpan(runes, idx); ok {
+					cur.put(runes[from:to], pos[from:to], md.Math)
+					idx = end
+					continue
+				}
+			}
+
+			// handle emphasis markers simple: *text* or _text_
+			if (r == '*' || r == '_') && !inInlineCode && !esc {
+				prevIsSpace := idx == 0 || runes[idx-1] == ' ' || runes[idx-1] == '\t'
+				nextIsSpace := idx+1 >= len(runes) || runes[idx+1] == ' ' || runes[idx+1] == '\t'
+				if !prevIsSpace && !nextIsSpace {
+					inEmphasis = !inEmphasis
+					continue // don't render marker
+				}
+			}
+
+			// handle links [text](url)
+			if r == '[' && !inInlineCode && !esc {
+				// find closing ] and opening ( and closing )
+				closeIdx := -1
+				for j := idx + 1; j < len(runes); j++ {
+					if runes[j] == ']' {
+						closeIdx = j
+						break
+					}
+				}
+				if closeIdx != -1 && closeIdx+1 < len(runes) && runes[closeIdx+1] == '(' {
+					// find closing )
+					parenClose := -1
+					for j := closeIdx + 2; j < len(runes); j++ {
+						if runes[j] == ')' {
+							parenClose = j
+							break
+						}
+					}
+					if parenClose != -1 {
+						// render the text between idx+1 .. closeIdx-1 as link text
+						cur.put(runes[idx+1:closeIdx], pos[idx+1:closeIdx], md.Link)
+						// advance idx to parenClose (skip url)
+						idx = parenClose
+						continue
+					}
+				}
+			}
+
+			// choose style for this rune
+			curStyle := baseStyle
+			if inInlineCode || htmlCode {
+				curStyle = md.InlineCode
+			} else if inEmphasis {
+				curStyle = curStyle.Bold(true)
+			}
+			if htmlBold {
+				curStyle = curStyle.Bold(true)
+			}
+			if htmlItalic {
+				curStyle = curStyle.Italic(true)
+			}
+			if htmlUnderline {
+				curStyle = curStyle.Underline(true)
+			}
+
+			// special: color list marker differently if at line start
+			if (r == '-' || r == '+' || r == '*') && idx == 0 && listRe.MatchString(string(runes)) {
+				curStyle = md.ListMarker
+			}
+
+			// &amp;, &mdash;, &nbsp;… — символом
+			if r == '&' && !inInlineCode && !inCodeBlock && !esc {
+				if s, n := mdEntity(runes, idx); n > 0 {
+					text := []rune(s)
+					cur.put(text, samePos(pos[idx], len(text)), curStyle)
+					idx += n - 1
+					continue
+				}
+			}
+
+			// графема целиком: буква с диакритикой, эмодзи с ZWJ, флаг
+			g := spans[idx]
+			if g.N == 0 {
+				g = editor.Grapheme{Start: idx, N: 1, Width: runewidth.RuneWidth(r)}
+			}
+			cur.Cells = append(cur.Cells, Cell{r, runes[idx+1 : idx+g.N], curStyle, g.Width, pos[idx]})
+			idx += g.N - 1
+		}
+		out = append(out, cur)
+	}
+	return out
+}
@@ -0,0 +1,185 @@
+package mdrender
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Стили, различимые в проверках
+func testStyles() *Styles {
+	c := func(n int) tcell.Style { return tcell.StyleDefault.Foreground(tcell.PaletteColor(n)) }
+	return &Styles{
+		Text: c(1), H1: c(2), H2: c(3), H3: c(4), InlineCode: c(5), CodeBlock: c(6),
+		Link: c(7), ListMarker: c(8), Blockquote: c(9), Math: c(10),
+		Callout: map[string]CalloutStyles{
+			"warning": {Title: c(11), Body: c(12).Background(tcell.PaletteColor(13))},
+		},
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"# Title", []string{"Title"}},
+		{"## Sub\n### Third", []string{"Sub", "Third"}},
+		{"> quoted", []string{"quoted"}},
+		{"- item", []string{"- item"}},
+		{"use `code` here", []string{"use code here"}},
+		{"a [link](http://x) b", []string{"a link b"}},
+		{"intra*word*emphasis", []string{"intrawordemphasis"}},
+		{"a * b * c", []string{"a * b * c"}},
+		{`\*not em\*`, []string{"*not em*"}},
+		{"fish &amp; chips &mdash; &bogus;", []string{"fish & chips — &bogus;"}},
+		{"one<br>two", []string{"one", "two"}},
+		{"<b>bold</b> <!-- note --> <details>x</details>", []string{"bold  x"}},
+		{`<img src="a.png">`, []string{"[image: a.png]"}},
+		{"```\n# not a heading\n```", []string{"", "# not a heading", ""}},
+		{"$a_1 * b_2$", []string{"a_1 * b_2"}},
+		{"$$\nx_1\n$$", []string{"", "x_1", ""}},
+		{"costs $5 and $10", []string{"costs $5 and $10"}},
+		{"> [!WARNING]\n> careful\nafter", []string{" ⚠ Warning", "careful", "after"}},
+		{"> [!tip] Own title", []string{" ★ Own title"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, l := range Render(tt.in, testStyles(), Options{}) {
+			got = append(got, lineText(l))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Render(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderStyles(t *testing.T) {
+	st := testStyles()
+	tests := []struct {
+		in    string
+		cell  int // индекс ячейки первой строки
+		style tcell.Style
+	}{
+		{"# Title", 0, st.H1},
+		{"plain", 0, st.Text},
+		{"- item", 0, st.ListMarker},
+		{"a `b`", 2, st.InlineCode},
+		{"a [b](c)", 2, st.Link},
+		{"x $y$", 2, st.Math},
+		{"x*yz*", 1, st.Text.Bold(true)},
+		{"<i>x</i>", 0, st.Text.Italic(true)},
+		{"> [!WARNING]", 1, st.Callout["warning"].Title},
+	}
+	for _, tt := range tests {
+		lines := Render(tt.in, st, Options{})
+		if got := lines[0].Cells[tt.cell].Style; got != tt.style {
+			t.Errorf("Render(%q) cell %d: style %v, want %v", tt.in, tt.cell, got, tt.style)
+		}
+	}
+
+	// тело выноски: фон на всю строку, ссылки на том же фоне
+	lines := Render("> [!WARNING]\n> see [x](y)", st, Options{})
+	body := lines[1]
+	_, bg, _ := st.Callout["warning"].Body.Decompose()
+	if !body.Filled || body.Fill != st.Callout["warning"].Body {
+		t.Errorf("callout body fill = %v %v", body.Filled, body.Fill)
+	}
+	if _, got, _ := body.Cells[len(body.Cells)-1].Style.Decompose(); got != bg {
+		t.Errorf("link in callout body: background %v, want %v", got, bg)
+	}
+}
+
+func TestRenderPositions(t *testing.T) {
+	tests := []struct {
+		in   string
+		typo bool
+		want []int
+	}{
+		{"ab", false, []int{0, 1}},
+		{"# ab", false, []int{2, 3}},
+		{"> ab", false, []int{2, 3}},
+		{"a `b` c", false, []int{0, 1, 3, 5, 6}},
+		{"[ab](u)", false, []int{1, 2}},
+		{"x&amp;y", false, []int{0, 1, 6}},
+		{"a -- b", true, []int{0, 1, 2, 4, 5}},
+		{"<b>x</b>", false, []int{3}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, c := range Render(tt.in, testStyles(), Options{Typography: tt.typo})[0].Cells {
+			got = append(got, c.Pos)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Render(%q) positions = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderSources(t *testing.T) {
+	var got []int
+	for _, l := range Render("a\nb<br>c\n\nd", testStyles(), Options{}) {
+		got = append(got, l.Src)
+	}
+	if want := []int{0, 1, 1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sources = %v, want %v", got, want)
+	}
+}
+
+func TestRenderLabels(t *testing.T) {
+	opt := Options{Label: func(key string) string { return strings.ToUpper(key) }}
+	lines := Render("> [!NOTE]\n<img>", testStyles(), opt)
+	if got := lineText(lines[0]); got != " ℹ CALLOUT.NOTE" {
+		t.Errorf("callout title = %q", got)
+	}
+	if got := lineText(lines[1]); got != "[PREVIEW.IMAGE]" {
+		t.Errorf("image placeholder = %q", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"one two three", 0, []string{"one two three"}},
+		{"one two three", 8, []string{"one two ", "three"}},
+		{"one two three", 7, []string{"one ", "two ", "three"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"- item with words", 10, []string{"- item ", "  with ", "  words"}},
+		{"12. long item text", 12, []string{"12. long ", "    item ", "    text"}},
+		{"  indented text here", 10, []string{"  indented", "  text ", "  here"}},
+		{"a  b", 2, []string{"a ", "b"}},
+		{"界界界", 4, []string{"界界", "界"}},
+	}
+	for _, tt := range tests {
+		rows := Render(tt.in, testStyles(), Options{})
+		var got []string
+		for _, l := range Wrap(rows, tt.width) {
+			got = append(got, lineText(l))
+			if tt.width > 0 && l.Width() > tt.width {
+				t.Errorf("Wrap(%q, %d): line %q is %d wide", tt.in, tt.width, lineText(l), l.Width())
+			}
+			if l.Src != 0 {
+				t.Errorf("Wrap(%q, %d): Src = %d", tt.in, tt.width, l.Src)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Wrap(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+// Текст экранной строки
+func lineText(l Line) string {
+	var s []rune
+	for _, c := range l.Cells {
+		s = append(s, c.Rune)
+		s = append(s, c.Comb...)
+	}
+	return string(s)
+}
@@ -0,0 +1,116 @@
+package mdrender
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ---- Типографика ----
+//
+// С Options.Typography предпросмотр показывает --- как длинное
+// тире, -- как короткое, ... как многоточие, а прямые кавычки — как
+// типографские (smartypants). Меняется только то, что на экране: текст
+// файла остаётся как есть. Код (`…` и блоки ```), адреса ссылок и
+// теги HTML не трогаются, как и формулы $…$ и строки-разделители (---,
+// строки таблиц |---|).
+
+// Строка-разделитель: линия или разметка колонок таблицы
+var typoRuleRe = regexp.MustCompile(`^\s*[-|: ]+$`)
+
+// Открывающая ли кавычка после руны prev (0 — начало строки)
+func opensQuote(prev rune) bool {
+	return prev == 0 || unicode.IsSpace(prev) || strings.ContainsRune("([{<-–—/“‘", prev)
+}
+
+// Типографские замены в строке Markdown. Второе значение — для каждой
+// руны результата индекс исходной руны (для поиска в предпросмотре).
+func smartypants(s string) (string, []int) {
+	runes := []rune(s)
+	var out []rune
+	var pos []int
+	keep := func(from, to int) {
+		for k := from; k < to; k++ {
+			out = append(out, runes[k])
+			pos = append(pos, k)
+		}
+	}
+	if typoRuleRe.MatchString(s) || hrRe.MatchString(strings.TrimSpace(s)) {
+		keep(0, len(runes))
+		return s, pos
+	}
+	var prev rune
+	inCode := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		start := i
+		next := func(k int) rune {
+			if i+k < len(runes) {
+				return runes[i+k]
+			}
+			return 0
+		}
+		switch {
+		case !inCode && isMDEscape(runes, i):
+			// \" и \` — как есть (см. escape.go)
+			keep(i, i+2)
+			prev = runes[i+1]
+			i++
+			continue
+		case r == '`':
+			inCode = !inCode
+		case inCode:
+		case r == '$':
+			// формула — как есть (см. math.go)
+			if _, _, end, ok := mathSpan(runes, i); ok {
+				keep(i, end+1)
+				prev = '$'
+				i = end
+				continue
+			}
+		case r == '<':
+			// тег HTML — как есть, кавычки в атрибутах не трогаем (см.
+			// inlinehtml.go); для кавычки после тега он прозрачен
+			if tag, ok := parseHTMLTag(runes, i); ok {
+				keep(i, i+tag.n)
+				i += tag.n - 1
+				continue
+			}
+		case r == ']' && next(1) == '(':
+			// адрес ссылки — как есть
+			end := i + 1
+			for end < len(runes) && runes[end] != ')' {
+				end++
+			}
+			if end < len(runes) {
+				keep(i, end+1)
+				prev = ')'
+				i = end
+				continue
+			}
+		case r == '-' && next(1) == '-' && next(2) == '-':
+			r = '—'
+			i += 2
+		case r == '-' && next(1) == '-':
+			r = '–'
+			i++
+		case r == '.' && next(1) == '.' && next(2) == '.':
+			r = '…'
+			i += 2
+		case r == '"':
+			r = '”'
+			if opensQuote(prev) {
+				r = '“'
+			}
+		case r == '\'':
+			r = '’' // апостроф и закрывающая
+			if opensQuote(prev) {
+				r = '‘'
+			}
+		}
+		out = append(out, r)
+		pos = append(pos, start)
+		prev = r
+	}
+	return string(out), pos
+}
@@ -0,0 +1,41 @@
+package mdrender
+
+import "testing"
+
+func TestSmartypants(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`a -- b --- c`, `a – b — c`},
+		{`wait...`, `wait…`},
+		{`"quoted" and 'single'`, `“quoted” and ‘single’`},
+		{`it's`, `it’s`},
+		{"`\"code\"` \"text\"", "`\"code\"` “text”"},
+		{`[a "link"](http://x/"y")`, `[a “link”](http://x/"y")`},
+		{`$a--b$ --`, `$a--b$ –`},
+		{`\"not curly\"`, `\"not curly\"`},
+		{`<img alt="pic"> "x"`, `<img alt="pic"> “x”`},
+		{`<a href='u'>"x"</a>`, `<a href='u'>“x”</a>`},
+		{`a < "b"`, `a < “b”`},
+		{`---`, `---`},
+		{`|---|:--|`, `|---|:--|`},
+	}
+	for _, tt := range tests {
+		got, pos := smartypants(tt.in)
+		if got != tt.want {
+			t.Errorf("smartypants(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if n := len([]rune(got)); len(pos) != n {
+			t.Errorf("smartypants(%q): %d positions for %d runes", tt.in, len(pos), n)
+		}
+	}
+}
+
+func TestRenderImageTypography(t *testing.T) {
+	for _, typo := range []bool{false, true} {
+		lines := Render(`<img alt="pic">`, &Styles{}, Options{Typography: typo})
+		if got := lineText(lines[0]); got != "[image: pic]" {
+			t.Errorf("typography=%v: got %q, want %q", typo, got, "[image: pic]")
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mdrender
+
+import "unicode"
+
+// ---- Перенос строк ----
+//
+// С preview.wrap = true длинные строки предпросмотра переносятся по
+// словам по ширине окна; слово длиннее окна режется. Продолжение пункта
+// списка выравнивается по тексту после маркера. Перенос пересчитывается
+// при изменении размеров окна (терминал, разделение, панель файлов), а
+// верхняя строка окна остаётся той же.
+
+// Wrap — перенести строки по ширине width (0 — без переноса)
+func Wrap(rows []Line, width int) []Line {
+	if width <= 0 {
+		return rows
+	}
+	out := make([]Line, 0, len(rows))
+	for _, l := range rows {
+		out = append(out, wrapLine(l, width)...)
+	}
+	return out
+}
+
+// Width — ширина строки в колонках
+func (l Line) Width() int {
+	w := 0
+	for _, c := range l.Cells {
+		w += c.Width
+	}
+	return w
+}
+
+// Отступ продолжения: пробелы в начале и маркер списка с пробелом
+func hangIndent(cells []Cell) int {
+	k := 0
+	for k < len(cells) && cells[k].Rune == ' ' {
+		k++
+	}
+	m := k
+	switch {
+	case m < len(cells) && (cells[m].Rune == '-' || cells[m].Rune == '*' || cells[m].Rune == '+'):
+		m++
+	case m < len(cells) && unicode.IsDigit(cells[m].Rune):
+		for m < len(cells) && unicode.IsDigit(cells[m].Rune) {
+			m++
+		}
+		if m < len(cells) && (cells[m].Rune == '.' || cells[m].Rune == ')') {
+			m++
+		} else {
+			return k
+		}
+	default:
+		return k
+	}
+	if m < len(cells) && cells[m].Rune == ' ' {
+		return m + 1
+	}
+	return k
+}
+
+// Перенести одну строку
+func wrapLine(l Line, width int) []Line {
+	if l.Width() <= width {
+		return []Line{l}
+	}
+	indent := hangIndent(l.Cells)
+	if indent > width/2 {
+		indent = 0
+	}
+	var pad []Cell
+	for k := 0; k < indent; k++ {
+		pad = append(pad, Cell{Rune: ' ', Style: l.Cells[k].Style, Width: 1, Pos: -1})
+	}
+
+	var out []Line
+	cells := l.Cells
+	for first := true; len(cells) > 0; first = false {
+		avail := width
+		if !first {
+			avail -= indent
+		}
+		w, cut, space := 0, len(cells), -1
+		for k, c := range cells {
+			if w+c.Width > avail {
+				cut = k
+				break
+			}
+			// пробел после слова, а не отступ в начале строки
+			if c.Rune == ' ' && k > 0 && cells[k-1].Rune != ' ' {
+				space = k
+			}
+			w += c.Width
+		}
+		if cut < len(cells) && space > 0 {
+			cut = space + 1 // перенос после пробела
+		}
+		cut = max(cut, 1) // графема шире окна — всё равно одна в строке
+		row := Line{Src: l.Src, Fill: l.Fill, Filled: l.Filled}
+		if !first {
+			row.Cells = append(row.Cells, pad...)
+		}
+		row.Cells = append(row.Cells, cells[:cut]...)
+		out = append(out, row)
+		cells = cells[cut:]
+		// пробелы в начале продолжения не нужны
+		for len(cells) > 0 && cells[0].Rune == ' ' {
+			cells = cells[1:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	plainImageRe = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	plainLinkRe  = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+)
+
+// ---- Копирование предпросмотра как простого текста (Alt+c) ----
+//
+// Разметка убирается, списки и отступы сохраняются, ссылки превращаются
+// в "текст <адрес>". Результат кладётся в буфер обмена — удобно для писем.
+
+// Строчная разметка → простой текст
+func inlinePlain(s string) string {
+	parts := strings.Split(s, "`")
+	var b strings.Builder
+	for i, p := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			b.WriteString(p) // содержимое `кода` как есть
+			continue
+		}
+		if i%2 == 1 {
+			b.WriteString("`")
+		}
+		p = plainImageRe.ReplaceAllString(p, "$1 <$2>")
+		p = plainLinkRe.ReplaceAllString(p, "$1 <$2>")
+		p = mdEmRe.ReplaceAllString(p, "$1$2")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// Markdown → простой текст
+func renderPlain(md string) string {
+	var out []string
+	inCode := false
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out = append(out, "    "+line)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(trim, "#"):
+			h := strings.TrimLeft(trim, "#")
+			if strings.HasPrefix(h, " ") {
+				out = append(out, inlinePlain(strings.TrimSpace(h)))
+			} else {
+				out = append(out, inlinePlain(line))
+			}
+		case mdHRRe.MatchString(trim):
+			out = append(out, strings.Repeat("-", 20))
+		default:
+			// отступы, маркеры списков и "> " цитат остаются как есть
+			out = append(out, inlinePlain(line))
+		}
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+}
+
+// Скопировать текст в буфер обмена
+func (a *App) copyToClipboard(text string) {
+	a.clipboard = text
+	a.rememberClip(text)
+	// OSC 52: работает, если терминал поддерживает
+	a.screen.SetClipboard([]byte(text))
+}
+
+// Скопировать отрендеренный документ
+func (a *App) copyPlainText() {
+	text := renderPlain(a.view.buf.Content)
+	a.copyToClipboard(text)
+	a.notify(levelSuccess, tr("plain.copied"), countWords(text))
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// Внешний редактор, если не задан ни в настройках, ни в окружении
+const defaultExternalEditor = "vi"
+
+// Команда для строки оболочки (Alt+!, хуки, плагины)
+func shellCommand(ctx context.Context, cmd string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", cmd)
+}
+
+// Строка в одинарных кавычках для sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Корни дисков: на этой системе один корень
+func driveRoots() []string {
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	for _, path := range []string{
+		"/tmp/note.md",
+		"/tmp/my notes/a b.md",
+		"/tmp/it's.md",
+		"/tmp/$HOME `id` \"q\".md",
+	} {
+		res := runShellInput("", "printf %s "+shellQuote(path), nil, nil)
+		if res.err != nil || res.stdout != path {
+			t.Errorf("shellQuote(%q): sh printed %q (%v)", path, res.stdout, res.err)
+		}
+	}
+}
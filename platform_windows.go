@@ -0,0 +1,42 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Внешний редактор, если не задан ни в настройках, ни в окружении
+const defaultExternalEditor = "notepad"
+
+// Команда для строки оболочки (Alt+!, хуки, плагины)
+func shellCommand(ctx context.Context, cmd string) *exec.Cmd {
+	c := exec.CommandContext(ctx, "cmd")
+	// строка целиком, как есть: экранирование аргументов Go (\") cmd
+	// не понимает. С /S cmd снимает только внешние кавычки.
+	c.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + cmd + `"`}
+	return c
+}
+
+// Строка в двойных кавычках для cmd. % в кавычках всё равно раскрывается
+// как переменная, поэтому выносится наружу с ^; самих кавычек в именах
+// файлов Windows не бывает.
+func shellQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, "%", `"^%"`) + `"`
+}
+
+// Корни дисков: C:\, D:\ ...
+func driveRoots() []string {
+	var roots []string
+	for d := 'A'; d <= 'Z'; d++ {
+		root := string(d) + `:\`
+		if _, err := os.Stat(root); err == nil {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
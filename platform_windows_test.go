@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`C:\notes\a.md`, `"C:\notes\a.md"`},
+		{`C:\my notes\a & b.md`, `"C:\my notes\a & b.md"`},
+		{`C:\100%\%PATH%.md`, `"C:\100"^%"\"^%"PATH"^%".md"`},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.path); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ---- Плагины ----
+//
+// Плагин — файл *.toml или сценарий *.lua (см. lua.go) в
+// ~/.config/eddy/plugins/. Он добавляет команды в реестр (commands.go),
+// привязывает к ним клавиши и подписывается на события (открытие,
+// сохранение файла, перезагрузка темы; см. hooks.go). В *.toml команда
+// плагина — строка для sh -c, а API — стандартный ввод, вывод и
+// переменные окружения:
+//
+//	EDDY_FILE, EDDY_DIR      — файл активного окна и папка панели
+//	EDDY_LINE, EDDY_COLUMN   — позиция курсора (с 1)
+//	EDDY_EVENT               — событие для обработчиков (open, save, theme-reload)
+//
+// Пример:
+//
+//	[[command]]
+//	name   = "wc.words"
+//	title  = "Count words"
+//	key    = "Alt+W"
+//	run    = "wc -w"
+//	input  = "buffer"   # buffer, selection или none
+//	output = "notify"   # replace, insert, show, notify или none
+//
+//	[[hook]]
+//	on  = "save"
+//	run = "git add \"$EDDY_FILE\""
+//
+//	[keys]
+//	"Ctrl+E" = "editor.external"
+
+// Файл плагина
+type pluginFile struct {
+	Command []pluginCommand   `toml:"command"`
+	Hook    []pluginHook      `toml:"hook"`
+	Keys    map[string]string `toml:"keys"`
+}
+
+type pluginCommand struct {
+	Name   string `toml:"name"`
+	Title  string `toml:"title"`
+	Key    string `toml:"key"`
+	Run    string `toml:"run"`
+	Input  string `toml:"input"`
+	Output string `toml:"output"`
+}
+
+type pluginHook struct {
+	On  string `toml:"on"`
+	Run string `toml:"run"`
+}
+
+// Папка плагинов
+func pluginsDir() string {
+	return filepath.Join(configDir(), "plugins")
+}
+
+// Загрузить все плагины; ошибки показываются сообщениями
+func (a *App) loadPlugins() {
+	paths, _ := filepath.Glob(filepath.Join(pluginsDir(), "*.toml"))
+	scripts, _ := filepath.Glob(filepath.Join(pluginsDir(), "*.lua"))
+	paths = append(paths, scripts...)
+	sort.Strings(paths)
+	for _, p := range paths {
+		load := a.loadPlugin
+		if filepath.Ext(p) == ".lua" {
+			load = a.loadLuaPlugin
+		}
+		if err := load(p); err != nil {
+			a.notify(levelError, tr("plugin.failed"), filepath.Base(p), err)
+			continue
+		}
+		a.plugins = append(a.plugins, p)
+	}
+}
+
+func (a *App) loadPlugin(path string) error {
+	var pf pluginFile
+	if _, err := toml.DecodeFile(path, &pf); err != nil {
+		return err
+	}
+	plugin := strings.TrimSuffix(filepath.Base(path), ".toml")
+	for _, pc := range pf.Command {
+		if pc.Name == "" || pc.Run == "" {
+			return fmt.Errorf("command %q: name and run are required", pc.Name)
+		}
+		if !oneOf(pc.Input, "", "buffer", "selection", "none") {
+			return fmt.Errorf("command %s: unknown input %q", pc.Name, pc.Input)
+		}
+		if !oneOf(pc.Output, "", "replace", "insert", "show", "notify", "none") {
+			return fmt.Errorf("command %s: unknown output %q", pc.Name, pc.Output)
+		}
+		c := &command{name: pc.Name, context: "help.ctx.plugins", desc: pc.Title, run: func(a *App) { a.runPluginCommand(pc) }}
+		if c.desc == "" {
+			c.desc = pc.Name
+		}
+		if pc.Key != "" {
+			c.keys = []string{pc.Key}
+		}
+		a.registerCommand(c)
+	}
+	for _, h := range pf.Hook {
+		if !oneOf(h.On, eventOpen, eventSave, eventThemeReload) || h.Run == "" {
+			return fmt.Errorf("hook %q: unknown event or empty run", h.On)
+		}
+		if a.hooks == nil {
+			a.hooks = map[string][]hook{}
+		}
+		a.hooks[h.On] = append(a.hooks[h.On], hook{source: plugin, run: h.Run})
+	}
+	for key, name := range pf.Keys {
+		if err := a.bindKey(key, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func oneOf(s string, values ...string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Переменные окружения для команд плагинов
+func (a *App) pluginEnv(event string) []string {
+	return []string{
+		"EDDY_FILE=" + a.view.buf.path,
+		"EDDY_DIR=" + a.currentDir,
+		"EDDY_LINE=" + strconv.Itoa(a.view.editY+1),
+		"EDDY_COLUMN=" + strconv.Itoa(a.view.editX+1),
+		"EDDY_EVENT=" + event,
+	}
+}
+
+// Выполнить команду плагина в фоне и применить вывод к тому же буферу
+func (a *App) runPluginCommand(pc pluginCommand) {
+	output := pc.Output
+	if output == "" {
+		output = "show"
+	}
+	if (output == "replace" || output == "insert") && (a.view.mode != "edit" || !a.checkWritable()) {
+		return
+	}
+	buf := a.view.buf
+	before := buf.Content
+	var input io.Reader
+	text := ""
+	switch pc.Input {
+	case "", "buffer":
+		text = buf.Content
+		input = strings.NewReader(text)
+	case "selection":
+		text = a.view.selectedText()
+		input = strings.NewReader(text)
+	}
+	env := a.pluginEnv("")
+	dir := a.currentDir
+	a.debugf("plugin: %s: %s", pc.Name, pc.Run)
+	go func() {
+		res := runShellInput(dir, pc.Run, env, input)
+		a.post(func() { a.applyPluginOutput(pc, output, buf, before, text, res) })
+	}()
+}
+
+// text — то, что было подано на stdin
+func (a *App) applyPluginOutput(pc pluginCommand, output string, buf *buffer, before, text string, res shellResult) {
+	if res.err != nil || res.exitCode != 0 {
+		msg := firstLine(res.output)
+		if res.err != nil {
+			msg = res.err.Error()
+		} else if msg == "" {
+			msg = trf("shell.exit_code", res.exitCode)
+		}
+		a.notify(levelError, tr("plugin.command_failed"), pc.Name, msg)
+		return
+	}
+	switch output {
+	case "show":
+		title := pc.Title
+		if title == "" {
+			title = pc.Name
+		}
+		a.showText(title, res.output)
+		return
+	case "notify":
+		a.notify(levelInfo, "%s", firstLine(res.stdout))
+		return
+	case "none":
+		return
+	}
+	// правка — только если за время работы команды текст не менялся
+	if a.view.buf != buf || buf.Content != before {
+		a.notify(levelWarning, tr("plugin.changed"), pc.Name)
+		return
+	}
+	// шаг отмены запишет основной цикл (не через a.repeatable: повтор
+	// вставил бы старый вывод)
+	out := res.stdout
+	if output == "replace" && strings.HasSuffix(text, "\n") {
+		out += "\n" // runShell отрезает последний перевод строки
+	}
+	switch {
+	case output == "insert" || pc.Input == "selection":
+		a.deleteSelection()
+		a.insertText(out)
+	default:
+		y, x := a.view.editY, a.view.editX
+		a.view.clearSelection()
+		a.setLines(strings.Split(out, "\n"))
+		a.view.editY, a.view.editX = y, x
+		a.clampCursor()
+		a.ensureCursorVisible()
+	}
+}
+
+// Привязать клавишу к существующей команде
+func (a *App) bindKey(key, name string) error {
+	c := a.command(name)
+	if c == nil {
+		return fmt.Errorf("unknown command %q", name)
+	}
+	c.keys = append(c.keys, key)
+	a.bindings[key] = append(a.bindings[key], c)
+	return nil
+}
+
+// Список загруженных плагинов и их команд (из палитры команд)
+func (a *App) showPlugins() {
+	if len(a.plugins) == 0 {
+		a.notify(levelInfo, tr("plugin.none"), pluginsDir())
+		return
+	}
+	var b strings.Builder
+	for _, p := range a.plugins {
+		b.WriteString(p + "\n")
+	}
+	b.WriteString("\n")
+	for _, c := range a.commands {
+		if c.context != "help.ctx.plugins" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %-24s %-10s %s\n", c.name, strings.Join(c.keys, " "), c.desc)
+	}
+	a.showText(tr("plugin.title"), strings.TrimRight(b.String(), "\n"))
+}
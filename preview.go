@@ -0,0 +1,96 @@
+package main
+
+import (
+	"github.com/StasKrav/eddy_tcell/internal/preview"
+	"github.com/StasKrav/eddy_tcell/pkg/mdrender"
+)
+
+// ---- Предпросмотр окна ----
+//
+// Экранные строки собирает и кэширует internal/preview; здесь — то,
+// что зависит от окна: ширина переноса, настройки, прокрутка previewY
+// и отрисовка с подсветкой поиска.
+
+// Экранные строки предпросмотра окна (из кэша, если ничего не менялось).
+// При пересборке верхняя строка окна остаётся той же исходной.
+func (a *App) previewLines(v *editorView) []mdrender.Line {
+	styles := a.getStyles()
+	width := 0
+	if a.config.Preview.Wrap {
+		_, _, width, _ = v.textArea()
+	}
+	opt := mdrender.Options{Typography: a.config.Preview.Typography, Label: tr}
+	lines, top := v.preview.Lines(v.buf.Content, &styles.Markdown, opt, width, v.previewY)
+	v.previewY = top
+	return lines
+}
+
+// Исходная строка верхней экранной строки окна
+func (a *App) previewTopSource(v *editorView) int {
+	lines := a.previewLines(v)
+	if len(lines) == 0 {
+		return 0
+	}
+	return lines[min(v.previewY, len(lines)-1)].Src
+}
+
+// Прокрутить предпросмотр так, чтобы сверху была исходная строка src
+func (a *App) scrollPreviewTo(v *editorView, src int) {
+	v.previewY = preview.RowOf(a.previewLines(v), src)
+}
+
+// Прокрутить предпросмотр на delta экранных строк
+func (a *App) scrollPreview(v *editorView, delta int) {
+	v.previewY += delta
+	a.clampPreview(v)
+}
+
+// Не уводить прокрутку за конец документа
+func (a *App) clampPreview(v *editorView) {
+	v.previewY = min(v.previewY, len(a.previewLines(v))-1)
+	v.previewY = max(v.previewY, 0)
+}
+
+// Отрисовка предпросмотра
+func (a *App) drawPreview(v *editorView) {
+	startX, startY, editorWidth, editorHeight := v.textArea()
+	lines := a.previewLines(v)
+	a.clampPreview(v)
+
+	// совпадения поиска в исходных строках (см. previewsearch.go)
+	var src []string
+	if v.found != nil {
+		src = v.buf.Lines()
+	}
+	for row := 0; row < editorHeight && v.previewY+row < len(lines); row++ {
+		line := lines[v.previewY+row]
+		y := startY + row
+		var matches [][2]int
+		if v.found != nil && line.Src < len(src) {
+			matches = findInLine(v.found.re, src[line.Src], v.found.wholeWord)
+		}
+		if line.Filled {
+			for x := 0; x < editorWidth; x++ {
+				a.screen.SetContent(startX+x, y, ' ', nil, line.Fill)
+			}
+		}
+		// scrollX — горизонтальная прокрутка в экранных колонках
+		col := 0
+		if !a.config.Preview.Wrap {
+			col = -v.scrollX
+		}
+		for _, c := range line.Cells {
+			if col+c.Width > editorWidth {
+				break
+			}
+			if col >= 0 {
+				style := c.Style
+				if matches != nil {
+					style = a.matchStyle(v, line.Src, c.Pos, matches, style)
+				}
+				a.screen.SetContent(startX+col, y, c.Rune, c.Comb, style)
+			}
+			col += c.Width
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ---- Навигация в предпросмотре ----
+//
+// Как в пейджере: gg/Home — в начало, G/End — в конец, Ctrl+D/Ctrl+U —
+// на полстраницы, PgDn/PgUp — на страницу, % — на N% документа.
+// Клавиши действуют только в предпросмотре (panel "preview" в реестре
+// команд), в редакторе g и G по-прежнему вводятся как текст.
+
+// Высота текста активного окна
+func (a *App) previewPage() int {
+	_, _, _, h := a.view.textArea()
+	return h
+}
+
+func (a *App) previewTop() {
+	a.view.previewY = 0
+}
+
+func (a *App) previewBottom() {
+	// последняя строка документа — у нижнего края окна
+	a.view.previewY = len(a.previewLines(a.view)) - a.previewPage()
+	a.clampPreview(a.view)
+}
+
+// Прокрутить на долю страницы (знак — направление)
+func (a *App) previewScrollPage(num, den int) {
+	a.scrollPreview(a.view, a.previewPage()*num/den)
+}
+
+// Прокрутить так, чтобы сверху была строка на pct% документа
+func (a *App) previewPercent(pct int) {
+	pct = min(max(pct, 0), 100)
+	a.view.previewY = (len(a.previewLines(a.view)) - 1) * pct / 100
+	a.clampPreview(a.view)
+}
+
+// %: спросить процент и перейти
+func (a *App) previewPercentPrompt() {
+	a.prompt(tr("preview.percent_title"), "", func(text string) {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(text), "%"))
+		if err != nil {
+			a.notify(levelWarning, tr("preview.percent_bad"), text)
+			return
+		}
+		a.previewPercent(n)
+	})
+}
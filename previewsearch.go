@@ -0,0 +1,84 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/preview"
+)
+
+// ---- Поиск в предпросмотре ----
+//
+// Ctrl+F, F3 и Shift+F3 работают и в предпросмотре. Ищется исходный
+// текст документа, а не показанный: совпадение внутри срезанной
+// разметки (**, адрес ссылки) всё равно находится, и окно
+// прокручивается к нужной экранной строке. Ячейки предпросмотра помнят
+// исходную руну (mdrender.Cell.Pos), так что все совпадения на экране
+// подсвечиваются стилем выделения, текущее — ещё и жирным с
+// подчёркиванием. Esc убирает подсветку.
+
+// Найденное в предпросмотре: запрос и текущее совпадение
+type previewFound struct {
+	re        *regexp.Regexp
+	wholeWord bool
+	y, s, e   int // исходная строка и руны [s, e)
+}
+
+// Перейти к следующему (предыдущему) совпадению в предпросмотре
+func (a *App) searchPreview(re *regexp.Regexp, backward bool) {
+	v := a.view
+	y, x := a.previewTopSource(v), 0
+	if f := v.found; f != nil {
+		y, x = f.y, f.e
+		if backward {
+			x = f.s
+		}
+	}
+	y, s, e, ok := findNext(v.buf.Lines(), re, a.search.opts.wholeWord, y, x, backward)
+	if !ok {
+		v.found = nil
+		a.notify(levelInfo, tr("search.not_found"), a.search.query)
+		return
+	}
+	v.found = &previewFound{re: re, wholeWord: a.search.opts.wholeWord, y: y, s: s, e: e}
+	a.activePanel = "right"
+
+	// экранная строка с началом совпадения (исходная может занимать несколько)
+	lines := a.previewLines(v)
+	row := preview.RowOf(lines, y)
+	for k := row; k < len(lines) && lines[k].Src == y; k++ {
+		if preview.HasPos(lines[k], s, e) {
+			row = k
+			break
+		}
+	}
+	_, _, _, h := v.textArea()
+	if row < v.previewY || row >= v.previewY+h {
+		v.previewY = row - h/3
+		a.clampPreview(v)
+	}
+}
+
+// Стиль ячейки с исходной руной pos строки src при совпадениях matches
+func (a *App) matchStyle(v *editorView, src, pos int, matches [][2]int, style tcell.Style) tcell.Style {
+	if pos < 0 {
+		return style
+	}
+	for _, m := range matches {
+		if pos < m[0] || pos >= m[1] {
+			continue
+		}
+		style = a.getStyles().Selection.apply(style)
+		if f := v.found; src == f.y && m[0] == f.s {
+			style = style.Bold(true).Underline(true)
+		}
+		return style
+	}
+	return style
+}
+
+// Esc: убрать подсветку поиска
+func (a *App) clearPreviewSearch() {
+	a.view.found = nil
+}
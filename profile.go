@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	_ "net/http/pprof" // обработчики /debug/pprof/
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// ---- Профилирование ----
+//
+// --pprof :6060 поднимает net/http/pprof (go tool pprof
+// http://localhost:6060/debug/pprof/profile), --cpuprofile файл пишет
+// профиль процессора с запуска до выхода, --memprofile файл — профиль
+// памяти при выходе. Вместе с --script (см. script.go) это повторяемый
+// замер отрисовки и правки больших файлов.
+
+// Включённые профили
+var profiling struct {
+	cpu     *os.File
+	memPath string
+}
+
+// Включить профилирование по флагам
+func startProfiling(addr, cpuPath, memPath string) error {
+	if addr != "" {
+		// слушаем сразу: занятый порт — ошибка запуска, а не тихий отказ
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("pprof: %w", err)
+		}
+		go http.Serve(ln, nil)
+	}
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return fmt.Errorf("cpuprofile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("cpuprofile: %w", err)
+		}
+		profiling.cpu = f
+	}
+	profiling.memPath = memPath
+	return nil
+}
+
+// Дописать профили (при выходе)
+func stopProfiling() {
+	if profiling.cpu != nil {
+		pprof.StopCPUProfile()
+		profiling.cpu.Close()
+		profiling.cpu = nil
+	}
+	if profiling.memPath != "" {
+		path := profiling.memPath
+		profiling.memPath = ""
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "memprofile:", err)
+			return
+		}
+		defer f.Close()
+		runtime.GC() // профиль живых объектов, а не мусора
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fmt.Fprintln(os.Stderr, "memprofile:", err)
+		}
+	}
+}
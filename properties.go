@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Свойства файла и права доступа (Alt+p) ----
+//
+// В левой панели пробел отмечает файлы. Alt+p показывает размер,
+// владельца, время изменения и права отмеченных файлов (или файла под
+// курсором). Права меняются в таблице rwx (стрелки и пробел) или вводом
+// восьмеричного числа (Tab переключает); Enter применяет их ко всем
+// файлам сразу.
+
+// Отметить файл под курсором и перейти к следующему
+func (a *App) toggleMark() {
+	if len(a.files) == 0 || a.cursor < 0 || a.cursor >= len(a.files) {
+		return
+	}
+	if a.marked == nil {
+		a.marked = map[string]bool{}
+	}
+	path := a.files[a.cursor].path
+	if a.marked[path] {
+		delete(a.marked, path)
+	} else {
+		a.marked[path] = true
+	}
+	if a.cursor < len(a.files)-1 {
+		a.cursor++
+	}
+}
+
+// Отмеченные файлы текущей папки, а если их нет — файл под курсором
+func (a *App) markedFiles() []fileItem {
+	var out []fileItem
+	for _, f := range a.files {
+		if a.marked[f.path] {
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 && a.cursor >= 0 && a.cursor < len(a.files) {
+		out = append(out, a.files[a.cursor])
+	}
+	return out
+}
+
+// Снять отметки с файлов, которых больше нет в списке
+func (a *App) pruneMarks() {
+	present := map[string]bool{}
+	for _, f := range a.files {
+		present[f.path] = true
+	}
+	for path := range a.marked {
+		if !present[path] {
+			delete(a.marked, path)
+		}
+	}
+}
+
+// Файл в окне свойств
+type propTarget struct {
+	path string
+	info fs.FileInfo
+}
+
+type propertiesOverlay struct {
+	targets []propTarget
+	perm    fs.FileMode
+	// фокус: таблица rwx или поле восьмеричного ввода
+	octalFocus bool
+	row, col   int
+	octal      inputLine
+}
+
+// Alt+p: окно свойств отмеченных файлов
+func (a *App) showProperties() {
+	if a.activePanel != "left" {
+		return
+	}
+	var targets []propTarget
+	for _, f := range a.markedFiles() {
+		info, err := os.Lstat(f.path)
+		if err != nil {
+			a.notify(levelError, tr("props.failed"), f.name, err)
+			return
+		}
+		targets = append(targets, propTarget{path: f.path, info: info})
+	}
+	if len(targets) == 0 {
+		return
+	}
+	p := &propertiesOverlay{targets: targets, perm: targets[0].info.Mode().Perm()}
+	p.syncOctal()
+	a.pushOverlay(p)
+}
+
+func (p *propertiesOverlay) syncOctal() {
+	p.octal = newInputLine(fmt.Sprintf("%03o", uint32(p.perm)))
+}
+
+// Строки сведений: подпись и значение
+func (p *propertiesOverlay) details() [][2]string {
+	if len(p.targets) == 1 {
+		t := p.targets[0]
+		size := formatSize(t.info.Size())
+		if t.info.IsDir() {
+			size = "—"
+		}
+		return [][2]string{
+			{tr("props.name"), t.info.Name()},
+			{tr("props.size"), size},
+			{tr("props.owner"), fileOwner(t.info)},
+			{tr("props.modified"), t.info.ModTime().Format("2006-01-02 15:04:05")},
+			{tr("props.mode"), fmt.Sprintf("%s  %04o", t.info.Mode(), uint32(t.info.Mode().Perm()))},
+		}
+	}
+	var total int64
+	mixed := false
+	for _, t := range p.targets {
+		total += t.info.Size()
+		if t.info.Mode().Perm() != p.targets[0].info.Mode().Perm() {
+			mixed = true
+		}
+	}
+	mode := fmt.Sprintf("%04o", uint32(p.targets[0].info.Mode().Perm()))
+	if mixed {
+		mode = tr("props.mixed")
+	}
+	return [][2]string{
+		{tr("props.files"), strconv.Itoa(len(p.targets))},
+		{tr("props.size"), formatSize(total)},
+		{tr("props.mode"), mode},
+	}
+}
+
+// Бит права для строки (владелец, группа, остальные) и столбца (r, w, x)
+func permBit(row, col int) fs.FileMode {
+	return 1 << uint(8-row*3-col)
+}
+
+func (p *propertiesOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	details := p.details()
+	x, y, w, h := a.centeredRect(68, len(details)+10)
+	title := " " + tr("props.title") + " "
+	ui.Box(a.screen, x, y, w, h, title, st.border, st.body)
+
+	row := y + 1
+	for _, d := range details {
+		col := ui.PutString(a.screen, x+2, row, x+w-2, d[0]+":", st.dim)
+		ui.PutString(a.screen, max(col+1, x+14), row, x+w-2, d[1], st.body)
+		row++
+	}
+	row++
+
+	// таблица rwx
+	ui.PutString(a.screen, x+14, row, x+w-2, "r  w  x", st.dim)
+	row++
+	for r, who := range []string{tr("props.user"), tr("props.group"), tr("props.other")} {
+		ui.PutString(a.screen, x+2, row, x+14, who, st.dim)
+		for c := 0; c < 3; c++ {
+			mark := "-"
+			if p.perm&permBit(r, c) != 0 {
+				mark = string("rwx"[c])
+			}
+			style := st.body
+			if !p.octalFocus && r == p.row && c == p.col {
+				style = st.selected
+			}
+			ui.PutString(a.screen, x+14+c*3, row, x+w-2, mark, style)
+		}
+		row++
+	}
+	row++
+
+	col := ui.PutString(a.screen, x+2, row, x+w-2, tr("props.octal")+" ", st.dim)
+	inputStyle := st.input
+	if !p.octalFocus {
+		inputStyle = st.body
+	}
+	a.drawInputLine(&p.octal, col, row, 6, inputStyle)
+	if !p.octalFocus {
+		a.screen.HideCursor()
+	}
+	ui.PutString(a.screen, x+2, y+h-2, x+w-2, tr("props.hint"), st.dim)
+}
+
+func (p *propertiesOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyTab, tcell.KeyBacktab:
+		p.octalFocus = !p.octalFocus
+		return false
+	case tcell.KeyEnter:
+		if p.octalFocus && !p.parseOctal() {
+			a.notify(levelWarning, tr("props.bad_octal"), p.octal.String())
+			return false
+		}
+		a.popOverlay()
+		a.chmodTargets(p.targets, p.perm)
+		return false
+	}
+	if p.octalFocus {
+		if ev.Key() == tcell.KeyRune && (ev.Rune() < '0' || ev.Rune() > '7') {
+			return false
+		}
+		p.octal.handleKey(ev)
+		p.parseOctal()
+		return false
+	}
+	switch ev.Key() {
+	case tcell.KeyUp:
+		p.row = (p.row + 2) % 3
+	case tcell.KeyDown:
+		p.row = (p.row + 1) % 3
+	case tcell.KeyLeft:
+		p.col = (p.col + 2) % 3
+	case tcell.KeyRight:
+		p.col = (p.col + 1) % 3
+	case tcell.KeyRune:
+		if ev.Rune() == ' ' {
+			p.perm ^= permBit(p.row, p.col)
+			p.syncOctal()
+		}
+	}
+	return false
+}
+
+// Разобрать восьмеричное поле; при успехе обновляет таблицу
+func (p *propertiesOverlay) parseOctal() bool {
+	s := strings.TrimSpace(p.octal.String())
+	n, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || s == "" || n > 0777 {
+		return false
+	}
+	p.perm = fs.FileMode(n)
+	return true
+}
+
+// Применить права ко всем файлам; особые биты (setuid и т.п.) сохраняются
+func (a *App) chmodTargets(targets []propTarget, perm fs.FileMode) {
+	changed := 0
+	for _, t := range targets {
+		if t.info.Mode()&fs.ModeSymlink != 0 {
+			continue
+		}
+		mode := t.info.Mode()&^fs.ModePerm | perm
+		if err := os.Chmod(t.path, mode); err != nil {
+			a.notify(levelError, tr("props.chmod_failed"), t.info.Name(), err)
+			continue
+		}
+		changed++
+	}
+	if changed > 0 {
+		a.notify(levelSuccess, tr("props.chmod_done"), fmt.Sprintf("%04o", uint32(perm)), changed)
+	}
+}
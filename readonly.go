@@ -0,0 +1,38 @@
+package main
+
+// ---- Только для чтения ----
+//
+// Окно можно перевести в режим «только чтение» (Alt+r), а с флагом
+// --readonly так открываются все файлы. Проверку делают сами примитивы
+// правки (insertText, setLines, deleteSelection, pasteText и др.), так
+// что ни клавиша, ни команда, ни оверлей её не обходят; отмена, повтор
+// и внешний редактор проверяют её отдельно. Ctrl+S не сохраняет, в
+// строке состояния вместо [+] виден [RO]. Файлы из архивов и по
+// ссылкам всегда открываются только для чтения.
+
+// Alt+r: включить или выключить «только чтение» для текущего окна
+func (a *App) toggleReadOnly() {
+	buf := a.view.buf
+	buf.readOnly = !buf.readOnly
+	if buf.readOnly {
+		a.unlock(buf)
+		a.notify(levelInfo, "%s", tr("readonly.on"))
+	} else {
+		a.notify(levelInfo, "%s", tr("readonly.off"))
+		a.lockBuffer(buf) // с чужой блокировкой спросит подтверждение
+	}
+}
+
+// Открывать ли файл только для чтения
+func (a *App) openReadOnly(path string) bool {
+	return a.readOnly || inArchive(path) || isWebURL(path)
+}
+
+// Можно ли править текущее окно (иначе — сообщение)
+func (a *App) checkWritable() bool {
+	if a.view.buf.readOnly {
+		a.notify(levelWarning, "%s", tr("readonly.blocked"))
+		return false
+	}
+	return true
+}
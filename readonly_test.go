@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestReadOnlyBlocksEdits(t *testing.T) {
+	key := func(k tcell.Key, r rune, mods tcell.ModMask) func(a *App) {
+		return func(a *App) { a.handleKey(tcell.NewEventKey(k, r, mods)) }
+	}
+	tests := []struct {
+		name string
+		edit func(a *App)
+	}{
+		{"type", key(tcell.KeyRune, 'x', tcell.ModNone)},
+		{"enter", key(tcell.KeyEnter, 0, tcell.ModNone)},
+		{"backspace", key(tcell.KeyBackspace2, 0, tcell.ModNone)},
+		{"delete", key(tcell.KeyDelete, 0, tcell.ModNone)},
+		{"cut", key(tcell.KeyCtrlX, 0, tcell.ModCtrl)},
+		{"paste", key(tcell.KeyCtrlV, 0, tcell.ModCtrl)},
+		{"clipboard picker", func(a *App) {
+			a.handleKey(tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModAlt))
+			a.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+		}},
+		{"bracketed paste", func(a *App) { a.insertPasted("pasted") }},
+		{"undo", key(tcell.KeyCtrlZ, 0, tcell.ModCtrl)},
+		{"repeat", key(tcell.KeyRune, '.', tcell.ModAlt)},
+	}
+	for _, tt := range tests {
+		a := newTestApp(t, nil)
+		a.activePanel, a.view.mode = "right", "edit"
+		a.view.buf.Content = "one two"
+		a.undoCheckpoint()
+		// правка до включения «только чтения»: есть что отменить и повторить
+		a.view.editX = 7
+		a.insertText("!")
+		a.undoCheckpoint()
+		a.copyToClipboard("clip")
+		before := a.view.buf.Content
+
+		a.view.buf.readOnly = true
+		a.view.selecting = true
+		a.view.selY, a.view.selX = 0, 4
+		a.view.editY, a.view.editX = 0, 7
+		tt.edit(a)
+		if got := a.view.buf.Content; got != before {
+			t.Errorf("%s: buffer changed to %q", tt.name, got)
+		}
+		if msg := lastMessage(a); msg != tr("readonly.blocked") {
+			t.Errorf("%s: last message %q, want %q", tt.name, msg, tr("readonly.blocked"))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Перерисовка по изменению ----
+//
+// Главный цикл рисует экран, только если что-то изменилось: обработка
+// событий отмечает это вызовом redraw, а draw снимает отметку. Движение
+// мыши без кнопок и другие события, которые ничего не меняют, кадра не
+// стоят. То, что меняется со временем — истечение уведомления, подсказка
+// which-key, — заказывает таймер after: в назначенный момент в очередь
+// приходит tickEvent, и экран перерисовывается. Постоянного таймера нет:
+// пока ничего не ждёт своего времени, редактор не просыпается.
+
+// Событие таймера (см. after)
+type tickEvent struct {
+	tcell.EventTime
+}
+
+// Экран нужно перерисовать
+func (a *App) redraw() {
+	a.dirty = true
+}
+
+// Перерисовать экран через d. Безопасно вызывать из любой горутины.
+func (a *App) after(d time.Duration) {
+	time.AfterFunc(d, func() {
+		ev := &tickEvent{}
+		ev.SetEventNow()
+		_ = a.screen.PostEvent(ev)
+	})
+}
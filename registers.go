@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- История буфера обмена и именованные регистры ----
+//
+// Всё скопированное (Ctrl+C) и вырезанное (Ctrl+X) попадает в историю из
+// последних clipHistoryLimit записей. Ctrl+V вставляет последнюю запись,
+// Alt+v открывает список истории и регистров, чтобы вставить более старую.
+//
+// Alt+" и буква a–z выбирают регистр для следующего копирования,
+// вырезания или вставки, как "a в vim. Заглавная буква (A–Z) дописывает
+// к регистру. Текст из регистра не попадает в буфер обмена терминала.
+
+// Сколько записей истории хранить
+const clipHistoryLimit = 30
+
+// Ожидание буквы регистра после Alt+"
+const registerPending = '"'
+
+// Запомнить текст в истории: повтор переносится в конец
+func (a *App) rememberClip(text string) {
+	kept := make([]string, 0, len(a.clipHistory)+1)
+	for _, s := range a.clipHistory {
+		if s != text {
+			kept = append(kept, s)
+		}
+	}
+	kept = append(kept, text)
+	if len(kept) > clipHistoryLimit {
+		kept = kept[len(kept)-clipHistoryLimit:]
+	}
+	a.clipHistory = kept
+}
+
+// Выбрать регистр: Alt+" и затем буква
+func (a *App) selectRegister(ev *tcell.EventKey) {
+	r := ev.Rune()
+	if ev.Key() != tcell.KeyRune || r > unicode.MaxASCII || !unicode.IsLetter(r) {
+		a.register = 0
+		return
+	}
+	a.register = r
+	a.notify(levelInfo, tr("register.selected"), r)
+}
+
+// Забрать выбранный регистр (0 — не выбран)
+func (a *App) takeRegister() rune {
+	r := a.register
+	a.register = 0
+	if r == registerPending {
+		return 0
+	}
+	return r
+}
+
+// Сохранить скопированный текст: в выбранный регистр или в буфер обмена
+func (a *App) yank(text string) {
+	r := a.takeRegister()
+	if r == 0 {
+		a.copyToClipboard(text)
+		return
+	}
+	if a.registers == nil {
+		a.registers = map[rune]string{}
+	}
+	if unicode.IsUpper(r) {
+		a.registers[unicode.ToLower(r)] += text
+	} else {
+		a.registers[r] = text
+	}
+}
+
+// Ctrl+X: вырезать выделение
+func (a *App) cutSelection() {
+	text := a.view.selectedText()
+	if text == "" {
+		return
+	}
+	a.yank(text)
+	if !a.deleteSelection() {
+		return
+	}
+	a.ensureCursorVisible()
+	a.notify(levelInfo, tr("selection.cut"), len([]rune(text)))
+}
+
+// Ctrl+V: вставить из выбранного регистра или последнее скопированное
+func (a *App) pasteClipboard() {
+	text := a.clipboard
+	if r := a.takeRegister(); r != 0 {
+		text = a.registers[unicode.ToLower(r)]
+		if text == "" {
+			a.notify(levelInfo, tr("register.empty"), unicode.ToLower(r))
+			return
+		}
+	}
+	a.pasteText(text)
+}
+
+// Вставить текст вместо выделения
+func (a *App) pasteText(text string) {
+	if text == "" || !a.checkWritable() {
+		return
+	}
+	a.deleteSelection()
+	a.insertText(text)
+	a.ensureCursorVisible()
+}
+
+// Alt+v: выбрать текст из истории или регистра и вставить
+func (a *App) clipboardPicker() {
+	if a.activePanel != "right" || a.view.mode != "edit" {
+		return
+	}
+	var items []listItem
+	for i := len(a.clipHistory) - 1; i >= 0; i-- {
+		text := a.clipHistory[i]
+		items = append(items, listItem{label: clipLabel(text), detail: clipDetail(text), value: strconv.Itoa(i)})
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		if text, ok := a.registers[r]; ok {
+			items = append(items, listItem{label: fmt.Sprintf("\"%c  %s", r, clipLabel(text)), detail: clipDetail(text), value: string(r)})
+		}
+	}
+	if len(items) == 0 {
+		a.notify(levelInfo, "%s", tr("clip.empty"))
+		return
+	}
+	a.pick(tr("clip.title"), items, func(item listItem) {
+		if i, err := strconv.Atoi(item.value); err == nil {
+			// выбранная запись становится последней
+			a.copyToClipboard(a.clipHistory[i])
+			a.pasteText(a.clipboard)
+			return
+		}
+		a.pasteText(a.registers[[]rune(item.value)[0]])
+	})
+}
+
+// Первая строка текста для списка
+func clipLabel(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
+
+// Размер текста для списка: строки или символы
+func clipDetail(text string) string {
+	if n := strings.Count(text, "\n") + 1; n > 1 {
+		return trf("clip.lines", n)
+	}
+	return trf("clip.chars", len([]rune(text)))
+}
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// ---- Удалённые файлы: sftp://user@host[:port]/path ----
+//
+// Путь вида sftp://user@host/home/user/notes можно ввести в окне перехода
+// (Alt+o): левая панель показывает удалённую папку, файлы открываются и
+// сохраняются как обычные. Файлы читаются и пишутся по протоколу SFTP
+// (github.com/pkg/sftp); транспорт — системный ssh с подсистемой sftp,
+// так что работают ключи, ssh-agent и ~/.ssh/config, а оболочка на
+// сервере не нужна. Пароль в интерфейсе не спрашивается. Соединение с
+// хостом открывается один раз и переиспользуется; оборванное
+// открывается заново при следующем обращении. Удаление и
+// переименование в панели тоже выполняются на сервере.
+//
+// Сеть не держит интерфейс: папки, файлы, запись, удаление и
+// переименование обрабатываются в фоне, результат возвращается в
+// главный цикл через a.post. Файл
+// пишется во временный рядом и переименовывается поверх старого, так
+// что обрыв связи посреди записи не оставляет его обрезанным.
+
+const (
+	remoteTimeout   = 30 * time.Second
+	remoteKeepAlive = 15 * time.Second // ssh проверяет связь, после трёх пропусков рвёт её
+)
+
+// Удалённое место
+type remoteLoc struct {
+	user, host, port string
+	path             string // абсолютный путь на сервере
+}
+
+// Разобрать sftp://-адрес
+func parseRemote(s string) (remoteLoc, bool) {
+	if !strings.HasPrefix(s, "sftp://") {
+		return remoteLoc{}, false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Hostname() == "" {
+		return remoteLoc{}, false
+	}
+	r := remoteLoc{host: u.Hostname(), port: u.Port(), path: u.Path}
+	if u.User != nil {
+		r.user = u.User.Username()
+	}
+	if r.path != "" {
+		r.path = path.Clean(r.path)
+	}
+	return r, true
+}
+
+func isRemote(p string) bool {
+	return strings.HasPrefix(p, "sftp://")
+}
+
+func (r remoteLoc) String() string {
+	return "sftp://" + r.conn() + r.path
+}
+
+// Адрес соединения: user@host:port
+func (r remoteLoc) conn() string {
+	host := r.host
+	if r.port != "" {
+		host += ":" + r.port
+	}
+	if r.user != "" {
+		host = r.user + "@" + host
+	}
+	return host
+}
+
+// То же место с другим путём
+func (r remoteLoc) with(p string) remoteLoc {
+	r.path = path.Clean(p)
+	return r
+}
+
+// Соединение с одним адресом. Подключение (до remoteTimeout) идёт под
+// своей блокировкой: медленный сервер не задерживает операции с другими.
+type sftpConn struct {
+	sync.Mutex
+	c *sftp.Client
+}
+
+// Соединения по адресу
+var sftpClients = struct {
+	sync.Mutex
+	m map[string]*sftpConn
+}{m: map[string]*sftpConn{}}
+
+// Соединение с адресом r (создаётся при первом обращении)
+func (r remoteLoc) sftpConn() *sftpConn {
+	sftpClients.Lock()
+	defer sftpClients.Unlock()
+	sc := sftpClients.m[r.conn()]
+	if sc == nil {
+		sc = &sftpConn{}
+		sftpClients.m[r.conn()] = sc
+	}
+	return sc
+}
+
+// Соединение с сервером: открытое раньше или новое
+func (r remoteLoc) client() (*sftp.Client, error) {
+	sc := r.sftpConn()
+	sc.Lock()
+	defer sc.Unlock()
+	if sc.c != nil {
+		return sc.c, nil
+	}
+	c, err := r.dial()
+	if err != nil {
+		return nil, err
+	}
+	sc.c = c
+	return c, nil
+}
+
+// Закрыть оборвавшееся соединение
+func (r remoteLoc) dropClient(c *sftp.Client) {
+	sc := r.sftpConn()
+	sc.Lock()
+	if sc.c == c {
+		sc.c = nil
+	}
+	sc.Unlock()
+	_ = c.Close()
+}
+
+// Запустить ssh с подсистемой sftp и начать сеанс
+func (r remoteLoc) dial() (*sftp.Client, error) {
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=" + strconv.Itoa(int(remoteTimeout/time.Second)),
+		"-o", "ServerAliveInterval=" + strconv.Itoa(int(remoteKeepAlive/time.Second)),
+		"-o", "ServerAliveCountMax=3",
+	}
+	if r.port != "" {
+		args = append(args, "-p", r.port)
+	}
+	if r.user != "" {
+		args = append(args, "-l", r.user)
+	}
+	// "--": хост, начинающийся с "-", не примется за ключ
+	args = append(args, "-s", "--", r.host, "sftp")
+	cmd := exec.Command("ssh", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	w, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	rd, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	// сервер, не ответивший на приветствие, не держит фоновую задачу вечно
+	timer := time.AfterFunc(remoteTimeout, func() { _ = cmd.Process.Kill() })
+	c, err := sftp.NewClientPipe(rd, w)
+	timer.Stop()
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(firstLine(msg))
+		}
+		return nil, err
+	}
+	go func() { _ = cmd.Wait() }()
+	return c, nil
+}
+
+// Выполнить fn с соединением; при обрыве — ещё раз с новым
+func (r remoteLoc) do(fn func(c *sftp.Client) error) error {
+	c, err := r.client()
+	if err == nil {
+		err = fn(c)
+		if errors.Is(err, sftp.ErrSSHFxConnectionLost) {
+			r.dropClient(c)
+			if c, err = r.client(); err == nil {
+				err = fn(c)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.host, err)
+	}
+	return nil
+}
+
+// Домашняя папка на сервере (для адреса без пути)
+func (r remoteLoc) resolve() (remoteLoc, error) {
+	if r.path != "" {
+		return r, nil
+	}
+	err := r.do(func(c *sftp.Client) error {
+		wd, err := c.Getwd()
+		if err == nil {
+			r = r.with(wd)
+		}
+		return err
+	})
+	return r, err
+}
+
+// Что находится по пути: "dir", "file" или "" (ничего)
+func (r remoteLoc) kind() (string, error) {
+	kind := ""
+	err := r.do(func(c *sftp.Client) error {
+		fi, err := c.Stat(r.path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil
+		case err != nil:
+			return err
+		case fi.IsDir():
+			kind = "dir"
+		default:
+			kind = "file"
+		}
+		return nil
+	})
+	return kind, err
+}
+
+// Записи удалённой папки; ссылки — по тому, на что указывают
+func (r remoteLoc) readDir() ([]os.DirEntry, error) {
+	var entries []os.DirEntry
+	err := r.do(func(c *sftp.Client) error {
+		infos, err := c.ReadDir(r.path)
+		if err != nil {
+			return err
+		}
+		entries = entries[:0]
+		for _, fi := range infos {
+			if fi.Mode()&fs.ModeSymlink != 0 {
+				if target, err := c.Stat(path.Join(r.path, fi.Name())); err == nil {
+					fi = renamedInfo{target, fi.Name()}
+				}
+			}
+			entries = append(entries, fs.FileInfoToDirEntry(fi))
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Сведения о цели ссылки под именем самой ссылки
+type renamedInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (i renamedInfo) Name() string { return i.name }
+
+// Прочитать удалённую папку в фоне (см. dirload.go)
+func (a *App) loadRemoteFiles(r remoteLoc) {
+	dl := &dirLoad{dir: a.currentDir, started: time.Now()}
+	a.dirLoad = dl
+	go func() {
+		entries, err := r.readDir()
+		dl.mu.Lock()
+		dl.pending = append(dl.pending, entries)
+		dl.err = err
+		dl.done = true
+		dl.mu.Unlock()
+		a.post(func() { a.drainDirLoad(dl) })
+	}()
+	a.spinDirLoad(dl)
+}
+
+// Прочитать удалённый файл
+func readRemoteFile(p string) ([]byte, error) {
+	r, _ := parseRemote(p)
+	var data []byte
+	err := r.do(func(c *sftp.Client) error {
+		f, err := c.Open(r.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		data, err = io.ReadAll(f)
+		return err
+	})
+	return data, err
+}
+
+// Записать удалённый файл: во временный рядом, затем переименованием
+// поверх старого (ссылка остаётся ссылкой — заменяется её цель)
+func writeRemoteFile(p string, data []byte) error {
+	r, _ := parseRemote(p)
+	return r.do(func(c *sftp.Client) error {
+		target := r.path
+		if fi, err := c.Lstat(target); err == nil && fi.Mode()&fs.ModeSymlink != 0 {
+			if link, err := c.ReadLink(target); err == nil {
+				if !path.IsAbs(link) {
+					link = path.Join(path.Dir(target), link)
+				}
+				target = link
+			}
+		}
+		dir, name := path.Split(target)
+		tmp := path.Join(dir, fmt.Sprintf(".%s.eddy-%d", name, time.Now().UnixNano()))
+		if err := writeRemoteTemp(c, tmp, target, data); err != nil {
+			_ = c.Remove(tmp)
+			return err
+		}
+		if _, ok := c.HasExtension("posix-rename@openssh.com"); ok {
+			err := c.PosixRename(tmp, target)
+			if err != nil {
+				_ = c.Remove(tmp)
+			}
+			return err
+		}
+		// простой rename в SFTP не заменяет существующий файл
+		if err := c.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			_ = c.Remove(tmp)
+			return err
+		}
+		return c.Rename(tmp, target)
+	})
+}
+
+// Записать временный файл с правами заменяемого
+func writeRemoteTemp(c *sftp.Client, tmp, target string, data []byte) error {
+	f, err := c.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if fi, err := c.Stat(target); err == nil {
+		return c.Chmod(tmp, fi.Mode().Perm())
+	}
+	return nil
+}
+
+// Удалить удалённый файл
+func removeRemoteFile(p string) error {
+	r, _ := parseRemote(p)
+	return r.do(func(c *sftp.Client) error {
+		return c.Remove(r.path)
+	})
+}
+
+// Переименовать удалённый файл или папку; занятое имя — fs.ErrExist
+func renameRemote(from, to string) error {
+	r, _ := parseRemote(from)
+	dst, _ := parseRemote(to)
+	return r.do(func(c *sftp.Client) error {
+		if _, err := c.Lstat(dst.path); err == nil {
+			return fs.ErrExist
+		}
+		return c.Rename(r.path, dst.path)
+	})
+}
+
+// Родительская папка удалённого пути
+func remoteParent(p string) string {
+	r, _ := parseRemote(p)
+	return r.with(path.Dir(r.path)).String()
+}
+
+// Открыть удалённый файл: чтение — в фоне
+func (a *App) openRemoteFile(p string) {
+	a.notify(levelInfo, tr("remote.loading"), p)
+	go func() {
+		data, err := readRemoteFile(p)
+		a.post(func() {
+			if err != nil {
+				a.notify(levelError, tr("file.read_error"), err)
+				return
+			}
+			a.openContent(p, data)
+		})
+	}()
+}
+
+// Записать буфер на сервер: запись — в фоне
+func (a *App) saveRemoteFile(buf *buffer) {
+	p, content := buf.path, buf.Content
+	a.notify(levelInfo, tr("remote.saving"), p)
+	go func() {
+		err := writeRemoteFile(p, []byte(content))
+		a.post(func() {
+			if err != nil {
+				a.notify(levelError, tr("save.failed"), err)
+				return
+			}
+			a.saved(buf, content)
+		})
+	}()
+}
+
+// Alt+o: перейти к папке или открыть файл по пути (в том числе sftp:// и http(s)://)
+func (a *App) openPathPrompt() {
+	a.promptHistory(historyOpen, tr("open.title"), "", func(text string) {
+		text = strings.TrimSpace(text)
+		if text != "" {
+			a.openPath(text)
+		}
+	})
+}
+
+func (a *App) openPath(p string) {
+	if isWebURL(p) {
+		a.openURL(p) // см. web.go
+		return
+	}
+	if isRemote(p) {
+		a.openRemote(p)
+		return
+	}
+
+	if strings.HasPrefix(p, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(a.currentDir, p)
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		a.notify(levelError, tr("open.bad"), err)
+		return
+	}
+	if info.IsDir() {
+		a.enterDir(p)
+		return
+	}
+	a.enterDir(filepath.Dir(p))
+	a.openFile(p)
+	a.activePanel = "right"
+}
+
+// Перейти к удалённой папке или открыть удалённый файл (проверка пути — в фоне)
+func (a *App) openRemote(p string) {
+	r, ok := parseRemote(p)
+	if !ok {
+		a.notify(levelWarning, tr("open.bad"), p)
+		return
+	}
+	a.notify(levelInfo, tr("remote.loading"), p)
+	go func() {
+		r, err := r.resolve()
+		kind := ""
+		if err == nil {
+			kind, err = r.kind()
+		}
+		a.post(func() {
+			switch {
+			case err != nil:
+				a.notify(levelError, tr("remote.failed"), err)
+			case kind == "dir":
+				a.enterDir(r.String())
+			case kind == "file":
+				a.enterDir(remoteParent(r.String()))
+				a.openFile(r.String())
+				a.activePanel = "right"
+			default:
+				a.notify(levelWarning, tr("open.bad"), r)
+			}
+		})
+	}()
+}
+
+// Показать папку в левой панели
+func (a *App) enterDir(dir string) {
+	a.currentDir = dir
+	a.cursor = 0
+	a.fileScroll = 0
+	a.loadFiles()
+	a.setActivePanel("left")
+}
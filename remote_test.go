@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		in   string
+		ok   bool
+		want remoteLoc
+	}{
+		{"sftp://host/home/u", true, remoteLoc{host: "host", path: "/home/u"}},
+		{"sftp://u@host:2222/a/../b/", true, remoteLoc{user: "u", host: "host", port: "2222", path: "/b"}},
+		{"sftp://u@host", true, remoteLoc{user: "u", host: "host"}},
+		{"sftp://-oProxyCommand=x/", true, remoteLoc{host: "-oProxyCommand=x", path: "/"}},
+		{"sftp:///path", false, remoteLoc{}},
+		{"/home/u", false, remoteLoc{}},
+		{"https://host/x", false, remoteLoc{}},
+	}
+	for _, tt := range tests {
+		got, ok := parseRemote(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseRemote(%q) = %+v %v, want %+v %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRemotePaths(t *testing.T) {
+	tests := []struct {
+		fn       func(string) string
+		in, want string
+	}{
+		{func(s string) string { r, _ := parseRemote(s); return r.String() }, "sftp://u@h:22/a/./b", "sftp://u@h:22/a/b"},
+		{remoteParent, "sftp://u@h/a/b.md", "sftp://u@h/a"},
+		{remoteParent, "sftp://h/a", "sftp://h/"},
+		{func(s string) string { return childPath(s, "c.md") }, "sftp://u@h/a", "sftp://u@h/a/c.md"},
+		{func(s string) string { return childPath(s, "c.md") }, "sftp://h/", "sftp://h/c.md"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("%q -> %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
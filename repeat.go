@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	textbuf "github.com/StasKrav/eddy_tcell/pkg/buffer"
+)
+
+// ---- Повтор последней правки (Alt+.) ----
+//
+// Как . в vim: последняя правка применяется ещё раз в позиции курсора.
+// Набор и удаление берутся из истории отмены (undo.go) — повторяется
+// весь шаг целиком: набранное слово, серия Backspace или Delete,
+// замена текста (вместо выделения или текста под курсором). Команды,
+// меняющие текст по-своему (например, форматирование выделения),
+// запоминают себя через a.repeatable и при повторе выполняются заново.
+
+// Команда, которую можно повторить
+type repeatAction struct {
+	run func()
+	// шаг отмены, который команда создала; если последним стал другой
+	// шаг (например, набор текста), повторяется он
+	entry *textbuf.Entry
+}
+
+// Выполнить команду и запомнить её для повтора
+func (a *App) repeatable(run func()) {
+	before := a.view.buf.Content
+	run()
+	if a.view.buf.Content == before {
+		return
+	}
+	a.undoCheckpointView(a.view)
+	a.lastAction = &repeatAction{run: run, entry: a.lastUndoEntry()}
+}
+
+// Последний шаг отмены текущего буфера
+func (a *App) lastUndoEntry() *textbuf.Entry {
+	if h := a.view.buf.Undo; h != nil {
+		return h.Last()
+	}
+	return nil
+}
+
+// Alt+.: повторить последнюю правку
+func (a *App) repeatLastEdit() {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	a.undoCheckpointView(a.view)
+	e := a.lastUndoEntry()
+	if act := a.lastAction; act != nil && act.entry == e {
+		a.repeatable(act.run)
+		return
+	}
+	if e == nil {
+		a.notify(levelInfo, "%s", tr("repeat.none"))
+		return
+	}
+	a.replayEntry(e)
+}
+
+// Применить шаг отмены в позиции курсора
+func (a *App) replayEntry(e *textbuf.Entry) {
+	v := a.view
+	a.clampCursor()
+	content := v.buf.Content
+	pos := byteOffset(content, v.editY, v.editX)
+	switch {
+	case e.Removed == "":
+		a.pasteText(e.Inserted)
+		return
+	case e.Inserted == "" && (e.AfterY < e.BeforeY || e.AfterY == e.BeforeY && e.AfterX < e.BeforeX):
+		// Backspace: столько же символов перед курсором
+		from := pos
+		for n := utf8.RuneCountInString(e.Removed); n > 0 && from > 0; n-- {
+			_, size := utf8.DecodeLastRuneInString(content[:from])
+			from -= size
+		}
+		v.buf.Content = content[:from] + content[pos:]
+		pos = from
+	case e.Inserted == "":
+		// Delete: столько же символов после курсора
+		to := pos
+		for n := utf8.RuneCountInString(e.Removed); n > 0 && to < len(content); n-- {
+			_, size := utf8.DecodeRuneInString(content[to:])
+			to += size
+		}
+		v.buf.Content = content[:pos] + content[to:]
+	default:
+		// замена: выделения или того же текста под курсором
+		if _, ok := v.selection(); ok {
+			a.pasteText(e.Inserted)
+			return
+		}
+		if !strings.HasPrefix(content[pos:], e.Removed) {
+			a.notify(levelInfo, tr("repeat.no_match"), firstLine(e.Removed))
+			return
+		}
+		v.buf.Content = content[:pos] + e.Inserted + content[pos+len(e.Removed):]
+		pos += len(e.Inserted)
+	}
+	v.buf.Modified = true
+	v.clearSelection()
+	v.editY, v.editX = cursorAt(v.buf.Content, pos)
+	a.ensureCursorVisible()
+}
+
+// Байтовое смещение позиции (строка, руна) в тексте
+func byteOffset(content string, y, x int) int {
+	pos := 0
+	for i := 0; i < y; i++ {
+		nl := strings.IndexByte(content[pos:], '\n')
+		if nl < 0 {
+			return len(content)
+		}
+		pos += nl + 1
+	}
+	for ; x > 0 && pos < len(content) && content[pos] != '\n'; x-- {
+		_, size := utf8.DecodeRuneInString(content[pos:])
+		pos += size
+	}
+	return pos
+}
+
+// Позиция (строка, руна) для байтового смещения
+func cursorAt(content string, pos int) (int, int) {
+	head := content[:pos]
+	y := strings.Count(head, "\n")
+	if nl := strings.LastIndexByte(head, '\n'); nl >= 0 {
+		head = head[nl+1:]
+	}
+	return y, utf8.RuneCountInString(head)
+}
+
+// Первая строка текста
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Замена во всех файлах папки (Alt+f) ----
+//
+// Запрашиваются искомый текст (с параметрами последнего поиска: регистр,
+// слово целиком, regex) и замена. В окне предпросмотра все изменения
+// сгруппированы по файлам; пробел исключает строку или, на заголовке,
+// весь файл. Enter применяет замену сразу ко всем файлам: сначала
+// оригиналы копируются в ~/.local/state/eddy/backups/<время>/, новые
+// версии пишутся во временные файлы и только потом переименовываются
+// поверх. Если переименование не удалось, уже заменённые файлы
+// возвращаются из копий. Если файл изменился после поиска, ничего не
+// записывается. Файлы, открытые с несохранёнными правками, пропускаются.
+
+// Не больше этого размера файлы не просматриваются
+const replaceMaxFileSize = 2 << 20
+
+// Переименование поверх оригинала (тесты подменяют, чтобы проверить откат)
+var renameFile = os.Rename
+
+// Замена в одной строке файла
+type replaceHunk struct {
+	line     int // с нуля
+	old, new string
+	count    int
+	include  bool
+}
+
+// Файл с заменами
+type replaceFile struct {
+	path, rel string
+	content   string
+	lines     []string
+	hunks     []*replaceHunk
+	written   bool // файл переписан (заменой или возвратом копии)
+}
+
+// Заменить совпадения в строке; шаблон $1 работает только в режиме regex
+func replaceInLine(re *regexp.Regexp, line, repl string, opts searchOptions) (string, int) {
+	var b strings.Builder
+	last, n := 0, 0
+	for _, m := range re.FindAllStringSubmatchIndex(line, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		if opts.wholeWord {
+			before, _ := utf8.DecodeLastRuneInString(line[:m[0]])
+			after, _ := utf8.DecodeRuneInString(line[m[1]:])
+			if (m[0] > 0 && isIdentRune(before)) || (m[1] < len(line) && isIdentRune(after)) {
+				continue
+			}
+		}
+		b.WriteString(line[last:m[0]])
+		if opts.regex {
+			b.Write(re.ExpandString(nil, repl, line, m))
+		} else {
+			b.WriteString(repl)
+		}
+		last = m[1]
+		n++
+	}
+	if n == 0 {
+		return line, 0
+	}
+	b.WriteString(line[last:])
+	return b.String(), n
+}
+
+// Текстовый ли файл (нет нулевых байтов, корректный UTF-8)
+func isTextContent(data []byte) bool {
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) < 0 && utf8.Valid(data)
+}
+
+// Найти все замены в файлах папки root. skipped — открытые файлы с
+// несохранёнными правками или только для чтения.
+func (a *App) scanReplace(root string, re *regexp.Regexp, repl string, opts searchOptions) (files []*replaceFile, skipped []string) {
+	dirty := map[string]bool{}
+	for _, v := range a.views {
+		if (v.buf.Modified || v.buf.readOnly) && v.buf.path != "" {
+			dirty[v.buf.path] = true
+		}
+	}
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err != nil || !info.Mode().IsRegular() || info.Size() > replaceMaxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !isTextContent(data) {
+			return nil
+		}
+		f := &replaceFile{path: path, rel: path, content: string(data)}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			f.rel = rel
+		}
+		f.lines = strings.Split(f.content, "\n")
+		for i, line := range f.lines {
+			if nl, n := replaceInLine(re, line, repl, opts); n > 0 {
+				f.hunks = append(f.hunks, &replaceHunk{line: i, old: line, new: nl, count: n, include: true})
+			}
+		}
+		if len(f.hunks) == 0 {
+			return nil
+		}
+		if dirty[path] {
+			skipped = append(skipped, f.rel)
+			return nil
+		}
+		files = append(files, f)
+		return nil
+	})
+	return files, skipped
+}
+
+// Запросить текст и замену, показать предпросмотр
+func (a *App) replaceInFiles() {
+	opts := a.search.opts
+	a.promptHistory(historySearch, tr("replace.find")+searchOptionsLabel(opts), a.search.query, func(query string) {
+		if query == "" {
+			return
+		}
+		re, err := opts.compile(query)
+		if err != nil {
+			a.notify(levelWarning, tr("search.bad_regex"), err)
+			return
+		}
+		a.search.query = query
+		a.promptHistory(historyReplace, trf("replace.with", query), "", func(repl string) {
+			files, skipped := a.scanReplace(a.currentDir, re, repl, opts)
+			for _, s := range skipped {
+				a.notify(levelWarning, tr("replace.skipped"), s)
+			}
+			if len(files) == 0 {
+				a.notify(levelInfo, tr("search.not_found"), query)
+				return
+			}
+			a.pushOverlay(newReplaceOverlay(files))
+		})
+	})
+}
+
+// Включённые параметры поиска для заголовка: " (Aa, word, regex)"
+func searchOptionsLabel(o searchOptions) string {
+	var on []string
+	if !o.ignoreCase {
+		on = append(on, "Aa")
+	}
+	if o.wholeWord {
+		on = append(on, tr("search.opt.word"))
+	}
+	if o.regex {
+		on = append(on, "regex")
+	}
+	if len(on) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(on, ", ") + ")"
+}
+
+// Записать замены во все файлы сразу. Возвращает папку копий.
+func applyReplace(files []*replaceFile) (string, error) {
+	type pending struct {
+		f        *replaceFile
+		tmp      string
+		backup   string // копия оригинала
+		mode     fs.FileMode
+		content  string
+		replaced int
+	}
+	var todo []*pending
+	for _, f := range files {
+		lines := append([]string{}, f.lines...)
+		n := 0
+		for _, h := range f.hunks {
+			if h.include {
+				lines[h.line] = h.new
+				n += h.count
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		// файл не должен был измениться после поиска
+		info, err := os.Stat(f.path)
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return "", err
+		}
+		if string(data) != f.content {
+			return "", fmt.Errorf(tr("replace.changed"), f.rel)
+		}
+		todo = append(todo, &pending{f: f, mode: info.Mode().Perm(), content: strings.Join(lines, "\n"), replaced: n})
+	}
+	if len(todo) == 0 {
+		return "", nil
+	}
+
+	// копии оригиналов
+	backup := filepath.Join(stateDir(), "backups", time.Now().Format("20060102-150405"))
+	for _, p := range todo {
+		p.backup = filepath.Join(backup, p.f.rel)
+		if filepath.IsAbs(p.f.rel) || strings.HasPrefix(p.f.rel, "..") {
+			p.backup = filepath.Join(backup, filepath.Base(p.f.path))
+		}
+		if err := os.MkdirAll(filepath.Dir(p.backup), 0755); err != nil {
+			return "", err
+		}
+		// права оригинала: копия закрытого файла не должна читаться другими
+		if err := os.WriteFile(p.backup, []byte(p.f.content), p.mode); err != nil {
+			return "", err
+		}
+	}
+
+	// новые версии во временные файлы рядом с оригиналами
+	cleanup := func() {
+		for _, p := range todo {
+			if p.tmp != "" {
+				os.Remove(p.tmp)
+			}
+		}
+	}
+	for _, p := range todo {
+		tmp, err := writeTemp(p.f.path, p.content, p.mode)
+		if err != nil {
+			cleanup()
+			return backup, err
+		}
+		p.tmp = tmp
+	}
+	for k, p := range todo {
+		if err := renameFile(p.tmp, p.f.path); err != nil {
+			cleanup()
+			// уже заменённые файлы — обратно из копий
+			for _, done := range todo[:k] {
+				if rerr := restoreBackup(done.backup, done.f.path, done.mode); rerr != nil {
+					err = fmt.Errorf("%w; %s: %v", err, done.f.rel, rerr)
+				}
+			}
+			return backup, err
+		}
+		p.tmp = ""
+		p.f.written = true
+	}
+	for _, p := range todo {
+		p.f.content = p.content
+	}
+	return backup, nil
+}
+
+// Записать content во временный файл рядом с path; возвращает его имя
+func writeTemp(path, content string, mode fs.FileMode) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.WriteString(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// Вернуть файл path из копии backup (тоже через временный файл)
+func restoreBackup(backup, path string, mode fs.FileMode) error {
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		return err
+	}
+	tmp, err := writeTemp(path, string(data), mode)
+	if err != nil {
+		return err
+	}
+	if err := renameFile(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// ---- Окно предпросмотра замены ----
+
+// Строка окна: заголовок файла или замена (старая и новая строки)
+type replaceRow struct {
+	file *replaceFile
+	hunk *replaceHunk // nil — заголовок файла
+}
+
+type replaceOverlay struct {
+	files    []*replaceFile
+	rows     []replaceRow
+	selected int
+	scroll   int // в строках экрана
+}
+
+func newReplaceOverlay(files []*replaceFile) *replaceOverlay {
+	o := &replaceOverlay{files: files}
+	for _, f := range files {
+		o.rows = append(o.rows, replaceRow{file: f})
+		for _, h := range f.hunks {
+			o.rows = append(o.rows, replaceRow{file: f, hunk: h})
+		}
+	}
+	return o
+}
+
+// Строк экрана у строки окна: у замены две (было/стало)
+func (r replaceRow) height() int {
+	if r.hunk == nil {
+		return 1
+	}
+	return 2
+}
+
+// Всего замен и включённых
+func (o *replaceOverlay) counts() (total, included int) {
+	for _, f := range o.files {
+		for _, h := range f.hunks {
+			total += h.count
+			if h.include {
+				included += h.count
+			}
+		}
+	}
+	return total, included
+}
+
+func (o *replaceOverlay) rect(a *App) (x, y, w, h int) {
+	return a.centeredRect(a.width-4, a.height-2)
+}
+
+func (o *replaceOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := o.rect(a)
+	if w < 20 || h < 5 {
+		return
+	}
+	total, included := o.counts()
+	ui.Box(a.screen, x, y, w, h, " "+trf("replace.title", included, total, len(o.files))+" ", st.border, st.body)
+
+	visible := h - 3
+	// первая строка экрана выбранной строки окна
+	top := 0
+	for i := 0; i < o.selected; i++ {
+		top += o.rows[i].height()
+	}
+	if top < o.scroll {
+		o.scroll = top
+	}
+	if bottom := top + o.rows[o.selected].height(); bottom > o.scroll+visible {
+		o.scroll = bottom - visible
+	}
+
+	line := 0
+	put := func(text string, style tcell.Style, selected bool) {
+		sy := y + 1 + line - o.scroll
+		line++
+		if sy <= y || sy >= y+1+visible {
+			return
+		}
+		if selected {
+			style = st.selected
+			for cx := x + 1; cx < x+w-1; cx++ {
+				a.screen.SetContent(cx, sy, ' ', nil, style)
+			}
+		}
+		ui.PutString(a.screen, x+2, sy, x+w-2, text, style)
+	}
+	totalLines := 0
+	for i, r := range o.rows {
+		totalLines += r.height()
+		sel := i == o.selected
+		if r.hunk == nil {
+			put(r.file.rel, st.body.Bold(true), sel)
+			continue
+		}
+		mark, style := "[x]", st.body
+		if !r.hunk.include {
+			mark, style = "[ ]", st.dim
+		}
+		num := fmt.Sprintf("%s %4d - ", mark, r.hunk.line+1)
+		put(num+strings.TrimRight(r.hunk.old, "\r"), style, sel)
+		put(strings.Repeat(" ", len(num)-2)+"+ "+strings.TrimRight(r.hunk.new, "\r"), style, false)
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, totalLines, visible, o.scroll)
+	ui.PutString(a.screen, x+2, y+h-2, x+w-2, tr("replace.hint"), st.dim)
+}
+
+func (o *replaceOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyUp:
+		o.selected--
+	case tcell.KeyDown:
+		o.selected++
+	case tcell.KeyPgUp:
+		o.selected -= 10
+	case tcell.KeyPgDn:
+		o.selected += 10
+	case tcell.KeyHome:
+		o.selected = 0
+	case tcell.KeyEnd:
+		o.selected = len(o.rows) - 1
+	case tcell.KeyEnter:
+		a.popOverlay()
+		a.finishReplace(o.files)
+		return false
+	case tcell.KeyRune:
+		if ev.Rune() == ' ' {
+			o.toggle(o.rows[o.selected])
+		}
+	}
+	if o.selected >= len(o.rows) {
+		o.selected = len(o.rows) - 1
+	}
+	if o.selected < 0 {
+		o.selected = 0
+	}
+	return false
+}
+
+// Пробел: исключить или вернуть замену (на заголовке — все замены файла)
+func (o *replaceOverlay) toggle(r replaceRow) {
+	if r.hunk != nil {
+		r.hunk.include = !r.hunk.include
+		return
+	}
+	on := false
+	for _, h := range r.file.hunks {
+		if !h.include {
+			on = true
+		}
+	}
+	for _, h := range r.file.hunks {
+		h.include = on
+	}
+}
+
+// Применить замены, обновить открытые буферы и показать итог
+func (a *App) finishReplace(files []*replaceFile) {
+	backup, err := applyReplace(files)
+	// переписанные файлы изменены нами, а не снаружи (см. checkDiskChanges)
+	defer a.syncDiskTimes(files)
+	if err != nil && backup != "" {
+		a.notify(levelError, tr("replace.failed_backup"), err, backup)
+		return
+	}
+	if err != nil {
+		a.notify(levelError, tr("replace.failed"), err)
+		return
+	}
+	if backup == "" {
+		a.notify(levelInfo, "%s", tr("replace.nothing"))
+		return
+	}
+	var b strings.Builder
+	touched, replaced := 0, 0
+	for _, f := range files {
+		n := 0
+		for _, h := range f.hunks {
+			if h.include {
+				n += h.count
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		touched++
+		replaced += n
+		fmt.Fprintf(&b, "%s: %d\n", f.rel, n)
+		// открытые без правок буферы показывают новый текст; замена
+		// в них — отдельный шаг отмены, как перечитывание после
+		// внешнего редактора: Ctrl+Z вернёт старый текст несохранённым
+		for _, v := range a.views {
+			if v.buf.path != f.path || v.buf.Modified {
+				continue
+			}
+			if v.buf.Content != f.content {
+				a.undoCheckpointView(v)
+				v.buf.Content = f.content
+			}
+			a.clampViewCursor(v)
+			a.undoCheckpointView(v)
+		}
+	}
+	b.WriteString("\n" + trf("replace.backup", backup))
+	a.showText(trf("replace.done", replaced, touched), b.String())
+}
+
+// Запомнить время изменения переписанных файлов у открытых буферов
+func (a *App) syncDiskTimes(files []*replaceFile) {
+	for _, f := range files {
+		if !f.written {
+			continue
+		}
+		for _, v := range a.views {
+			if v.buf.path == f.path {
+				v.buf.diskTime = fileModTime(f.path)
+			}
+		}
+	}
+}
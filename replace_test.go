@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// Папка с файлами для замены: имя — текст и права
+func replaceDir(t *testing.T) (string, map[string]os.FileMode) {
+	dir := t.TempDir()
+	files := map[string]struct {
+		text string
+		mode os.FileMode
+	}{
+		"a.md":     {"foo one\nbar\nfoo two", 0600},
+		"sub/b.md": {"x foo", 0644},
+		"c.md":     {"nothing here", 0644},
+	}
+	modes := map[string]os.FileMode{}
+	for name, f := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f.text), f.mode); err != nil {
+			t.Fatal(err)
+		}
+		modes[name] = f.mode
+	}
+	return dir, modes
+}
+
+func readText(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// Файлы папки (кроме самих заменяемых): временные не должны оставаться
+func strayFiles(t *testing.T, dir string) []string {
+	var names []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			if !slices.Contains([]string{"a.md", filepath.Join("sub", "b.md"), "c.md"}, rel) {
+				names = append(names, rel)
+			}
+		}
+		return nil
+	})
+	return names
+}
+
+func TestApplyReplace(t *testing.T) {
+	a := newTestApp(t, nil)
+	dir, modes := replaceDir(t)
+	files, _ := a.scanReplace(dir, regexp.MustCompile("foo"), "baz", searchOptions{})
+	if len(files) != 2 {
+		t.Fatalf("%d files to replace, want 2", len(files))
+	}
+	// вторую замену в a.md пропускаем
+	for _, f := range files {
+		if f.rel == "a.md" {
+			f.hunks[1].include = false
+		}
+	}
+
+	backup, err := applyReplace(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a.md": "baz one\nbar\nfoo two", filepath.Join("sub", "b.md"): "x baz", "c.md": "nothing here"}
+	for name, text := range want {
+		if got := readText(t, filepath.Join(dir, name)); got != text {
+			t.Errorf("%s = %q, want %q", name, got, text)
+		}
+	}
+	if stray := strayFiles(t, dir); len(stray) > 0 {
+		t.Errorf("temporary files left: %q", stray)
+	}
+
+	// копии оригиналов в папке состояния, с правами оригиналов
+	if rel, err := filepath.Rel(stateDir(), backup); err != nil || !filepath.IsLocal(rel) {
+		t.Errorf("backup %s is outside %s", backup, stateDir())
+	}
+	for name, orig := range map[string]string{"a.md": "foo one\nbar\nfoo two", filepath.Join("sub", "b.md"): "x foo"} {
+		path := filepath.Join(backup, name)
+		if got := readText(t, path); got != orig {
+			t.Errorf("backup of %s = %q, want %q", name, got, orig)
+		}
+		for _, p := range []string{path, filepath.Join(dir, name)} {
+			info, err := os.Stat(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != modes[filepath.ToSlash(name)] {
+				t.Errorf("%s mode = %v, want %v", p, info.Mode().Perm(), modes[filepath.ToSlash(name)])
+			}
+		}
+	}
+}
+
+func TestApplyReplaceRollback(t *testing.T) {
+	a := newTestApp(t, nil)
+	dir, _ := replaceDir(t)
+	files, _ := a.scanReplace(dir, regexp.MustCompile("foo"), "baz", searchOptions{})
+	if len(files) != 2 {
+		t.Fatalf("%d files to replace, want 2", len(files))
+	}
+	orig := map[string]string{}
+	for _, f := range files {
+		orig[f.path] = f.content
+	}
+
+	// второе переименование не удаётся, первое уже сделано
+	renames := 0
+	failed := errors.New("rename failed")
+	renameFile = func(from, to string) error {
+		renames++
+		if renames == 2 {
+			return failed
+		}
+		return os.Rename(from, to)
+	}
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	backup, err := applyReplace(files)
+	if !errors.Is(err, failed) {
+		t.Fatalf("applyReplace error = %v, want %v", err, failed)
+	}
+	if backup == "" {
+		t.Error("no backup folder reported with the error")
+	}
+	for path, text := range orig {
+		if got := readText(t, path); got != text {
+			t.Errorf("%s = %q after rollback, want %q", path, got, text)
+		}
+	}
+	if !files[0].written || files[1].written {
+		t.Errorf("written = %v %v, want true false", files[0].written, files[1].written)
+	}
+	if stray := strayFiles(t, dir); len(stray) > 0 {
+		t.Errorf("temporary files left: %q", stray)
+	}
+}
+
+func TestApplyReplaceChangedFile(t *testing.T) {
+	a := newTestApp(t, nil)
+	dir, _ := replaceDir(t)
+	files, _ := a.scanReplace(dir, regexp.MustCompile("foo"), "baz", searchOptions{})
+	changed := filepath.Join(dir, "sub", "b.md")
+	if err := os.WriteFile(changed, []byte("x foo!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := applyReplace(files); err == nil {
+		t.Fatal("applyReplace succeeded on a file changed after the scan")
+	}
+	if got := readText(t, filepath.Join(dir, "a.md")); got != "foo one\nbar\nfoo two" {
+		t.Errorf("a.md = %q, nothing should be written", got)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir(), "backups")); !os.IsNotExist(err) {
+		t.Errorf("backups written for a cancelled replace: %v", err)
+	}
+}
+
+func TestFinishReplaceUndo(t *testing.T) {
+	a := newTestApp(t, nil)
+	dir, _ := replaceDir(t)
+	path := filepath.Join(dir, "a.md")
+	a.openFile(path)
+	a.activePanel, a.view.mode = "right", "edit"
+	a.undoCheckpoint()
+	files, _ := a.scanReplace(dir, regexp.MustCompile("foo"), "baz", searchOptions{})
+
+	a.finishReplace(files)
+	if got := a.view.buf.Content; got != "baz one\nbar\nbaz two" || a.view.buf.Modified {
+		t.Fatalf("buffer after replace = %q modified=%v", got, a.view.buf.Modified)
+	}
+	// замена отменяется одним шагом, и текст снова расходится с диском
+	a.undo()
+	if got := a.view.buf.Content; got != "foo one\nbar\nfoo two" || !a.view.buf.Modified {
+		t.Errorf("buffer after undo = %q modified=%v", got, a.view.buf.Modified)
+	}
+	a.redo()
+	if got := a.view.buf.Content; got != readText(t, path) {
+		t.Errorf("buffer after redo = %q, file %q", got, readText(t, path))
+	}
+}
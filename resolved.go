@@ -0,0 +1,303 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+	"github.com/StasKrav/eddy_tcell/pkg/mdrender"
+)
+
+// ---- Готовые стили темы ----
+//
+// Тема переводится в tcell.Style один раз — при загрузке и перезагрузке
+// (applyTheme). Отрисовка берёт готовые стили из a.getStyles() и не
+// разбирает цвета заново для каждого символа на каждом кадре.
+
+// Наложение StyleSpec с уже разобранными цветами (см. overlayStyle)
+type styleOverlay struct {
+	fg, bg       tcell.Color
+	setFG, setBG bool
+	attrs        tcell.AttrMask
+}
+
+func newStyleOverlay(spec theme.StyleSpec) styleOverlay {
+	o := styleOverlay{setFG: spec.FG != "", setBG: spec.BG != ""}
+	if o.setFG {
+		o.fg = theme.ParseColor(spec.FG)
+	}
+	if o.setBG {
+		o.bg = theme.ParseColor(spec.BG)
+	}
+	if spec.Bold {
+		o.attrs |= tcell.AttrBold
+	}
+	if spec.Italic {
+		o.attrs |= tcell.AttrItalic
+	}
+	if spec.Underline {
+		o.attrs |= tcell.AttrUnderline
+	}
+	if spec.Reverse {
+		o.attrs |= tcell.AttrReverse
+	}
+	return o
+}
+
+// Наложить поверх базового стиля: только заданные поля
+func (o styleOverlay) apply(base tcell.Style) tcell.Style {
+	if o.setFG {
+		base = base.Foreground(o.fg)
+	}
+	if o.setBG {
+		base = base.Background(o.bg)
+	}
+	if o.attrs != 0 {
+		_, _, attrs := base.Decompose()
+		base = base.Attributes(attrs | o.attrs)
+	}
+	return base
+}
+
+// ResolvedTheme — стили темы, готовые к отрисовке
+type ResolvedTheme struct {
+	// Текст цветом foreground без фона (основа предпросмотра)
+	Text tcell.Style
+	// Рамка левой панели и разделитель окон
+	Border  tcell.Style
+	Divider tcell.Style
+	// Цвета заголовков панелей: [правая][активная]
+	Titles [2][2]tcell.Color
+	// Строки списка файлов: [каталог][выделена]
+	FileRows [2][2]tcell.Style
+	// Полоса прокрутки
+	ScrollTrack, ScrollThumb tcell.Style
+	// Наложения в редакторе: курсор, выделение, строка курсора, направляющая,
+	// ошибки, парные скобки
+	Cursor     styleOverlay
+	Selection  styleOverlay
+	CursorLine styleOverlay
+	Ruler      styleOverlay
+	Spell      styleOverlay
+	Bracket    styleOverlay
+	// блоки конфликтов слияния: маркеры, наша и их сторона
+	Conflict [3]styleOverlay
+	// Статусная строка и цвета сегментов panel/mode
+	Statusbar       tcell.Style
+	LeftFG, RightFG tcell.Color
+	Segments        map[string]styleOverlay
+	Notify          [levelError + 1]tcell.Style
+	Dialog          dialogStyles
+	Markdown        mdrender.Styles
+	Filetype        map[string]filetypeStyles
+}
+
+// Стиль текста редактора для типа файла; filled — задан свой фон
+type filetypeStyles struct {
+	text   tcell.Style
+	filled bool
+}
+
+// Перевести тему в готовые стили
+func compileTheme(t *theme.Theme) *ResolvedTheme {
+	ui := t.UI
+	r := &ResolvedTheme{
+		Text:      tcell.StyleDefault.Foreground(theme.ParseColor(ui.Foreground)),
+		Divider:   tcell.StyleDefault.Foreground(theme.ParseColor(ui.LeftPanel.FG)),
+		Statusbar: tcell.StyleDefault.Foreground(theme.ParseColor(ui.Statusbar.FG)),
+		LeftFG:    theme.ParseColor(ui.LeftPanel.FG),
+		RightFG:   theme.ParseColor(ui.RightPanel.FG),
+		Segments:  map[string]styleOverlay{},
+		Filetype:  map[string]filetypeStyles{},
+
+		ScrollTrack: tcell.StyleDefault.Foreground(theme.ParseColor(ui.Scrollbar.Track)),
+		ScrollThumb: tcell.StyleDefault.Foreground(theme.ParseColor(ui.Scrollbar.Thumb)),
+		Cursor:      styleOverlay{fg: theme.ParseColor(ui.RightPanel.FG), bg: theme.ParseColor(ui.Cursor), setFG: true, setBG: true},
+		CursorLine:  newStyleOverlay(ui.CursorLine),
+		Ruler:       newStyleOverlay(ui.Ruler),
+		Spell:       newStyleOverlay(ui.Spell),
+		Bracket:     newStyleOverlay(ui.Bracket),
+		Conflict: [3]styleOverlay{
+			newStyleOverlay(ui.Conflict.Marker),
+			newStyleOverlay(ui.Conflict.Ours),
+			newStyleOverlay(ui.Conflict.Theirs),
+		},
+	}
+
+	border := theme.ParseColor(ui.LeftPanel.FG)
+	if border == tcell.ColorDefault {
+		border = theme.ParseColor(ui.Foreground)
+	}
+	r.Border = tcell.StyleDefault.Foreground(border)
+	for active := 0; active < 2; active++ {
+		r.Titles[0][active] = panelTitleColor(t, active == 1, "")
+		r.Titles[1][active] = panelTitleColor(t, active == 1, ui.RightPanel.FG)
+	}
+	for dir := 0; dir < 2; dir++ {
+		for sel := 0; sel < 2; sel++ {
+			r.FileRows[dir][sel] = fileRowStyle(t, dir == 1, sel == 1)
+		}
+	}
+	// без selection_bg (режим без цветов) выделение — инверсией
+	if ui.SelectionBG == "" {
+		r.Selection = styleOverlay{attrs: tcell.AttrReverse}
+	} else {
+		r.Selection = styleOverlay{bg: theme.ParseColor(ui.SelectionBG), setBG: true}
+	}
+	for name, spec := range ui.StatusSegments {
+		r.Segments[name] = newStyleOverlay(spec)
+	}
+
+	r.Notify[levelDebug] = styleFromSpec(ui.Notify.Info, ui)
+	r.Notify[levelInfo] = styleFromSpec(ui.Notify.Info, ui)
+	r.Notify[levelSuccess] = styleFromSpec(ui.Notify.Success, ui)
+	r.Notify[levelWarning] = styleFromSpec(ui.Notify.Warning, ui)
+	r.Notify[levelError] = styleFromSpec(ui.Notify.Error, ui)
+
+	body := styleFromSpec(ui.Dialog.Body, ui)
+	r.Dialog = dialogStyles{
+		body:     body,
+		border:   overlayStyle(body, ui.Dialog.Border),
+		selected: overlayStyle(body, ui.Dialog.Selected),
+		input:    overlayStyle(body, ui.Dialog.Input),
+		dim:      body.Foreground(theme.ParseColor(ui.Statusbar.FG)),
+	}
+
+	md := t.Markdown
+	r.Markdown = mdrender.Styles{
+		Text:       r.Text,
+		H1:         styleFromSpec(md.H1, ui),
+		H2:         styleFromSpec(md.H2, ui),
+		H3:         styleFromSpec(md.H3, ui),
+		InlineCode: styleFromSpec(md.InlineCode, ui),
+		CodeBlock:  styleFromSpec(md.CodeBlock, ui),
+		Link:       styleFromSpec(md.Link, ui),
+		ListMarker: styleFromSpec(md.ListMarker, ui),
+		Blockquote: styleFromSpec(md.Blockquote, ui),
+		Math:       styleFromSpec(md.Math, ui),
+		Callout:    map[string]mdrender.CalloutStyles{},
+	}
+	for kind, cs := range md.Callout.ByKind() {
+		r.Markdown.Callout[kind] = mdrender.CalloutStyles{
+			Title: styleFromSpec(cs.Title, ui),
+			Body:  styleFromSpec(cs.Body, ui),
+		}
+	}
+
+	for ext, ft := range t.Filetype {
+		r.Filetype[ext] = filetypeStyles{
+			text:   overlayStyle(tcell.StyleDefault, theme.StyleSpec{FG: ft.FG, BG: ft.BG}),
+			filled: ft.BG != "",
+		}
+	}
+	return r
+}
+
+// Стиль текста редактора для файла; true — задан свой фон, окно нужно залить
+func (r *ResolvedTheme) filetypeStyle(path string) (tcell.Style, bool) {
+	if ft, ok := r.Filetype[theme.FiletypeKey(path)]; ok {
+		return ft.text, ft.filled
+	}
+	return tcell.StyleDefault, false
+}
+
+// Стиль строки списка файлов
+func (r *ResolvedTheme) fileRow(isDir, selected bool) tcell.Style {
+	return r.FileRows[b2i(isDir)][b2i(selected)]
+}
+
+// Цвет заголовка панели
+func (r *ResolvedTheme) title(right, active bool) tcell.Color {
+	return r.Titles[b2i(right)][b2i(active)]
+}
+
+// Стиль уведомления по уровню
+func (r *ResolvedTheme) notify(level msgLevel) tcell.Style {
+	if level < 0 || int(level) >= len(r.Notify) {
+		return r.Notify[levelInfo]
+	}
+	return r.Notify[level]
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Готовые стили текущей темы
+func (a *App) getStyles() *ResolvedTheme {
+	a.themeMu.RLock()
+	defer a.themeMu.RUnlock()
+	if a.styles == nil {
+		return compileTheme(a.theme)
+	}
+	return a.styles
+}
+
+// Преобразование StyleSpec -> tcell.Style (с использованием UI-фоллбеков)
+func styleFromSpec(spec theme.StyleSpec, ui theme.UITheme) tcell.Style {
+	style := tcell.StyleDefault
+
+	// fg
+	fg := spec.FG
+	if fg == "" {
+		fg = ui.Foreground
+	}
+	if fg != "" {
+		style = style.Foreground(theme.ParseColor(fg))
+	}
+
+	// bg
+	bg := spec.BG
+	if bg == "" {
+		bg = ui.Background
+	}
+	if bg != "" {
+		style = style.Background(theme.ParseColor(bg))
+	}
+
+	if spec.Bold {
+		style = style.Bold(true)
+	}
+	if spec.Italic {
+		style = style.Italic(true)
+	}
+	if spec.Underline {
+		style = style.Underline(true)
+	}
+	if spec.Reverse {
+		style = style.Reverse(true)
+	}
+	return style
+
+}
+
+// Наложить StyleSpec поверх базового стиля: применяются только заданные поля
+func overlayStyle(base tcell.Style, spec theme.StyleSpec) tcell.Style {
+	if spec.FG != "" {
+		base = base.Foreground(theme.ParseColor(spec.FG))
+	}
+	if spec.BG != "" {
+		base = base.Background(theme.ParseColor(spec.BG))
+	}
+	if spec.Bold {
+		base = base.Bold(true)
+	}
+	if spec.Italic {
+		base = base.Italic(true)
+	}
+	if spec.Underline {
+		base = base.Underline(true)
+	}
+	if spec.Reverse {
+		base = base.Reverse(true)
+	}
+	return base
+}
+
+// Цвет фона стиля
+func bgOf(style tcell.Style) tcell.Color {
+	_, bg, _ := style.Decompose()
+	return bg
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// ---- Сценарий (--script) ----
+//
+// eddy --script demo.txt [путь] запускает редактор на виртуальном экране
+// tcell и выполняет шаги из файла — по одному на строку, # — комментарий:
+//
+//	size 100 30          размер экрана (по умолчанию 80×24)
+//	open notes.md        открыть файл, папку или адрес
+//	key Ctrl+W v Down    клавиши в записи справки (keyName)
+//	type привет          набрать текст как есть
+//	command app.help     выполнить команду реестра
+//	wait 300ms           дать фоновым задачам дойти до экрана
+//	expect текст         текст должен быть на экране
+//	expect-not текст     текста на экране быть не должно
+//	dump [файл]          записать экран в файл (без файла — в stdout)
+//
+// После каждого шага обрабатываются накопившиеся события и экран,
+// если он изменился, перерисовывается. Первая невыполненная проверка печатает экран в
+// stderr и завершает сценарий с кодом 1, выход (Ctrl+Q) — с кодом 0,
+// остальные шаги не выполняются. state.toml не читается и не
+// пишется, папка настроек не создаётся — настройки можно подменить
+// флагом --config-dir.
+
+// Размер виртуального экрана по умолчанию
+const (
+	scriptWidth  = 80
+	scriptHeight = 24
+)
+
+// Шаг сценария
+type scriptStep struct {
+	line int
+	verb string
+	arg  string
+}
+
+// Прочитать сценарий
+func readScript(path string) ([]scriptStep, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var steps []scriptStep
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		verb, arg, _ := strings.Cut(strings.TrimLeft(line, " \t"), " ")
+		// у type пробелы значимы, остальным аргументам они не нужны
+		if verb != "type" {
+			arg = strings.TrimSpace(arg)
+		}
+		steps = append(steps, scriptStep{line: n, verb: verb, arg: arg})
+	}
+	return steps, sc.Err()
+}
+
+// --script: выполнить сценарий; path — необязательный аргумент запуска
+func runScript(script, path string) error {
+	steps, err := readScript(script)
+	if err != nil {
+		return err
+	}
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	screen.SetSize(scriptWidth, scriptHeight)
+	a := newApp(screen, false, true)
+	defer func() {
+		a.releaseLocks()
+		screen.Fini()
+	}()
+	a.redraw()
+	if path != "" {
+		a.openPath(path)
+	}
+	a.settle()
+	for _, st := range steps {
+		if err := a.scriptStep(st); err != nil {
+			return fmt.Errorf("%s:%d: %w", script, st.line, err)
+		}
+		a.settle()
+		if a.quitting {
+			break
+		}
+	}
+	return nil
+}
+
+// Выполнить шаг
+func (a *App) scriptStep(st scriptStep) error {
+	switch st.verb {
+	case "size":
+		var w, h int
+		if _, err := fmt.Sscanf(st.arg, "%d %d", &w, &h); err != nil || w <= 0 || h <= 0 {
+			return fmt.Errorf(tr("script.bad_size"), st.arg)
+		}
+		a.screen.(tcell.SimulationScreen).SetSize(w, h)
+		a.handleEvent(tcell.NewEventResize(w, h))
+	case "open":
+		a.redraw()
+		a.openPath(st.arg)
+	case "key":
+		for _, name := range strings.Fields(st.arg) {
+			ev, err := parseKeyName(name)
+			if err != nil {
+				return err
+			}
+			a.handleEvent(ev)
+			a.settle()
+			if a.quitting {
+				break
+			}
+		}
+	case "type":
+		for _, r := range st.arg {
+			a.handleEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	case "command":
+		a.redraw()
+		if !a.runCommand(st.arg) {
+			return fmt.Errorf(tr("script.bad_command"), st.arg)
+		}
+		a.undoCheckpoint()
+	case "wait":
+		d, err := time.ParseDuration(st.arg)
+		if err != nil {
+			return err
+		}
+		for deadline := time.Now().Add(d); time.Now().Before(deadline); {
+			a.settle()
+			time.Sleep(10 * time.Millisecond)
+		}
+	case "expect", "expect-not":
+		text := a.screenText()
+		if strings.Contains(text, st.arg) != (st.verb == "expect") {
+			os.Stderr.WriteString(text)
+			if st.verb == "expect" {
+				return fmt.Errorf(tr("script.missing"), st.arg)
+			}
+			return fmt.Errorf(tr("script.present"), st.arg)
+		}
+	case "dump":
+		text := a.screenText()
+		if st.arg == "" {
+			_, err := os.Stdout.WriteString(text)
+			return err
+		}
+		return os.WriteFile(st.arg, []byte(text), 0644)
+	default:
+		return fmt.Errorf(tr("script.bad_step"), st.verb)
+	}
+	return nil
+}
+
+// Обработать накопившиеся события и перерисовать экран, если он
+// изменился — как главный цикл (см. redraw.go)
+func (a *App) settle() {
+	for a.screen.HasPendingEvent() {
+		a.handleEvent(a.screen.PollEvent())
+	}
+	if a.dirty && !a.pasting {
+		a.draw()
+	}
+}
+
+// Содержимое виртуального экрана построчно, без хвостовых пробелов
+func (a *App) screenText() string {
+	cells, w, h := a.screen.(tcell.SimulationScreen).GetContents()
+	var b strings.Builder
+	for y := 0; y < h; y++ {
+		var line strings.Builder
+		for x := 0; x < w; x++ {
+			c := cells[y*w+x]
+			if len(c.Runes) == 0 {
+				line.WriteByte(' ')
+				continue
+			}
+			line.WriteString(string(c.Runes))
+			// вторая половина широкого символа
+			if runewidth.RuneWidth(c.Runes[0]) == 2 {
+				x++
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Событие клавиши по имени в записи keyName: "Ctrl+S", "Alt+m",
+// "Ctrl+Left", "F2", "Space", "x"
+func parseKeyName(name string) (*tcell.EventKey, error) {
+	mods := tcell.ModNone
+	base := name
+	for {
+		switch {
+		case strings.HasPrefix(base, "Ctrl+") && len(base) > len("Ctrl+"):
+			mods |= tcell.ModCtrl
+			base = strings.TrimPrefix(base, "Ctrl+")
+			continue
+		case strings.HasPrefix(base, "Alt+") && len(base) > len("Alt+"):
+			mods |= tcell.ModAlt
+			base = strings.TrimPrefix(base, "Alt+")
+			continue
+		case strings.HasPrefix(base, "Shift+") && len(base) > len("Shift+"):
+			mods |= tcell.ModShift
+			base = strings.TrimPrefix(base, "Shift+")
+			continue
+		}
+		break
+	}
+	if base == "Space" {
+		base = " "
+	}
+	if r := []rune(base); len(r) == 1 {
+		// Ctrl+буква — отдельный код клавиши, как шлёт терминал
+		if mods&tcell.ModCtrl != 0 && r[0] < 128 {
+			if k, ok := ctrlKey(r[0]); ok {
+				return tcell.NewEventKey(k, 0, mods), nil
+			}
+		}
+		return tcell.NewEventKey(tcell.KeyRune, r[0], mods), nil
+	}
+	switch base {
+	case "Backspace":
+		return tcell.NewEventKey(tcell.KeyBackspace2, 0, mods), nil
+	case "Esc":
+		return tcell.NewEventKey(tcell.KeyEscape, 0, mods), nil
+	}
+	for k, n := range tcell.KeyNames {
+		if n == base && !strings.HasPrefix(n, "Ctrl-") {
+			return tcell.NewEventKey(k, 0, mods), nil
+		}
+	}
+	return nil, fmt.Errorf(tr("script.bad_key"), name)
+}
+
+// Код клавиши Ctrl+буква ("Ctrl+S" → KeyCtrlS)
+func ctrlKey(r rune) (tcell.Key, bool) {
+	switch {
+	case r >= 'A' && r <= 'Z':
+		return tcell.KeyCtrlA + tcell.Key(r-'A'), true
+	case r >= 'a' && r <= 'z':
+		return tcell.KeyCtrlA + tcell.Key(r-'a'), true
+	}
+	for k, n := range tcell.KeyNames {
+		if n == "Ctrl-"+string(r) {
+			return k, true
+		}
+	}
+	return 0, false
+}
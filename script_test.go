@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseKeyName(t *testing.T) {
+	tests := []struct {
+		name string
+		key  tcell.Key
+		r    rune
+		mods tcell.ModMask
+	}{
+		{"x", tcell.KeyRune, 'x', tcell.ModNone},
+		{"Space", tcell.KeyRune, ' ', tcell.ModNone},
+		{"Alt+m", tcell.KeyRune, 'm', tcell.ModAlt},
+		{"Ctrl+S", tcell.KeyCtrlS, 0, tcell.ModCtrl},
+		{"Ctrl+Left", tcell.KeyLeft, 0, tcell.ModCtrl},
+		{"Shift+Tab", tcell.KeyTab, 0, tcell.ModShift},
+		{"F2", tcell.KeyF2, 0, tcell.ModNone},
+		{"Backspace", tcell.KeyBackspace2, 0, tcell.ModNone},
+		{"Esc", tcell.KeyEscape, 0, tcell.ModNone},
+	}
+	for _, tt := range tests {
+		ev, err := parseKeyName(tt.name)
+		if err != nil {
+			t.Errorf("parseKeyName(%q): %v", tt.name, err)
+			continue
+		}
+		if ev.Key() != tt.key || ev.Modifiers() != tt.mods || (tt.key == tcell.KeyRune && ev.Rune() != tt.r) {
+			t.Errorf("parseKeyName(%q) = %v %q %v, want %v %q %v", tt.name, ev.Key(), ev.Rune(), ev.Modifiers(), tt.key, tt.r, tt.mods)
+		}
+	}
+	for _, name := range []string{"Hyper+x", "Ctrl+", "Nope"} {
+		if _, err := parseKeyName(name); err == nil {
+			t.Errorf("parseKeyName(%q): no error", name)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	old := configDirFlag
+	configDirFlag = t.TempDir()
+	t.Cleanup(func() { configDirFlag = old })
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	dir := t.TempDir()
+	note := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(note, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dump := filepath.Join(dir, "screen.txt")
+
+	tests := []struct {
+		name   string
+		script string
+		err    string // начало ошибки ("" — сценарий проходит)
+	}{
+		{"edit and save", `
+size 60 16
+# Tab: из просмотра в правку
+key Tab
+type X
+key Ctrl+S
+expect Saved: note.md
+expect-not [+]
+dump ` + dump, ""},
+		{"failed expect", "size 60 16\n\nexpect no such text\ntype never\n", "s.txt:3: "},
+		{"unknown step", "jump 5\n", "s.txt:1: "},
+		{"bad key", "key Hyper+x\n", "s.txt:1: "},
+	}
+	for _, tt := range tests {
+		script := filepath.Join(t.TempDir(), "s.txt")
+		if err := os.WriteFile(script, []byte(tt.script), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := runScript(script, note)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.HasPrefix(err.Error(), strings.Replace(tt.err, "s.txt", script, 1))):
+			t.Errorf("%s: error = %v, want %q…", tt.name, err, tt.err)
+		}
+	}
+
+	// после всех сценариев в файле только правка из первого
+	if data, _ := os.ReadFile(note); string(data) != "hello\nX" {
+		t.Errorf("note.md = %q, want %q", data, "hello\nX")
+	}
+	screen, err := os.ReadFile(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(screen), "Mode: edit") || !strings.Contains(string(screen), "hello") {
+		t.Errorf("dumped screen:\n%s", screen)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Поиск в редакторе (Ctrl+F, F3 / Shift+F3) ----
+//
+// В окне поиска Alt+C переключает учёт регистра, Alt+W — поиск целых
+// слов, Alt+R — регулярные выражения; включённые параметры видны под
+// строкой ввода. Запрос и параметры запоминаются до следующего поиска,
+// Up/Down листают прошлые запросы (см. history.go).
+// Найденный текст выделяется, F3 и Shift+F3 ищут дальше и назад.
+// В предпросмотре поиск свой, см. previewsearch.go.
+
+// Параметры поиска
+type searchOptions struct {
+	ignoreCase bool
+	wholeWord  bool
+	regex      bool
+}
+
+// Последний поиск
+type searchState struct {
+	query string
+	opts  searchOptions
+}
+
+// Регулярное выражение для запроса
+func (o searchOptions) compile(query string) (*regexp.Regexp, error) {
+	expr := query
+	if !o.regex {
+		expr = regexp.QuoteMeta(query)
+	}
+	if o.ignoreCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// Символ слова (буква, цифра, _)
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Совпадения в строке: пары [начало, конец) в рунах. Границы слов
+// проверяем сами: \b в regexp понимает только ASCII.
+func findInLine(re *regexp.Regexp, line string, wholeWord bool) [][2]int {
+	runes := []rune(line)
+	// байтовое смещение -> индекс руны
+	idx := make([]int, len(line)+1)
+	n := 0
+	for i := range line {
+		idx[i] = n
+		n++
+	}
+	idx[len(line)] = n
+	var out [][2]int
+	for _, m := range re.FindAllStringIndex(line, -1) {
+		if m[0] == m[1] {
+			continue // пустые совпадения не показываем
+		}
+		s, e := idx[m[0]], idx[m[1]]
+		if wholeWord && ((s > 0 && isIdentRune(runes[s-1])) || (e < len(runes) && isIdentRune(runes[e]))) {
+			continue
+		}
+		out = append(out, [2]int{s, e})
+	}
+	return out
+}
+
+// Найти следующее (или предыдущее) совпадение от курсора, по кругу.
+// Возвращает строку, начало и конец в рунах.
+func findNext(lines []string, re *regexp.Regexp, wholeWord bool, y, x int, backward bool) (int, int, int, bool) {
+	n := len(lines)
+	for i := 0; i <= n; i++ {
+		ly := y + i
+		if backward {
+			ly = y - i
+		}
+		ly = ((ly % n) + n) % n
+		ms := findInLine(re, lines[ly], wholeWord)
+		if backward {
+			for j := len(ms) - 1; j >= 0; j-- {
+				if i > 0 || ms[j][0] < x {
+					return ly, ms[j][0], ms[j][1], true
+				}
+			}
+			continue
+		}
+		for _, m := range ms {
+			if i > 0 || m[0] >= x {
+				return ly, m[0], m[1], true
+			}
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// Перейти к совпадению последнего поиска и выделить его
+func (a *App) searchAgain(backward bool) {
+	if a.search.query == "" {
+		a.openSearch()
+		return
+	}
+	re, err := a.search.opts.compile(a.search.query)
+	if err != nil {
+		a.notify(levelWarning, tr("search.bad_regex"), err)
+		return
+	}
+	if a.view.mode == "preview" {
+		a.searchPreview(re, backward)
+		return
+	}
+	v := a.view
+	x := v.editX
+	if backward {
+		// с начала выделенного совпадения, чтобы не найти его же
+		if r, ok := v.selection(); ok {
+			x = r.sx
+		}
+	}
+	y, s, e, ok := findNext(v.buf.Lines(), re, a.search.opts.wholeWord, v.editY, x, backward)
+	if !ok {
+		a.notify(levelInfo, tr("search.not_found"), a.search.query)
+		return
+	}
+	v.selecting = true
+	v.selY, v.selX = y, s
+	v.editY, v.editX = y, e
+	a.activePanel = "right"
+	a.ensureCursorVisible()
+}
+
+// ---- Окно поиска ----
+
+type searchOverlay struct {
+	input   inputLine
+	history historyNav
+	opts    searchOptions
+}
+
+// Открыть окно поиска с последним запросом и параметрами
+func (a *App) openSearch() {
+	a.pushOverlay(&searchOverlay{
+		input:   newInputLine(a.search.query),
+		history: newHistoryNav(a, historySearch),
+		opts:    a.search.opts,
+	})
+}
+
+func (s *searchOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := a.centeredRect(60, 4)
+	ui.Box(a.screen, x, y, w, h, " "+tr("search.title")+" ", st.border, st.body)
+	a.drawInputLine(&s.input, x+2, y+1, w-4, st.input)
+
+	// параметры: включённые выделены
+	col := x + 2
+	for _, o := range []struct {
+		on    bool
+		label string
+	}{
+		{!s.opts.ignoreCase, tr("search.opt.case")},
+		{s.opts.wholeWord, tr("search.opt.word")},
+		{s.opts.regex, tr("search.opt.regex")},
+	} {
+		style, mark := st.dim, "[ ] "
+		if o.on {
+			style, mark = st.body.Bold(true), "[x] "
+		}
+		col = ui.PutString(a.screen, col, y+2, x+w-2, mark+o.label, style) + 2
+	}
+	if _, err := s.opts.compile(s.input.String()); err != nil && s.opts.regex {
+		ui.PutString(a.screen, col, y+2, x+w-2, tr("search.bad"), st.dim)
+	}
+}
+
+func (s *searchOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	if ev.Key() == tcell.KeyRune && ev.Modifiers()&tcell.ModAlt != 0 {
+		switch unicode.ToLower(ev.Rune()) {
+		case 'c':
+			s.opts.ignoreCase = !s.opts.ignoreCase
+		case 'w':
+			s.opts.wholeWord = !s.opts.wholeWord
+		case 'r':
+			s.opts.regex = !s.opts.regex
+		}
+		a.search.opts = s.opts
+		return false
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyEnter:
+		query := s.input.String()
+		if _, err := s.opts.compile(query); err != nil {
+			a.notify(levelWarning, tr("search.bad_regex"), err)
+			return false
+		}
+		a.search = searchState{query: query, opts: s.opts}
+		a.addHistory(historySearch, query)
+		a.popOverlay()
+		if strings.TrimSpace(query) != "" {
+			a.searchAgain(false)
+		}
+		return false
+	}
+	if s.history.handleKey(a, ev, &s.input) {
+		return false
+	}
+	s.input.handleKey(ev)
+	return false
+}
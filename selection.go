@@ -0,0 +1,101 @@
+package main
+
+import "github.com/gdamore/tcell/v2"
+
+// ---- Выделение текста в редакторе ----
+//
+// Shift+стрелки, Shift+Home/End выделяют текст от точки, где началось
+// выделение, до курсора. Выделение рисуется цветом ui.selection_bg.
+// Ввод, Enter, Backspace и Delete заменяют выделенный текст, Ctrl+C
+// копирует его в буфер обмена. Движение без Shift снимает выделение.
+
+// Начать выделение от курсора (если оно ещё не начато)
+func (v *editorView) startSelection() {
+	if !v.selecting {
+		v.selecting = true
+		v.selX, v.selY = v.editX, v.editY
+	}
+}
+
+// Снять выделение
+func (v *editorView) clearSelection() {
+	v.selecting = false
+}
+
+// Выделенный диапазон: от (sy, sx) включительно до (ey, ex) исключительно
+type selRange struct {
+	sy, sx, ey, ex int
+}
+
+// Попадает ли руна col строки line в диапазон
+func (r selRange) contains(line, col int) bool {
+	if line < r.sy || line > r.ey {
+		return false
+	}
+	if line == r.sy && col < r.sx {
+		return false
+	}
+	return line != r.ey || col < r.ex
+}
+
+// Выделение по порядку; ok=false — выделения нет (или текст изменился так,
+// что оно вышло за пределы буфера)
+func (v *editorView) selection() (selRange, bool) {
+	if !v.selecting || (v.selX == v.editX && v.selY == v.editY) {
+		return selRange{}, false
+	}
+	r := selRange{v.selY, v.selX, v.editY, v.editX}
+	if r.sy > r.ey || (r.sy == r.ey && r.sx > r.ex) {
+		r = selRange{r.ey, r.ex, r.sy, r.sx}
+	}
+	lines := v.buf.Lines()
+	if r.ey >= len(lines) || r.sx > len([]rune(lines[r.sy])) || r.ex > len([]rune(lines[r.ey])) {
+		return selRange{}, false
+	}
+	return r, true
+}
+
+// Выделенный текст
+func (v *editorView) selectedText() string {
+	r, ok := v.selection()
+	if !ok {
+		return ""
+	}
+	return v.buf.Text(r.sy, r.sx, r.ey, r.ex)
+}
+
+// Удалить выделенный текст; курсор встаёт на начало. false — выделения
+// не было (или окно только для чтения).
+func (a *App) deleteSelection() bool {
+	v := a.view
+	if _, ok := v.selection(); ok && !a.checkWritable() {
+		return false
+	}
+	r, ok := v.selection()
+	v.clearSelection()
+	if !ok {
+		return false
+	}
+	v.editY, v.editX = v.buf.DeleteRange(r.sy, r.sx, r.ey, r.ex)
+	a.ensureCursorVisible()
+	return true
+}
+
+// Клавиши перемещения курсора, которые с Shift расширяют выделение
+func isSelectionMoveKey(k tcell.Key) bool {
+	switch k {
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight, tcell.KeyHome, tcell.KeyEnd:
+		return true
+	}
+	return false
+}
+
+// Копировать выделение в буфер обмена
+func (a *App) copySelection() {
+	text := a.view.selectedText()
+	if text == "" {
+		return
+	}
+	a.yank(text)
+	a.notify(levelInfo, tr("selection.copied"), len([]rune(text)))
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Настройки (Alt+,) ----
+//
+// Список простых настроек config.toml (строки, числа, флаги) с текущими
+// значениями. Внизу — допустимые значения выбранной. Enter — изменить
+// (выбор из списка, ввод или переключение флага), пробел — переключить
+// флаг или следующий вариант, Del — значение по умолчанию. Ctrl+S
+// записывает изменённые ключи в config.toml (остальной текст файла не
+// трогается) и сразу применяет их, Esc — отмена. Списки и таблицы
+// (словари, экспорт, хуки) правятся в самом файле.
+
+// Допустимые значения настроек-вариантов
+var settingChoices = map[string][]string{
+	"language":               {"auto", "en", "ru"},
+	"colors":                 {"auto", "truecolor", "256", "16"},
+	"background":             {"auto", "light", "dark"},
+	"accessibility.no_color": {"auto", "on", "off"},
+}
+
+type settingsOverlay struct {
+	cfg      Config // редактируемая копия
+	keys     []string
+	changed  map[string]bool
+	selected int
+	scroll   int
+}
+
+// Открыть настройки
+func (a *App) openSettings() {
+	a.pushOverlay(&settingsOverlay{cfg: a.config, keys: settingKeys(), changed: map[string]bool{}})
+}
+
+// Ключи простых настроек в порядке полей Config ("editor.ruler")
+func settingKeys() []string {
+	var keys []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := f.Tag.Get("toml")
+			if name == "" {
+				continue
+			}
+			switch f.Type.Kind() {
+			case reflect.Struct:
+				walk(f.Type, prefix+name+".")
+			case reflect.String, reflect.Bool, reflect.Int, reflect.Float64:
+				keys = append(keys, prefix+name)
+			}
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return keys
+}
+
+// Поле настройки key в cfg
+func settingField(cfg *Config, key string) reflect.Value {
+	v := reflect.ValueOf(cfg).Elem()
+	for _, name := range strings.Split(key, ".") {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Tag.Get("toml") == name {
+				v = v.Field(i)
+				break
+			}
+		}
+	}
+	return v
+}
+
+// Значение для показа
+func settingText(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "[x]"
+		}
+		return "[ ]"
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// Допустимые значения для подсказки
+func settingHint(key string, v reflect.Value) string {
+	if choices := settingChoices[key]; choices != nil {
+		return strings.Join(choices, " · ")
+	}
+	switch {
+	case key == "panel.width":
+		return tr("settings.type_width")
+	case v.Kind() == reflect.Bool:
+		return tr("settings.type_bool")
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Float64:
+		return tr("settings.type_number")
+	}
+	return tr("settings.type_text")
+}
+
+// Разобрать ввод для настройки key; false — значение недопустимо
+func parseSetting(key string, v reflect.Value, text string) (interface{}, bool) {
+	text = strings.TrimSpace(text)
+	switch v.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(text)
+		return n, err == nil && n >= 0
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		return f, err == nil && f >= 0
+	}
+	if key == "panel.width" {
+		_, err := parsePanelSize(text)
+		return text, err == nil
+	}
+	return text, true
+}
+
+// Задать значение
+func (e *settingsOverlay) set(key string, value interface{}) {
+	f := settingField(&e.cfg, key)
+	nv := reflect.ValueOf(value).Convert(f.Type())
+	if f.Interface() == nv.Interface() {
+		return
+	}
+	f.Set(nv)
+	e.changed[key] = true
+}
+
+func (e *settingsOverlay) rect(a *App) (x, y, w, h int) {
+	return a.centeredRect(72, a.height-2)
+}
+
+func (e *settingsOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := e.rect(a)
+	if w < 20 || h < 6 {
+		return
+	}
+	title := " " + tr("settings.title") + " "
+	if len(e.changed) > 0 {
+		title = " " + tr("settings.title") + " * "
+	}
+	ui.Box(a.screen, x, y, w, h, title, st.border, st.body)
+
+	visible := h - 5
+	if e.selected < e.scroll {
+		e.scroll = e.selected
+	}
+	if e.selected >= e.scroll+visible {
+		e.scroll = e.selected - visible + 1
+	}
+	keyW := w / 2
+	for i := 0; i < visible && e.scroll+i < len(e.keys); i++ {
+		idx := e.scroll + i
+		key := e.keys[idx]
+		style, dim := st.body, st.dim
+		if idx == e.selected {
+			style, dim = st.selected, st.selected
+			for cx := x + 1; cx < x+w-1; cx++ {
+				a.screen.SetContent(cx, y+1+i, ' ', nil, style)
+			}
+		}
+		label := key
+		if e.changed[key] {
+			label += " *"
+		}
+		ui.PutString(a.screen, x+2, y+1+i, x+keyW, label, style)
+		if text := settingText(settingField(&e.cfg, key)); text != "" {
+			ui.PutString(a.screen, x+keyW+1, y+1+i, x+w-3, text, style)
+		} else {
+			ui.PutString(a.screen, x+keyW+1, y+1+i, x+w-3, "-", dim)
+		}
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, len(e.keys), visible, e.scroll)
+	if len(e.keys) > 0 {
+		key := e.keys[e.selected]
+		ui.PutString(a.screen, x+2, y+h-3, x+w-2, settingHint(key, settingField(&e.cfg, key)), st.dim)
+	}
+	ui.PutString(a.screen, x+2, y+h-2, x+w-2, tr("settings.hint"), st.dim)
+}
+
+func (e *settingsOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	_, _, _, h := e.rect(a)
+	page := h - 5
+	key := e.keys[e.selected]
+	field := settingField(&e.cfg, key)
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		if len(e.changed) == 0 {
+			return true
+		}
+		a.confirm(tr("settings.discard"), a.popOverlay)
+		return false
+	case tcell.KeyCtrlS:
+		if err := a.saveSettings(e.cfg, e.changed); err != nil {
+			a.notify(levelError, tr("config.save_failed"), err)
+			return false
+		}
+		return true
+	case tcell.KeyUp:
+		e.selected--
+	case tcell.KeyDown:
+		e.selected++
+	case tcell.KeyPgUp:
+		e.selected -= page
+	case tcell.KeyPgDn:
+		e.selected += page
+	case tcell.KeyHome:
+		e.selected = 0
+	case tcell.KeyEnd:
+		e.selected = len(e.keys) - 1
+	case tcell.KeyDelete:
+		def := defaultConfig()
+		e.set(key, settingField(&def, key).Interface())
+	case tcell.KeyEnter:
+		e.edit(a, key, field)
+	case tcell.KeyRune:
+		if ev.Rune() == ' ' {
+			e.cycle(key, field)
+		}
+	}
+	e.selected = min(max(e.selected, 0), len(e.keys)-1)
+	return false
+}
+
+// Enter: флаг переключается, вариант выбирается из списка, остальное вводится
+func (e *settingsOverlay) edit(a *App, key string, field reflect.Value) {
+	if field.Kind() == reflect.Bool {
+		e.set(key, !field.Bool())
+		return
+	}
+	if choices := settingChoices[key]; choices != nil {
+		items := make([]listItem, 0, len(choices))
+		current := 0
+		for i, c := range choices {
+			items = append(items, listItem{label: c, value: c})
+			if c == field.String() {
+				current = i
+			}
+		}
+		l := a.pick(key, items, func(item listItem) { e.set(key, item.value) })
+		l.selected = current
+		return
+	}
+	a.prompt(key, settingText(field), func(text string) {
+		value, ok := parseSetting(key, field, text)
+		if !ok {
+			a.notify(levelWarning, tr("settings.bad_value"), text)
+			return
+		}
+		e.set(key, value)
+	})
+}
+
+// Пробел: переключить флаг или взять следующий вариант
+func (e *settingsOverlay) cycle(key string, field reflect.Value) {
+	if field.Kind() == reflect.Bool {
+		e.set(key, !field.Bool())
+		return
+	}
+	choices := settingChoices[key]
+	for i, c := range choices {
+		if c == field.String() {
+			e.set(key, choices[(i+1)%len(choices)])
+			return
+		}
+	}
+	if len(choices) > 0 {
+		e.set(key, choices[0])
+	}
+}
+
+// Записать изменённые ключи в config.toml и применить настройки
+func (a *App) saveSettings(cfg Config, changed map[string]bool) error {
+	for _, key := range settingKeys() {
+		if !changed[key] {
+			continue
+		}
+		if err := setConfigValue(key, settingField(&cfg, key).Interface()); err != nil {
+			return err
+		}
+	}
+	a.config = cfg
+	uiLang = detectLanguage(a.config.Language)
+	theme.ColorLimit = theme.DetectColorLimit(a.config.Colors, a.screen)
+	if changed["panel.width"] {
+		a.applyPanelWidth()
+	}
+	if changed["spell.enabled"] {
+		a.loadSpell()
+	}
+	// цвета, фон и доступность влияют на тему
+	if t, _, _ := a.readTheme(); t != nil {
+		a.applyTheme(t)
+	}
+	a.notify(levelSuccess, tr("settings.saved"), len(changed))
+	return nil
+}
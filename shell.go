@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Запуск shell-команды (Alt+!) ----
+//
+// Команда выполняется через sh -c (в Windows — cmd /C, см. platform_*.go)
+// в текущей папке файловой панели,
+// вывод (stdout и stderr) показывается в прокручиваемом окне.
+// Из окна вывод можно вставить в позицию курсора или вместо
+// выделения (i).
+
+// Сколько ждать завершения команды
+const shellTimeout = 30 * time.Second
+
+// Результат выполнения команды
+type shellResult struct {
+	cmd      string
+	stdout   string // только stdout — для вставки в текст
+	output   string // stdout и stderr вперемешку, как в терминале
+	exitCode int
+	err      error
+}
+
+// Запросить команду и выполнить её
+func (a *App) shellPrompt() {
+	a.promptHistory(historyCommand, "!", "", func(cmd string) {
+		if strings.TrimSpace(cmd) == "" {
+			return
+		}
+		a.notify(levelInfo, tr("shell.running"), cmd)
+		dir := a.currentDir
+		go func() {
+			res := runShell(dir, cmd)
+			a.post(func() { a.showShellResult(res) })
+		}()
+	})
+}
+
+// Выполнить команду и собрать вывод
+func runShell(dir, cmd string) shellResult {
+	return runShellInput(dir, cmd, nil, nil)
+}
+
+// То же с дополнительными переменными окружения и вводом (nil — без stdin)
+func runShellInput(dir, cmd string, env []string, stdin io.Reader) shellResult {
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+
+	c := shellCommand(ctx, cmd)
+	c.Dir = dir
+	if env != nil {
+		c.Env = append(os.Environ(), env...)
+	}
+	c.Stdin = stdin
+	var stdout bytes.Buffer
+	output := &lockedBuffer{}
+	c.Stdout = io.MultiWriter(&stdout, output)
+	c.Stderr = output
+
+	res := shellResult{cmd: cmd}
+	err := c.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		res.err = ctx.Err()
+		res.exitCode = -1
+	case errors.As(err, &exitErr):
+		res.exitCode = exitErr.ExitCode()
+	case err != nil:
+		res.err = err
+		res.exitCode = -1
+	}
+	res.stdout = strings.TrimRight(stdout.String(), "\n")
+	res.output = strings.TrimRight(output.String(), "\n")
+	return res
+}
+
+// Буфер, в который stdout и stderr пишут из разных горутин
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// Показать результат команды
+func (a *App) showShellResult(res shellResult) {
+	if res.err != nil {
+		a.notify(levelError, tr("shell.failed"), res.cmd, res.err)
+	} else if res.exitCode != 0 {
+		a.notify(levelWarning, tr("shell.exit"), res.cmd, res.exitCode)
+	}
+	a.debugf("shell: %q exit %d", res.cmd, res.exitCode)
+
+	title := "$ " + res.cmd
+	if res.exitCode != 0 {
+		title += " " + trf("shell.exit_code", res.exitCode)
+	}
+	a.pushOverlay(&shellOverlay{
+		textViewOverlay: textViewOverlay{title: title, lines: strings.Split(res.output, "\n")},
+		result:          res,
+	})
+}
+
+// Окно вывода команды: как textViewOverlay, плюс вставка вывода
+type shellOverlay struct {
+	textViewOverlay
+	result shellResult
+}
+
+func (s *shellOverlay) draw(a *App) {
+	s.textViewOverlay.draw(a)
+	x, y, w, h := s.rect(a)
+	ui.PutString(a.screen, x+2, y+h-1, x+w-2, " "+tr("shell.hint")+" ", a.dialogStyles().dim)
+}
+
+func (s *shellOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	if ev.Key() == tcell.KeyRune && ev.Rune() == 'i' {
+		if a.view.mode != "edit" {
+			a.notify(levelWarning, "%s", tr("shell.not_edit"))
+			return false
+		}
+		if !a.checkWritable() {
+			return false
+		}
+		a.pasteText(s.result.stdout)
+		a.setActivePanel("right")
+		return true
+	}
+	return s.textViewOverlay.handleKey(a, ev)
+}
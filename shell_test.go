@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestShellOutputInsert(t *testing.T) {
+	tests := []struct {
+		name     string
+		sel      bool // выделено «two»
+		readOnly bool
+		want     string
+	}{
+		{"at cursor", false, false, "one OUTtwo three"},
+		{"replaces selection", true, false, "one OUT three"},
+		{"read-only", true, true, "one two three"},
+	}
+	for _, tt := range tests {
+		a := newTestApp(t, nil)
+		a.view.buf.Content = "one two three"
+		a.view.mode = "edit"
+		a.view.buf.readOnly = tt.readOnly
+		a.view.editY, a.view.editX = 0, 4
+		if tt.sel {
+			a.view.selecting = true
+			a.view.selY, a.view.selX = 0, 4
+			a.view.editX = 7
+		}
+		s := &shellOverlay{result: shellResult{stdout: "OUT"}}
+		s.handleKey(a, tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone))
+		if got := a.view.buf.Content; got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ---- Проверка орфографии ----
+//
+// Словари — файлы .dic в формате hunspell (или просто списки слов, по
+// одному на строку). Правила аффиксов (.aff) не применяются: проверяются
+// только слова, перечисленные в словаре, плюс личный словарь.
+//
+// Ошибки подчёркиваются в режиме edit в Markdown и текстовых файлах;
+// блоки кода, `код`, ссылки и URL не проверяются.
+// F7 — варианты исправления для слова под курсором.
+
+// Сколько вариантов исправления показывать
+const spellSuggestions = 10
+
+type speller struct {
+	words    map[string]bool
+	personal map[string]bool
+	alphabet []rune // буквы из словарей — для генерации исправлений
+}
+
+// Путь к личному словарю
+func personalDictPath() string {
+	return filepath.Join(configDir(), "personal.dic")
+}
+
+// Загрузить словари; nil, если ни одного слова не нашлось
+func loadSpeller(paths []string) (*speller, error) {
+	s := &speller{words: map[string]bool{}, personal: map[string]bool{}}
+	for _, p := range paths {
+		if err := s.loadDict(p, s.words); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+	}
+	if len(s.words) == 0 {
+		return nil, nil
+	}
+	if err := s.loadDict(personalDictPath(), s.personal); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	letters := map[rune]bool{}
+	for w := range s.words {
+		for _, r := range w {
+			letters[r] = true
+		}
+	}
+	for r := range letters {
+		s.alphabet = append(s.alphabet, r)
+	}
+	sort.Slice(s.alphabet, func(i, j int) bool { return s.alphabet[i] < s.alphabet[j] })
+	return s, nil
+}
+
+// Прочитать словарь: "слово/ФЛАГИ" или "слово"; первая строка .dic — число слов
+func (s *speller) loadDict(path string, into map[string]bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.IndexAny(line, "/\t"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.Trim(line, "0123456789") == "" {
+			continue
+		}
+		into[strings.ToLower(line)] = true
+	}
+	return sc.Err()
+}
+
+// Слово написано правильно (или проверять его не нужно)
+func (s *speller) correct(word string) bool {
+	runes := []rune(word)
+	if len(runes) < 2 {
+		return true
+	}
+	// аббревиатуры не проверяем
+	if strings.ToUpper(word) == word {
+		return true
+	}
+	low := strings.ToLower(word)
+	return s.words[low] || s.personal[low]
+}
+
+// Добавить слово в личный словарь
+func (s *speller) addWord(word string) error {
+	low := strings.ToLower(word)
+	s.personal[low] = true
+	path := personalDictPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(low + "\n")
+	return err
+}
+
+// Варианты исправления: слова словаря на расстоянии одной правки
+func (s *speller) suggest(word string) []string {
+	runes := []rune(strings.ToLower(word))
+	cyr := unicode.Is(unicode.Cyrillic, runes[0])
+	seen := map[string]bool{}
+	var res []string
+	try := func(c []rune) {
+		w := string(c)
+		if seen[w] || !(s.words[w] || s.personal[w]) {
+			return
+		}
+		seen[w] = true
+		res = append(res, w)
+	}
+	for i := 0; i <= len(runes); i++ {
+		head, tail := runes[:i], runes[i:]
+		if len(tail) > 0 {
+			try(concatRunes(head, tail[1:])) // удаление
+		}
+		if len(tail) > 1 {
+			try(concatRunes(head, []rune{tail[1], tail[0]}, tail[2:])) // перестановка
+		}
+		for _, r := range s.alphabet {
+			// буквы другой письменности не подставляем
+			if unicode.Is(unicode.Cyrillic, r) != cyr {
+				continue
+			}
+			if len(tail) > 0 {
+				try(concatRunes(head, []rune{r}, tail[1:])) // замена
+			}
+			try(concatRunes(head, []rune{r}, tail)) // вставка
+		}
+	}
+	if len(res) > spellSuggestions {
+		res = res[:spellSuggestions]
+	}
+	// сохраняем заглавную первую букву
+	if first := []rune(word)[0]; unicode.IsUpper(first) {
+		for i, w := range res {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			res[i] = string(r)
+		}
+	}
+	return res
+}
+
+func concatRunes(parts ...[]rune) []rune {
+	var res []rune
+	for _, p := range parts {
+		res = append(res, p...)
+	}
+	return res
+}
+
+// Начинается ли с позиции i адрес (http://, https://, www.)
+func isURLStart(runes []rune, i int) bool {
+	if i > 0 && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+		return false
+	}
+	rest := string(runes[i:min(i+8, len(runes))])
+	return strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://") || strings.HasPrefix(rest, "www.")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '\'' || r == '’'
+}
+
+// Диапазоны рун [начало, конец) слов с ошибками в строке
+func (s *speller) misspelled(line string) [][2]int {
+	runes := []rune(line)
+	var res [][2]int
+	inCode := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '`':
+			inCode = !inCode
+			i++
+		case inCode:
+			i++
+		case r == ']' && i+1 < len(runes) && runes[i+1] == '(':
+			// адрес ссылки [текст](адрес)
+			i += 2
+			for i < len(runes) && runes[i] != ')' {
+				i++
+			}
+		case isURLStart(runes, i):
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+		case unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			end := j
+			for end > i && !unicode.IsLetter(runes[end-1]) {
+				end--
+			}
+			// слова, склеенные с цифрами (v2, 10px), не проверяем
+			digits := (i > 0 && unicode.IsDigit(runes[i-1])) || (j < len(runes) && unicode.IsDigit(runes[j]))
+			if !digits && !s.correct(string(runes[i:end])) {
+				res = append(res, [2]int{i, end})
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return res
+}
+
+// Строки внутри блоков кода ``` / ~~~
+func codeBlockLines(lines []string) []bool {
+	res := make([]bool, len(lines))
+	fence := ""
+	for i, l := range lines {
+		t := strings.TrimSpace(l)
+		if fence == "" {
+			if strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+				fence = t[:3]
+				res[i] = true
+			}
+			continue
+		}
+		res[i] = true
+		if strings.HasPrefix(t, fence) {
+			fence = ""
+		}
+	}
+	return res
+}
+
+// Проверять ли орфографию в буфере: только Markdown и текст
+func spellCheckable(buf *buffer) bool {
+	switch strings.ToLower(filepath.Ext(buf.path)) {
+	case ".md", ".markdown", ".txt", "":
+		return true
+	}
+	return false
+}
+
+// Загрузить словари в фоне (словари бывают большими)
+func (a *App) loadSpell() {
+	a.spell = nil
+	if !a.config.Spell.Enabled {
+		return
+	}
+	paths := a.config.Spell.Dictionaries
+	go func() {
+		s, err := loadSpeller(paths)
+		a.post(func() {
+			if err != nil {
+				a.notify(levelWarning, tr("spell.load_failed"), err)
+				return
+			}
+			if s == nil {
+				a.debugf("spell: no dictionaries found in %v", paths)
+				return
+			}
+			a.debugf("spell: %d words loaded", len(s.words))
+			a.spell = s
+		})
+	}()
+}
+
+// Ошибки в видимых строках окна: номер строки → диапазоны рун
+func (a *App) spellMarks(v *editorView, lines []string, from, to int) map[int][][2]int {
+	if a.spell == nil || v.mode != "edit" || !spellCheckable(v.buf) {
+		return nil
+	}
+	code := codeBlockLines(lines)
+	marks := map[int][][2]int{}
+	for i := from; i < to && i < len(lines); i++ {
+		if code[i] {
+			continue
+		}
+		if m := a.spell.misspelled(lines[i]); len(m) > 0 {
+			marks[i] = m
+		}
+	}
+	return marks
+}
+
+// Попадает ли руна k в один из диапазонов
+func inRanges(ranges [][2]int, k int) bool {
+	for _, r := range ranges {
+		if k >= r[0] && k < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Варианты исправления для слова под курсором (F7)
+func (a *App) spellSuggest() {
+	if a.spell == nil {
+		a.notify(levelWarning, "%s", tr("spell.no_dict"))
+		return
+	}
+	lines := a.getLines()
+	line := lines[a.view.editY]
+	var word [2]int
+	found := false
+	for _, r := range a.spell.misspelled(line) {
+		if a.view.editX >= r[0] && a.view.editX <= r[1] {
+			word, found = r, true
+			break
+		}
+	}
+	if !found {
+		a.notify(levelInfo, "%s", tr("spell.ok"))
+		return
+	}
+	runes := []rune(line)
+	text := string(runes[word[0]:word[1]])
+
+	var items []listItem
+	for _, s := range a.spell.suggest(text) {
+		items = append(items, listItem{label: s, value: s})
+	}
+	items = append(items, listItem{label: trf("spell.add", text)})
+
+	view, y := a.view, a.view.editY
+	a.pick(text, items, func(item listItem) {
+		if item.value == "" {
+			if err := a.spell.addWord(text); err != nil {
+				a.notify(levelError, tr("spell.add_failed"), err)
+			}
+			return
+		}
+		if view.buf.readOnly {
+			a.notify(levelWarning, "%s", tr("readonly.blocked"))
+			return
+		}
+		lines := view.buf.Lines()
+		runes := []rune(lines[y])
+		if word[1] > len(runes) || string(runes[word[0]:word[1]]) != text {
+			return // текст успел измениться
+		}
+		lines[y] = string(runes[:word[0]]) + item.value + string(runes[word[1]:])
+		view.buf.Content = strings.Join(lines, "\n")
+		view.buf.Modified = true
+		view.editX = word[0] + len([]rune(item.value))
+	})
+}
+
+// Стиль подчёркивания ошибки поверх стиля символа
+func (a *App) spellStyle(base tcell.Style) tcell.Style {
+	return a.getStyles().Spell.apply(base)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ---- Состояние интерфейса ----
+//
+// Переключатели, которые неудобно нажимать при каждом запуске, —
+// скрытые файлы ("."), сортировка по размеру (Alt+D), ширина панели
+// (Alt+> / Alt+<), скрытая панель (Ctrl+B) и режим Markdown-файлов
+// (Tab) — запоминаются при выходе в ~/.config/eddy/state.toml и
+// восстанавливаются при следующем запуске. config.toml не меняется.
+
+// Содержимое state.toml
+type uiState struct {
+	ShowHidden  bool   `toml:"show_hidden"`
+	SortBySize  bool   `toml:"sort_by_size"`
+	PanelWidth  string `toml:"panel_width"` // пусто — panel.width из config.toml
+	PanelHidden bool   `toml:"panel_hidden"`
+	// режим, в котором открываются Markdown-файлы: "preview" или "edit"
+	MarkdownMode string `toml:"markdown_mode"`
+}
+
+// Файл состояния
+func statePath() string {
+	return filepath.Join(configDir(), "state.toml")
+}
+
+// Ширина панели в виде panel.width ("30" или "25%")
+func (p panelSize) String() string {
+	if p.percent {
+		return fmt.Sprintf("%d%%", p.n)
+	}
+	return fmt.Sprint(p.n)
+}
+
+// Восстановить состояние (до первого чтения папки)
+func (a *App) loadState() {
+	var st uiState
+	if _, err := toml.DecodeFile(statePath(), &st); err != nil {
+		if !os.IsNotExist(err) {
+			a.debugf("state: %v", err)
+		}
+		return
+	}
+	a.showHidden = st.ShowHidden
+	if st.SortBySize {
+		a.du = &diskUsage{gen: &atomic.Int64{}, bySize: true}
+	}
+	if p, err := parsePanelSize(st.PanelWidth); err == nil {
+		a.panelSize = p
+	}
+	if st.PanelHidden {
+		a.leftWidth = 0
+		a.activePanel = "right"
+	}
+	if st.MarkdownMode == "edit" || st.MarkdownMode == "preview" {
+		a.markdownMode = st.MarkdownMode
+	}
+}
+
+// Запомнить состояние (при выходе). При просмотре stdin и в сценарии
+// не пишется: панель там скрыта не пользователем.
+func (a *App) saveState() {
+	if a.pager || a.headless {
+		return
+	}
+	st := uiState{
+		ShowHidden:   a.showHidden,
+		SortBySize:   a.du != nil && a.du.bySize,
+		PanelHidden:  a.leftWidth == 0,
+		MarkdownMode: a.markdownMode,
+	}
+	// ширина — только если отличается от config.toml
+	if p, err := parsePanelSize(a.config.Panel.Width); err != nil || p != a.panelSize {
+		st.PanelWidth = a.panelSize.String()
+	}
+
+	var b strings.Builder
+	err := toml.NewEncoder(&b).Encode(st)
+	if err == nil {
+		if err = os.MkdirAll(configDir(), 0755); err == nil {
+			err = os.WriteFile(statePath(), []byte(b.String()), 0644)
+		}
+	}
+	if err != nil {
+		a.debugf("state: %v", err)
+	}
+}
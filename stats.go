@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ---- Статистика документа (Alt+s) ----
+
+// Скорость чтения для оценки времени, слов в минуту
+const readingWPM = 200
+
+// Показатели текста
+type textStats struct {
+	words, chars, charsNoSpace, lines, paragraphs int
+}
+
+func computeStats(text string) textStats {
+	st := textStats{
+		words: countWords(text),
+		chars: utf8.RuneCountInString(text),
+	}
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			st.charsNoSpace++
+		}
+	}
+	// абзацы — блоки непустых строк, разделённые пустыми
+	inPara := false
+	for _, line := range strings.Split(text, "\n") {
+		st.lines++
+		if strings.TrimSpace(line) == "" {
+			inPara = false
+			continue
+		}
+		if !inPara {
+			st.paragraphs++
+			inPara = true
+		}
+	}
+	return st
+}
+
+// Окно со статистикой текущего буфера
+func (a *App) showStats() {
+	buf := a.view.buf
+	st := computeStats(buf.Content)
+	minutes := (st.words + readingWPM - 1) / readingWPM
+
+	rows := [][2]string{
+		{tr("stats.words"), fmt.Sprint(st.words)},
+		{tr("stats.chars"), fmt.Sprint(st.chars)},
+		{tr("stats.chars_ns"), fmt.Sprint(st.charsNoSpace)},
+		{tr("stats.lines"), fmt.Sprint(st.lines)},
+		{tr("stats.paragraphs"), fmt.Sprint(st.paragraphs)},
+		{tr("stats.reading"), trf("stats.minutes", minutes)},
+		{tr("stats.session"), fmt.Sprintf("%+d", st.words-buf.openWords)},
+	}
+	width := 0
+	for _, r := range rows {
+		if w := runewidth.StringWidth(r[0]); w > width {
+			width = w
+		}
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		b.WriteString(runewidth.FillRight(r[0], width) + "  " + r[1] + "\n")
+	}
+
+	title := tr("stats.title")
+	if buf.path != "" {
+		title += ": " + filepath.Base(buf.path)
+	}
+	a.showText(title, strings.TrimSuffix(b.String(), "\n"))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// ---- Статусная строка из сегментов ----
+//
+// Набор и порядок сегментов задаются в config.toml:
+//
+// [statusbar]
+// left = ["panel", "mode", "file", "modified"]
+// right = ["position", "percent", "lines", "wordcount"]
+// separator = " | "
+//
+// Стиль каждого сегмента можно переопределить в теме:
+//
+// [ui.status_segments.mode]
+// fg = "#88d4ab"
+// bold = true
+
+// Сегмент статусной строки: текст и стиль. Пустой текст — сегмент скрыт.
+type statusSegment struct {
+	text  string
+	style tcell.Style
+}
+
+// Функция, строящая сегмент по текущему состоянию приложения
+type segmentFunc func(a *App, base tcell.Style) statusSegment
+
+// Доступные сегменты
+var statusSegments = map[string]segmentFunc{
+	"panel": func(a *App, base tcell.Style) statusSegment {
+		styles := a.getStyles()
+		color := styles.RightFG
+		if a.activePanel == "left" {
+			color = styles.LeftFG
+		}
+		return statusSegment{trf("status.panel", padLabel(a.activePanel, "panel.left", "panel.right")), base.Foreground(color).Bold(true)}
+	},
+	"mode": func(a *App, base tcell.Style) statusSegment {
+		styles := a.getStyles()
+		color := styles.LeftFG
+		if a.view.mode == "edit" {
+			color = styles.RightFG
+		}
+		return statusSegment{trf("status.mode", padLabel(a.view.mode, "mode.edit", "mode.preview")), base.Foreground(color).Bold(true)}
+	},
+	"file": func(a *App, base tcell.Style) statusSegment {
+		name := filepath.Base(a.view.buf.path)
+		if a.view.buf.path == "" && a.pager {
+			name = "stdin" // текст из stdin, см. stdin.go
+		}
+		return statusSegment{trf("status.file", name), base}
+	},
+	"modified": func(a *App, base tcell.Style) statusSegment {
+		if a.view.buf.readOnly {
+			return statusSegment{"[RO]", base.Bold(true)}
+		}
+		if !a.view.buf.Modified {
+			return statusSegment{}
+		}
+		return statusSegment{"[+]", base.Bold(true)}
+	},
+	"position": func(a *App, base tcell.Style) statusSegment {
+		return statusSegment{trf("status.position", a.view.editY+1, a.view.editX+1), base}
+	},
+	"percent": func(a *App, base tcell.Style) statusSegment {
+		total, line := len(a.getLines()), a.view.editY
+		if a.view.mode == "preview" {
+			total, line = len(a.previewLines(a.view)), a.view.previewY
+		}
+		return statusSegment{fmt.Sprintf("%d%%", (line+1)*100/max(total, 1)), base}
+	},
+	"lines": func(a *App, base tcell.Style) statusSegment {
+		return statusSegment{trf("status.lines", len(a.view.buf.CachedLines())), base}
+	},
+	"wordcount": func(a *App, base tcell.Style) statusSegment {
+		if !a.isMarkdownFile() {
+			return statusSegment{}
+		}
+		return statusSegment{trf("status.words", a.view.buf.WordCount()), base}
+	},
+}
+
+// Перевод значения ("left", "edit"…), дополненный до ширины самого длинного
+// из вариантов, чтобы сегмент не менял ширину при переключении
+func padLabel(value string, keys ...string) string {
+	width := 0
+	for _, k := range keys {
+		if w := runewidth.StringWidth(tr(k)); w > width {
+			width = w
+		}
+	}
+	prefix := strings.SplitN(keys[0], ".", 2)[0]
+	return runewidth.FillRight(tr(prefix+"."+value), width)
+}
+
+// Построить видимые сегменты по списку имён из настроек
+func (a *App) buildSegments(names []string, base tcell.Style) []statusSegment {
+	styles := a.getStyles()
+	var segs []statusSegment
+	for _, name := range names {
+		fn, ok := statusSegments[name]
+		if !ok {
+			continue
+		}
+		seg := fn(a, base)
+		if seg.text == "" {
+			continue
+		}
+		if o, ok := styles.Segments[name]; ok {
+			seg.style = o.apply(seg.style)
+		}
+		segs = append(segs, seg)
+	}
+	return segs
+}
+
+// Ширина сегментов вместе с разделителями
+func segmentsWidth(segs []statusSegment, sep string) int {
+	w := 0
+	for i, seg := range segs {
+		if i > 0 {
+			w += runewidth.StringWidth(sep)
+		}
+		w += runewidth.StringWidth(seg.text)
+	}
+	return w
+}
+
+// Нарисовать сегменты с колонки col, не выходя за limit. Возвращает конечную колонку.
+func (a *App) drawSegments(col, y, limit int, segs []statusSegment, sep string, sepStyle tcell.Style) int {
+	put := func(text string, style tcell.Style) {
+		for _, r := range text {
+			w := runewidth.RuneWidth(r)
+			if col+w > limit {
+				return
+			}
+			a.screen.SetContent(col, y, r, nil, style)
+			col += w
+		}
+	}
+	for i, seg := range segs {
+		if i > 0 {
+			put(sep, sepStyle)
+		}
+		put(seg.text, seg.style)
+	}
+	return col
+}
+
+// Отрисовка статусной строки
+func (a *App) drawStatus() {
+	y := a.height - 1
+	cfg := a.config.Statusbar
+
+	base := a.getStyles().Statusbar
+
+	left := a.buildSegments(cfg.Left, base)
+	right := a.buildSegments(cfg.Right, base)
+
+	col := a.drawSegments(0, y, a.width, left, cfg.Separator, base)
+
+	// Правые сегменты прижимаем к краю, если они помещаются
+	rightStart := a.width - segmentsWidth(right, cfg.Separator) - 1
+	if rightStart > col {
+		a.drawSegments(rightStart, y, a.width, right, cfg.Separator, base)
+	}
+
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	textbuf "github.com/StasKrav/eddy_tcell/pkg/buffer"
+)
+
+// ---- Чтение из stdin: команда | eddy - ----
+//
+// С аргументом "-" текст читается из стандартного ввода и открывается
+// в окне без имени: Markdown — в предпросмотре (eddy работает как
+// пейджер, q выходит), остальное — в редакторе. Клавиатура при этом
+// читается из терминала, а не из stdin. Сохранить текст можно через
+// Ctrl+S: имя файла спрашивается, файл пишется в текущую папку.
+
+// Больше этого из stdin не читаем
+const stdinMaxSize = 64 << 20
+
+// Цвета и прочие управляющие последовательности от ls --color, git и т.п.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]|\x1b\][^\x07]*\x07`)
+
+// Прочитать весь стандартный ввод
+func readStdin() ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, stdinMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	text := ansiEscape.ReplaceAllString(string(data), "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return []byte(text), nil
+}
+
+// Признаки Markdown для текста без имени файла
+var markdownHints = regexp.MustCompile("(?m)^(#{1,6} |```|~~~|> |[-*+] |\\d+\\. |\\|.*\\|\\s*$)|\\[[^\\]]+\\]\\([^)]+\\)|\\*\\*[^*]+\\*\\*")
+
+// Похож ли текст на Markdown: нужно хотя бы два признака
+func looksLikeMarkdown(text string) bool {
+	return len(markdownHints.FindAllStringIndex(text, 2)) >= 2
+}
+
+// Открыть текст из stdin в новом буфере без имени
+func (a *App) openStdin(data []byte) {
+	content := string(data)
+	buf := &buffer{Buffer: textbuf.Buffer{Content: content, Undo: textbuf.NewHistory(content)}, openWords: countWords(content), readOnly: a.readOnly}
+	a.view.buf = buf
+	a.view.editX, a.view.editY = 0, 0
+	a.view.scrollX, a.view.scrollY, a.view.previewY = 0, 0, 0
+	a.view.mode = "edit"
+	if looksLikeMarkdown(content) {
+		a.view.mode = "preview"
+	}
+	a.pager = true
+	a.leftWidth = 0
+	a.activePanel = "right"
+}
+
+// Ctrl+S в окне без имени: спросить имя и сохранить в текущую папку
+func (a *App) saveUnnamed() {
+	if isRemote(a.currentDir) || inArchive(a.currentDir) {
+		a.notify(levelWarning, "%s", tr("save.no_name"))
+		return
+	}
+	buf := a.view.buf
+	a.prompt(tr("save.name_title"), "", func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		if strings.ContainsRune(name, os.PathSeparator) {
+			a.notify(levelWarning, tr("file.bad_name"), os.PathSeparator)
+			return
+		}
+		path := filepath.Join(a.currentDir, name)
+		if _, err := os.Stat(path); err == nil {
+			a.notify(levelError, tr("file.exists"), name)
+			return
+		}
+		if a.view.buf != buf {
+			return
+		}
+		buf.path = path
+		a.saveFile()
+		a.loadFiles()
+	})
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ---- Индекс тегов (Alt+t) ----
+//
+// Теги заметок: #тег в тексте и tags: во frontmatter (--- … ---).
+// Индекс строится по всем Markdown-файлам текущей папки; выбор тега
+// показывает заметки с ним, выбор заметки открывает её.
+
+var inlineTagRe = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]*\p{L}[\p{L}\p{N}_/-]*)`)
+
+// Теги одной заметки (без повторов, в порядке появления)
+func noteTags(content string) []string {
+	seen := map[string]bool{}
+	var tags []string
+	add := func(t string) {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "#")
+		t = strings.Trim(t, `"'`)
+		if t == "" || seen[strings.ToLower(t)] {
+			return
+		}
+		seen[strings.ToLower(t)] = true
+		tags = append(tags, t)
+	}
+
+	lines := strings.Split(content, "\n")
+	start := 0
+	// frontmatter: tags: [a, b] | tags: a, b | tags:\n  - a
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		inTags := false
+		for i := 1; i < len(lines); i++ {
+			l := strings.TrimSpace(lines[i])
+			if l == "---" || l == "..." {
+				start = i + 1
+				break
+			}
+			if inTags && strings.HasPrefix(l, "- ") {
+				add(strings.TrimPrefix(l, "- "))
+				continue
+			}
+			inTags = false
+			if v, ok := strings.CutPrefix(l, "tags:"); ok {
+				v = strings.Trim(strings.TrimSpace(v), "[]")
+				if v == "" {
+					inTags = true
+					continue
+				}
+				for _, t := range strings.Split(v, ",") {
+					add(t)
+				}
+			}
+		}
+	}
+
+	code := codeBlockLines(lines)
+	for i := start; i < len(lines); i++ {
+		if code[i] {
+			continue
+		}
+		for _, m := range inlineTagRe.FindAllStringSubmatch(stripInlineCode(lines[i]), -1) {
+			add(m[1])
+		}
+	}
+	return tags
+}
+
+// Убрать `код` из строки
+func stripInlineCode(line string) string {
+	parts := strings.Split(line, "`")
+	for i := 1; i < len(parts); i += 2 {
+		parts[i] = ""
+	}
+	return strings.Join(parts, " ")
+}
+
+// Индекс: тег → файлы
+func buildTagIndex(root string) map[string][]string {
+	index := map[string][]string{}
+	for _, path := range markdownFiles(root) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, t := range noteTags(string(content)) {
+			index[t] = append(index[t], path)
+		}
+	}
+	return index
+}
+
+// Браузер тегов
+func (a *App) showTags() {
+	index := buildTagIndex(a.currentDir)
+	if len(index) == 0 {
+		a.notify(levelInfo, "%s", tr("tags.none"))
+		return
+	}
+	tags := make([]string, 0, len(index))
+	for t := range index {
+		tags = append(tags, t)
+	}
+	// сначала самые частые
+	sort.Slice(tags, func(i, j int) bool {
+		if len(index[tags[i]]) != len(index[tags[j]]) {
+			return len(index[tags[i]]) > len(index[tags[j]])
+		}
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+	items := make([]listItem, len(tags))
+	for i, t := range tags {
+		items[i] = listItem{label: "#" + t, detail: fmt.Sprint(len(index[t])), value: t}
+	}
+	a.pick(tr("tags.title"), items, func(item listItem) {
+		a.showTagNotes(item.value, index[item.value])
+	})
+}
+
+// Заметки с тегом
+func (a *App) showTagNotes(tag string, files []string) {
+	items := make([]listItem, len(files))
+	for i, f := range files {
+		name := f
+		if rel, err := filepath.Rel(a.currentDir, f); err == nil {
+			name = rel
+		}
+		items[i] = listItem{label: name, value: f}
+	}
+	a.pick("#"+tag, items, func(item listItem) {
+		a.openFile(item.value)
+		a.setActivePanel("right")
+	})
+}
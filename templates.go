@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ---- Новые файлы из шаблонов (Ctrl+N) ----
+//
+// Шаблоны лежат в ~/.config/eddy/templates. При создании файла
+// предлагается выбрать шаблон; в нём подставляются переменные:
+//
+// {{date}}     — 2006-01-02
+// {{time}}     — 15:04
+// {{title}}    — имя файла без расширения, "my-note" → "My note"
+// {{filename}} — имя файла
+// {{cursor}}   — где поставить курсор (сама метка удаляется)
+
+// Папка шаблонов
+func templatesDir() string {
+	return filepath.Join(configDir(), "templates")
+}
+
+// Имена файлов шаблонов
+func listTemplates() []string {
+	entries, err := os.ReadDir(templatesDir())
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Заголовок из имени файла
+func titleFromName(name string) string {
+	title := strings.TrimSuffix(name, filepath.Ext(name))
+	title = strings.NewReplacer("-", " ", "_", " ").Replace(title)
+	r := []rune(title)
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
+
+// Подставить переменные; возвращает текст и смещение метки {{cursor}} (-1 — нет)
+func expandTemplate(tmpl, name string, now time.Time) (string, int) {
+	text := strings.NewReplacer(
+		"{{date}}", now.Format("2006-01-02"),
+		"{{time}}", now.Format("15:04"),
+		"{{title}}", titleFromName(name),
+		"{{filename}}", name,
+	).Replace(tmpl)
+	cursor := strings.Index(text, "{{cursor}}")
+	if cursor >= 0 {
+		text = text[:cursor] + text[cursor+len("{{cursor}}"):]
+	}
+	return text, cursor
+}
+
+// Создать файл: имя, затем шаблон
+func (a *App) newFilePrompt() {
+	a.prompt(tr("new.title"), "", func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		if strings.ContainsRune(name, os.PathSeparator) {
+			a.notify(levelWarning, tr("file.bad_name"), os.PathSeparator)
+			return
+		}
+		path := filepath.Join(a.currentDir, name)
+		if _, err := os.Stat(path); err == nil {
+			a.notify(levelError, tr("file.exists"), name)
+			return
+		}
+
+		templates := listTemplates()
+		if len(templates) == 0 {
+			a.createFile(path, "")
+			return
+		}
+		items := []listItem{{label: tr("new.empty")}}
+		for _, t := range templates {
+			items = append(items, listItem{label: t, value: t})
+		}
+		// шаблон с тем же расширением — первым после пустого
+		sort.SliceStable(items[1:], func(i, j int) bool {
+			return filepath.Ext(items[1+i].value) == filepath.Ext(name) && filepath.Ext(items[1+j].value) != filepath.Ext(name)
+		})
+		a.pick(tr("new.template"), items, func(item listItem) {
+			a.createFile(path, item.value)
+		})
+	})
+}
+
+// Записать новый файл (из шаблона, если задан) и открыть его
+func (a *App) createFile(path, template string) {
+	text, cursor := "", -1
+	if template != "" {
+		tmpl, err := os.ReadFile(filepath.Join(templatesDir(), template))
+		if err != nil {
+			a.notify(levelError, tr("file.read_error"), err)
+			return
+		}
+		text, cursor = expandTemplate(string(tmpl), filepath.Base(path), time.Now())
+	}
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		a.notify(levelError, tr("save.failed"), err)
+		return
+	}
+	a.loadFiles()
+	a.selectFile(path)
+	a.openFile(path)
+	a.view.mode = "edit"
+	a.setActivePanel("right")
+	if cursor >= 0 {
+		before := text[:cursor]
+		a.view.editY = strings.Count(before, "\n")
+		a.view.editX = len([]rune(before[strings.LastIndex(before, "\n")+1:]))
+		a.ensureCursorVisible()
+	}
+	a.notify(levelSuccess, tr("new.created"), filepath.Base(path))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// ---- Светлый или тёмный фон терминала ----
+//
+// Настройка background в config.toml: "light", "dark" или "auto".
+// В режиме auto терминал спрашивается через OSC 11 (до инициализации
+// tcell), при отсутствии ответа смотрим на COLORFGBG, иначе считаем фон
+// тёмным. Вслед за OSC 11 посылается запрос DA1: на него отвечает любой
+// терминал, ответы приходят по порядку, и после ответа на DA1 ответ на
+// OSC 11 уже прочитан — запоздавший ответ не попадёт в tcell как
+// нажатия клавиш. На светлом фоне берётся theme-light.toml и светлая тема по
+// умолчанию.
+
+// Сколько ждать ответов терминала (обычно они приходят сразу)
+const bgQueryTimeout = time.Second
+
+var (
+	osc11Re = regexp.MustCompile(`\]11;rgb:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})`)
+	da1Re   = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+)
+
+// Светлый ли фон: по настройке или по ответу терминала
+func detectLightBackground(setting string) bool {
+	switch strings.ToLower(strings.TrimSpace(setting)) {
+	case "light":
+		return true
+	case "dark":
+		return false
+	}
+	if light, ok := queryTerminalBackground(); ok {
+		return light
+	}
+	// COLORFGBG="15;0": последний номер — цвет фона
+	if v := os.Getenv("COLORFGBG"); v != "" {
+		parts := strings.Split(v, ";")
+		if n, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			return n == 7 || n == 15
+		}
+	}
+	return false
+}
+
+// Спросить цвет фона у терминала (OSC 11); ok=false — ответа нет
+func queryTerminalBackground() (light, ok bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, false
+	}
+	defer tty.Close()
+	// без поддержки дедлайнов читать нельзя: зависнем, если терминал молчит
+	if err := tty.SetReadDeadline(time.Now().Add(bgQueryTimeout)); err != nil {
+		return false, false
+	}
+	// Fd() перевёл бы файл в блокирующий режим и отключил дедлайны
+	rc, err := tty.SyscallConn()
+	if err != nil {
+		return false, false
+	}
+	var fd int
+	var state *term.State
+	if err := rc.Control(func(f uintptr) {
+		fd = int(f)
+		state, err = term.MakeRaw(fd)
+	}); err != nil || state == nil {
+		return false, false
+	}
+	defer term.Restore(fd, state)
+
+	if _, err := tty.WriteString("\x1b]11;?\x07\x1b[c"); err != nil {
+		return false, false
+	}
+	// читаем до ответа на DA1, он идёт последним
+	var resp []byte
+	buf := make([]byte, 64)
+	for !da1Re.Match(resp) {
+		n, err := tty.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	return parseBackgroundReply(string(resp))
+}
+
+// Светлый ли цвет в ответе на OSC 11; ok=false — ответа на OSC 11 нет
+func parseBackgroundReply(resp string) (light, ok bool) {
+	m := osc11Re.FindStringSubmatch(resp)
+	if m == nil {
+		return false, false
+	}
+	var rgb [3]float64
+	for i := range rgb {
+		v, _ := strconv.ParseUint(m[i+1], 16, 32)
+		rgb[i] = float64(v) / float64(uint64(1)<<(4*len(m[i+1]))-1)
+	}
+	lum := 0.2126*rgb[0] + 0.7152*rgb[1] + 0.0722*rgb[2]
+	return lum > 0.5, true
+}
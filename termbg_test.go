@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestParseBackgroundReply(t *testing.T) {
+	tests := []struct {
+		resp      string
+		light, ok bool
+	}{
+		{"\x1b]11;rgb:ffff/ffff/ffff\x07\x1b[?62;22c", true, true},
+		{"\x1b]11;rgb:0000/0000/0000\x1b\\\x1b[?1;2c", false, true},
+		{"\x1b]11;rgb:fd/f6/e3\x07", true, true}, // solarized light, по 2 цифры
+		{"\x1b]11;rgb:1e1e/1e1e/2e2e\x07", false, true},
+		{"\x1b[?1;2c", false, false}, // OSC 11 не поддерживается
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		light, ok := parseBackgroundReply(tt.resp)
+		if light != tt.light || ok != tt.ok {
+			t.Errorf("parseBackgroundReply(%q) = %v %v, want %v %v", tt.resp, light, ok, tt.light, tt.ok)
+		}
+	}
+}
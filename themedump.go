@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+)
+
+// ---- Выгрузка темы ----
+//
+// Команда theme.dump (палитра Ctrl+P) и флаг --dump-theme записывают
+// действующую тему целиком: встроенные значения вместе с правками из
+// файла темы и всех inherit. Каждый ключ есть в файле, так что это
+// готовая заготовка своей темы. Прозрачность и доступность в выгрузку
+// не попадают: это настройки, а не тема.
+
+// Тема в TOML со всеми ключами
+func encodeTheme(t *theme.Theme, source string) (string, error) {
+	doc := map[string]interface{}{}
+	for key, v := range theme.Flatten(t) {
+		setNested(doc, key, v)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Full theme exported from %s\n\n", source)
+	enc := toml.NewEncoder(&b)
+	enc.Indent = ""
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Записать тему в файл ("-" — в stdout)
+func writeThemeDump(t *theme.Theme, source, path string) error {
+	text, err := encodeTheme(t, source)
+	if err != nil {
+		return err
+	}
+	if path == "-" {
+		_, err = os.Stdout.WriteString(text)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(text), 0644)
+}
+
+// theme.dump: спросить файл и выгрузить тему
+func (a *App) dumpThemePrompt() {
+	initial := filepath.Join(configDir(), "themes", "current.toml")
+	a.prompt(tr("themedump.title"), initial, func(path string) {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return
+		}
+		if strings.HasPrefix(path, "~") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[1:])
+			}
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(a.currentDir, path)
+		}
+		write := func() {
+			_, source, _ := a.readTheme()
+			if err := writeThemeDump(a.loadedTheme(), source, path); err != nil {
+				a.notify(levelError, tr("save.failed"), err)
+				return
+			}
+			a.notify(levelSuccess, tr("themedump.saved"), path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			a.confirmIf(a.config.Confirm.Overwrite, trf("web.overwrite", filepath.Base(path)), write)
+			return
+		}
+		write()
+	})
+}
+
+// --dump-theme: выгрузить тему без запуска интерфейса
+func dumpThemeCLI(path string) error {
+	cfg, err := loadConfigFromFile(configPath())
+	if err != nil {
+		return err
+	}
+	a := &App{config: cfg, lightBackground: detectLightBackground(cfg.Background)}
+	t, source, err := a.readTheme()
+	if t == nil {
+		return err
+	}
+	if err != nil {
+		// замечания к теме не мешают выгрузке
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return writeThemeDump(t, source, path)
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Редактор темы (Alt+E) ----
+//
+// Список всех ключей темы с текущими значениями и образцом цвета. Правки
+// сразу видны в интерфейсе. Enter — ввести цвет (#rrggbb, #rgb, имя или
+// "r, g, b"), p — выбрать из палитры, пробел — переключить флаг, Del —
+// вернуть исходное значение. Ctrl+S записывает в файл темы только ключи,
+// отличающиеся от базовой темы (inherit сохраняется), Esc — отмена.
+
+var rgbInputRe = regexp.MustCompile(`^(?:rgb)?\(?\s*(\d{1,3})\s*[, ]\s*(\d{1,3})\s*[, ]\s*(\d{1,3})\s*\)?$`)
+
+type themeEditorOverlay struct {
+	orig     *theme.Theme // тема до правки: Esc возвращает её
+	theme    *theme.Theme // редактируемая копия
+	keys     []string
+	selected int
+	scroll   int
+	modified bool
+}
+
+// Открыть редактор текущей темы
+func (a *App) openThemeEditor() {
+	orig := a.loadedTheme()
+	a.pushOverlay(&themeEditorOverlay{orig: orig, theme: orig.Clone(), keys: theme.Keys(orig)})
+}
+
+// Цвет из ввода пользователя: "r, g, b" переводится в #rrggbb
+func normalizeColorInput(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if m := rgbInputRe.FindStringSubmatch(strings.ToLower(s)); m != nil {
+		var rgb [3]int
+		for i := range rgb {
+			rgb[i], _ = strconv.Atoi(m[i+1])
+			if rgb[i] > 255 {
+				return "", false
+			}
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), true
+	}
+	return s, theme.ValidColor(s)
+}
+
+func (e *themeEditorOverlay) rect(a *App) (x, y, w, h int) {
+	return a.centeredRect(72, a.height-2)
+}
+
+// Текущий ключ и его значение
+func (e *themeEditorOverlay) current() (string, interface{}) {
+	key := e.keys[e.selected]
+	return key, theme.Flatten(e.theme)[key]
+}
+
+// Задать значение и сразу показать тему
+func (e *themeEditorOverlay) set(a *App, key string, value interface{}) {
+	if theme.SetValue(e.theme, key, value) {
+		e.modified = true
+		a.applyTheme(e.theme)
+	}
+}
+
+func (e *themeEditorOverlay) draw(a *App) {
+	st := a.dialogStyles()
+	x, y, w, h := e.rect(a)
+	if w < 20 || h < 5 {
+		return
+	}
+	title := " " + tr("themeedit.title") + " "
+	if e.modified {
+		title = " " + tr("themeedit.title") + " * "
+	}
+	ui.Box(a.screen, x, y, w, h, title, st.border, st.body)
+
+	visible := h - 4
+	if e.selected < e.scroll {
+		e.scroll = e.selected
+	}
+	if e.selected >= e.scroll+visible {
+		e.scroll = e.selected - visible + 1
+	}
+	values := theme.Flatten(e.theme)
+	keyW := w / 2
+	for i := 0; i < visible && e.scroll+i < len(e.keys); i++ {
+		idx := e.scroll + i
+		key := e.keys[idx]
+		style, dim := st.body, st.dim
+		if idx == e.selected {
+			style, dim = st.selected, st.selected
+			for cx := x + 1; cx < x+w-1; cx++ {
+				a.screen.SetContent(cx, y+1+i, ' ', nil, style)
+			}
+		}
+		ui.PutString(a.screen, x+2, y+1+i, x+keyW, key, style)
+		col := x + keyW + 1
+		switch v := values[key].(type) {
+		case bool:
+			mark := "[ ]"
+			if v {
+				mark = "[x]"
+			}
+			ui.PutString(a.screen, col, y+1+i, x+w-3, mark, style)
+		case string:
+			if v == "" {
+				ui.PutString(a.screen, col, y+1+i, x+w-3, "-", dim)
+				break
+			}
+			// образец цвета
+			ui.PutString(a.screen, col, y+1+i, x+w-3, "   ", tcell.StyleDefault.Background(theme.ParseColor(v)))
+			ui.PutString(a.screen, col+4, y+1+i, x+w-3, v, style)
+		}
+	}
+	a.drawScrollbar(x+w-2, y+1, visible, len(e.keys), visible, e.scroll)
+	ui.PutString(a.screen, x+2, y+h-2, x+w-2, tr("themeedit.hint"), st.dim)
+}
+
+func (e *themeEditorOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	_, _, _, h := e.rect(a)
+	page := h - 4
+	key, value := e.current()
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		if !e.modified {
+			return true
+		}
+		a.confirm(tr("themeedit.discard"), func() {
+			a.applyTheme(e.orig)
+			a.popOverlay()
+		})
+		return false
+	case tcell.KeyCtrlS:
+		if err := a.saveEditedTheme(e.theme); err != nil {
+			a.notify(levelError, tr("save.failed"), err)
+			return false
+		}
+		return true
+	case tcell.KeyUp:
+		e.selected--
+	case tcell.KeyDown:
+		e.selected++
+	case tcell.KeyPgUp:
+		e.selected -= page
+	case tcell.KeyPgDn:
+		e.selected += page
+	case tcell.KeyHome:
+		e.selected = 0
+	case tcell.KeyEnd:
+		e.selected = len(e.keys) - 1
+	case tcell.KeyDelete:
+		e.set(a, key, theme.Flatten(e.orig)[key])
+	case tcell.KeyEnter:
+		if v, ok := value.(bool); ok {
+			e.set(a, key, !v)
+			break
+		}
+		a.prompt(key, value.(string), func(text string) {
+			c, ok := normalizeColorInput(text)
+			if !ok {
+				a.notify(levelWarning, tr("themeedit.bad_color"), text)
+				return
+			}
+			e.set(a, key, c)
+		})
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case ' ':
+			if v, ok := value.(bool); ok {
+				e.set(a, key, !v)
+			}
+		case 'p':
+			if s, ok := value.(string); ok {
+				e.pickColor(a, key, s)
+			}
+		}
+	}
+	if e.selected >= len(e.keys) {
+		e.selected = len(e.keys) - 1
+	}
+	if e.selected < 0 {
+		e.selected = 0
+	}
+	return false
+}
+
+// Палитра: 16 цветов терминала и цвета, уже используемые в теме
+func (e *themeEditorOverlay) pickColor(a *App, key, prev string) {
+	names := []string{"default", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white", "gray"}
+	items := make([]listItem, 0, len(names))
+	for _, n := range names {
+		items = append(items, listItem{label: n, value: n})
+	}
+	seen := map[string]bool{}
+	var used []string
+	for _, v := range theme.Flatten(e.theme) {
+		if s, ok := v.(string); ok && strings.HasPrefix(s, "#") && !seen[strings.ToLower(s)] {
+			seen[strings.ToLower(s)] = true
+			used = append(used, strings.ToLower(s))
+		}
+	}
+	sort.Strings(used)
+	for _, c := range used {
+		items = append(items, listItem{label: c, detail: tr("themeedit.in_theme"), value: c})
+	}
+	wasModified := e.modified
+	l := a.pick(key, items, func(item listItem) {
+		e.set(a, key, item.value)
+	})
+	l.onChange = func(item listItem) { e.set(a, key, item.value) }
+	l.onCancel = func() {
+		e.set(a, key, prev)
+		e.modified = wasModified
+	}
+}
+
+// Положить значение по ключу "a.b.c" во вложенные таблицы doc
+func setNested(doc map[string]interface{}, key string, v interface{}) {
+	parts := strings.Split(key, ".")
+	m := doc
+	for _, p := range parts[:len(parts)-1] {
+		sub, ok := m[p].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			m[p] = sub
+		}
+		m = sub
+	}
+	m[parts[len(parts)-1]] = v
+}
+
+// Записать тему в файл: inherit и ключи, отличающиеся от базовой темы
+func (a *App) saveEditedTheme(t *theme.Theme) error {
+	path := a.themePath()
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(configDir(), filepath.Base(path))
+	}
+	inherit := ""
+	ref := a.config.Theme
+	switch {
+	case ref != "" && filepath.Ext(ref) == ".toml":
+		// своя тема из настройки theme — правим её
+		path = ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(configDir(), path)
+		}
+	case ref != "":
+		// встроенная тема или base16 — наследуем её в theme.toml
+		inherit = ref
+	}
+	if inherit == "" {
+		// inherit уже существующего файла сохраняем
+		var head struct {
+			Inherit string `toml:"inherit"`
+		}
+		if _, err := toml.DecodeFile(path, &head); err == nil {
+			inherit = head.Inherit
+		}
+	}
+
+	base := a.fallbackTheme()
+	if inherit != "" {
+		dir := filepath.Dir(path)
+		if ref != "" && inherit == ref {
+			dir = configDir()
+		}
+		if parent, err := theme.Resolve(inherit, dir, base, 1); parent != nil {
+			base = parent
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// вложенные таблицы из отличающихся ключей
+	doc := map[string]interface{}{}
+	if inherit != "" {
+		doc["inherit"] = inherit
+	}
+	values := theme.Flatten(t)
+	for _, key := range theme.Diff(base, t) {
+		v, ok := values[key]
+		if !ok {
+			continue
+		}
+		setNested(doc, key, v)
+	}
+
+	var b strings.Builder
+	enc := toml.NewEncoder(&b)
+	enc.Indent = ""
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	// theme.toml действует, только если в настройках не выбрана другая тема
+	if ref != "" && inherit == ref {
+		a.config.Theme = ""
+		if err := setConfigValue("theme", ""); err != nil {
+			return err
+		}
+	}
+	a.applyTheme(t)
+	a.notify(levelSuccess, tr("themeedit.saved"), path)
+	return nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/StasKrav/eddy_tcell/internal/theme"
+)
+
+// ---- Загрузка темы ----
+//
+// Тема берётся из настройки theme (встроенная или файл, см.
+// internal/theme) или из theme.toml в папке настроек; на светлом фоне
+// терминала сначала ищется theme-light.toml. Перед отрисовкой к ней
+// применяются прозрачность и доступность, и она переводится в готовые
+// стили (resolved.go). За файлом темы следит fsnotify: после изменения
+// тема перезагружается без перезапуска.
+
+// Тема по умолчанию с учётом фона терминала
+func (a *App) fallbackTheme() *theme.Theme {
+	if a.lightBackground {
+		return &theme.DefaultLight
+	}
+	return &theme.Default
+}
+
+// Получить путь к файлу темы: ~/.config/eddy/theme.toml
+// (на светлом фоне сначала ищется theme-light.toml)
+func (a *App) themePath() string {
+	names := []string{"theme.toml"}
+	if a.lightBackground {
+		names = []string{"theme-light.toml", "theme.toml"}
+	}
+	for _, name := range names {
+		userPath := filepath.Join(configDir(), name)
+		if _, err := os.Stat(userPath); err == nil {
+			return userPath
+		}
+	}
+	// fallback на файл рядом с бинарником
+	for _, name := range names {
+		if _, err := os.Stat(name); err == nil {
+			return "./" + name
+		}
+	}
+	return "./" + names[len(names)-1]
+}
+
+// Отсутствующие в файле ключи берутся из base (или из inherit, см. internal/theme/theme.go)
+func loadThemeFromFile(path string, base *theme.Theme) (*theme.Theme, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("theme not found at %s", path)
+		}
+		return nil, fmt.Errorf("cannot access theme file: %v", err)
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("theme path %s is a directory, not a file", path)
+	}
+
+	t, err := theme.DecodeFile(path, base, 0)
+	if _, ok := err.(theme.Warnings); ok {
+		return t, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse theme: %v", err)
+	}
+
+	return t, nil
+}
+
+func (a *App) applyTheme(t *theme.Theme) {
+	a.themeMu.Lock()
+	defer a.themeMu.Unlock()
+	if t == nil {
+		// если nil — используем дефолтную
+		t = a.fallbackTheme()
+	}
+	a.loaded = t
+	// прозрачный фон: настройка transparent или ui.transparent в теме
+	if a.config.Transparent || t.UI.Transparent {
+		t = t.WithoutBackground()
+	}
+	// доступность (см. internal/theme/accessibility.go)
+	if theme.NoColorMode(a.config.Accessibility.NoColor) {
+		t = t.Monochrome()
+	} else if min := a.config.Accessibility.MinContrast; min > 1 {
+		t = t.WithContrast(min)
+	}
+	a.theme = t
+	a.styles = compileTheme(t)
+}
+
+// Прочитать тему: встроенную или файл из настройки theme, иначе theme.toml
+func (a *App) readTheme() (*theme.Theme, string, error) {
+	if ref := a.config.Theme; ref != "" {
+		t, err := theme.Resolve(ref, configDir(), a.fallbackTheme(), 0)
+		return t, ref, err
+	}
+	path := a.themePath()
+	t, err := loadThemeFromFile(path, a.fallbackTheme())
+	return t, path, err
+}
+
+// загрузка темы: если нет файла — дефолт
+func (a *App) loadTheme() {
+	t, path, err := a.readTheme()
+	a.debugf("trying to load theme: %s", path)
+	if t != nil && err != nil {
+		a.debugf("theme loaded with warnings: %v", err)
+		a.applyTheme(t)
+		a.notifyThemeWarnings(err)
+		return
+	}
+	if err != nil {
+		a.debugf("theme load failed: %v", err)
+		a.applyTheme(nil)
+		// отсутствие файла темы — нормальная ситуация, об остальном сообщаем
+		if _, statErr := os.Stat(path); statErr == nil || a.config.Theme != "" {
+			a.notify(levelWarning, tr("theme.load_failed"), err)
+		}
+		return
+	}
+	a.debugf("theme loaded: %s", path)
+	a.applyTheme(t)
+}
+
+// Показать замечания к загруженной теме (каждое отдельным уведомлением)
+func (a *App) notifyThemeWarnings(err error) {
+	w, ok := err.(theme.Warnings)
+	if !ok {
+		return
+	}
+	for _, msg := range w {
+		a.notify(levelWarning, tr("theme.warning"), msg)
+	}
+}
+
+// Релоад темы (хоткей и наблюдатель за файлом). При ошибке остаётся
+// прежняя тема; что изменилось — в уведомлении.
+func (a *App) reloadTheme() {
+	old := a.loadedTheme()
+	t, _, err := a.readTheme()
+	if t == nil {
+		a.notify(levelError, tr("theme.error"), err)
+		return
+	}
+	a.applyTheme(t)
+	a.notifyThemeWarnings(err)
+	a.fireHooks(eventThemeReload)
+	switch changes := theme.Diff(old, t); {
+	case len(changes) == 0:
+		a.notify(levelInfo, "%s", tr("theme.unchanged"))
+	case len(changes) <= 3:
+		a.notify(levelSuccess, tr("theme.reloaded_keys"), strings.Join(changes, ", "))
+	default:
+		a.notify(levelSuccess, tr("theme.reloaded"), len(changes))
+	}
+	// попросим tcell перерисовать экран
+	if a.screen != nil {
+		a.screen.Sync()
+	}
+}
+
+// Пауза после последнего изменения файла темы перед перезагрузкой
+const themeReloadDelay = 100 * time.Millisecond
+
+// Наблюдатель за файлом темы (fsnotify). Работает в отдельной горутине.
+// Смотрим за директорией, где лежит файл, т.к. иногда файл перезаписывают через tmp-файл.
+func (a *App) watchThemeFile() error {
+	path := a.themePath()
+	dir := filepath.Dir(path)
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	a.themeWatcher = w
+
+	// Добавляем watcher на директорию
+	if err := w.Add(dir); err != nil {
+		// если не удалось — всё равно продолжаем (приложение будет работать без hot-reload)
+		go func() {
+			// закрываем watcher через некоторое время, чтобы не утекал
+			time.Sleep(10 * time.Millisecond)
+			_ = w.Close()
+		}()
+		return err
+	}
+
+	go func() {
+		defer w.Close()
+		// редакторы сохраняют файл в несколько приёмов — ждём, пока затихнет
+		var pending *time.Timer
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				// интересуют изменения конкретного файла
+				if filepath.Clean(ev.Name) == filepath.Clean(path) {
+					// WRITE, CREATE, REMOVE, RENAME — в любом случае пробуем перезагрузить тему
+					if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+						if pending != nil {
+							pending.Stop()
+						}
+						pending = time.AfterFunc(themeReloadDelay, func() {
+							a.post(a.reloadTheme)
+						})
+					}
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				a.debugf("theme watcher: %v", err)
+			}
+		}
+	}()
+
+	return nil
+
+}
+
+// Получить текущую тему (копия указателя под RLock)
+func (a *App) getTheme() *theme.Theme {
+	a.themeMu.RLock()
+	defer a.themeMu.RUnlock()
+	return a.theme
+}
+
+// Тема как она загружена (для редактора темы, экспорта и сравнения)
+func (a *App) loadedTheme() *theme.Theme {
+	a.themeMu.RLock()
+	defer a.themeMu.RUnlock()
+	if a.loaded == nil {
+		return a.theme
+	}
+	return a.loaded
+}
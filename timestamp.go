@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// ---- Вставка даты и времени (Alt+i) ----
+//
+// Форматы задаются в editor.timestamps раскладками Go ("2006-01-02",
+// "15:04", "Monday, 2 January 2006"). С одним форматом дата вставляется
+// сразу, с несколькими — выбирается из списка, где видно, как она
+// будет выглядеть. Удобно для дневников и списков изменений.
+
+// Alt+i: вставить текущую дату
+func (a *App) insertTimestamp() {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	var layouts []string
+	for _, l := range a.config.Editor.Timestamps {
+		if strings.TrimSpace(l) != "" {
+			layouts = append(layouts, l)
+		}
+	}
+	if len(layouts) == 0 {
+		layouts = []string{time.DateOnly}
+	}
+	insert := func(layout string) {
+		a.repeatable(func() {
+			a.deleteSelection()
+			a.insertText(time.Now().Format(layout))
+		})
+	}
+	if len(layouts) == 1 {
+		insert(layouts[0])
+		return
+	}
+	now := time.Now()
+	items := make([]listItem, len(layouts))
+	for i, l := range layouts {
+		items[i] = listItem{label: now.Format(l), detail: l, value: l}
+	}
+	a.pick(tr("timestamp.title"), items, func(item listItem) {
+		insert(item.value)
+	})
+}
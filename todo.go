@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ---- Список задач по заметкам (Alt+g) ----
+//
+// Собирает из всех Markdown-файлов текущей папки (с подпапками) строки
+// с TODO: и FIXME:, а также невыполненные пункты «- [ ]». Список
+// сгруппирован по файлам; выбор пункта открывает файл на этой строке,
+// выбор заголовка файла — на первой задаче в нём.
+
+var (
+	todoMarkRe = regexp.MustCompile(`\b(TODO|FIXME):\s*(.*)`)
+	todoTaskRe = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[ \]\s+(.*)`)
+)
+
+// Задача в заметке
+type todoItem struct {
+	file string
+	line int // с 1
+	kind string
+	text string
+}
+
+// Задачи одной заметки
+func findTodos(file, content string) []todoItem {
+	var items []todoItem
+	lines := strings.Split(content, "\n")
+	code := codeBlockLines(lines)
+	for i, line := range lines {
+		if m := todoMarkRe.FindStringSubmatch(line); m != nil {
+			items = append(items, todoItem{file: file, line: i + 1, kind: m[1], text: strings.TrimSpace(m[2])})
+			continue
+		}
+		if code[i] {
+			continue
+		}
+		if m := todoTaskRe.FindStringSubmatch(line); m != nil {
+			items = append(items, todoItem{file: file, line: i + 1, kind: "[ ]", text: strings.TrimSpace(m[1])})
+		}
+	}
+	return items
+}
+
+// Показать задачи по всем заметкам текущей папки
+func (a *App) showTodos() {
+	var todos []todoItem
+	var items []listItem
+	for _, path := range markdownFiles(a.currentDir) {
+		content, ok := a.noteContent(path)
+		if !ok {
+			continue
+		}
+		found := findTodos(path, content)
+		if len(found) == 0 {
+			continue
+		}
+		name := path
+		if rel, err := filepath.Rel(a.currentDir, path); err == nil {
+			name = rel
+		}
+		// заголовок файла ведёт к его первой задаче
+		items = append(items, listItem{label: name, detail: fmt.Sprint(len(found)), value: fmt.Sprint(len(todos))})
+		for _, t := range found {
+			items = append(items, listItem{
+				label:  "  " + t.kind + " " + t.text,
+				detail: fmt.Sprint(t.line),
+				value:  fmt.Sprint(len(todos)),
+			})
+			todos = append(todos, t)
+		}
+	}
+	if len(todos) == 0 {
+		a.notify(levelInfo, "%s", tr("todo.none"))
+		return
+	}
+	a.pick(trf("todo.title", len(todos)), items, func(item listItem) {
+		var i int
+		fmt.Sscan(item.value, &i)
+		t := todos[i]
+		if t.file != a.view.buf.path {
+			a.openFile(t.file)
+		}
+		a.view.mode = "edit"
+		a.gotoLine(t.line)
+	})
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	textbuf "github.com/StasKrav/eddy_tcell/pkg/buffer"
+)
+
+// ---- Отмена правок (Ctrl+Z / Ctrl+Y) ----
+//
+// После каждого события главный цикл передаёт текст буферов в их
+// историю (undoCheckpoint, см. pkg/buffer/undo.go): так отменяются
+// любые правки — набор, вставка, замена слова, внешний редактор — без
+// отдельного учёта в каждой команде. Набор подряд объединяется в один
+// шаг, пока нет паузы дольше group_pause_ms, история ограничена по
+// памяти (max_memory_mb). С persist = true история сохраняется вместе с
+// файлом в ~/.local/state/eddy/undo и подхватывается при следующем открытии,
+// если файл с тех пор не менялся.
+
+// Записать правки всех открытых буферов, сделанные последним событием
+func (a *App) undoCheckpoint() {
+	for _, v := range a.views {
+		a.undoCheckpointView(v)
+	}
+}
+
+func (a *App) undoCheckpointView(v *editorView) {
+	buf := v.buf
+	if buf.Undo == nil {
+		buf.Undo = textbuf.NewHistory(buf.Content)
+	}
+	h := buf.Undo
+	// курсор без правок запоминаем только у активного окна
+	if v != a.view && !h.Changed(buf.Content) {
+		return
+	}
+	h.GroupPause = time.Duration(a.config.Undo.GroupPause) * time.Millisecond
+	h.MaxBytes = a.config.Undo.MaxMemory << 20
+	h.Record(buf.Content, v.editY, v.editX)
+}
+
+// Отменить последний шаг
+func (a *App) undo() {
+	a.undoRedo(true)
+}
+
+// Вернуть отменённый шаг
+func (a *App) redo() {
+	a.undoRedo(false)
+}
+
+func (a *App) undoRedo(undo bool) {
+	if a.activePanel != "right" || a.view.mode != "edit" || !a.checkWritable() {
+		return
+	}
+	// правки этого события ещё не записаны
+	a.undoCheckpointView(a.view)
+	buf := a.view.buf
+	step, empty := buf.Undo.Undo, "undo.none"
+	if !undo {
+		step, empty = buf.Undo.Redo, "redo.none"
+	}
+	content, y, x, ok := step(buf.Content)
+	if !ok {
+		a.notify(levelInfo, "%s", tr(empty))
+		return
+	}
+	buf.Content = content
+	buf.Modified = true
+	a.view.editY, a.view.editX = y, x
+	a.view.clearSelection()
+	a.clampCursor()
+	a.ensureCursorVisible()
+	buf.Undo.Record(buf.Content, a.view.editY, a.view.editX)
+}
+
+// ---- Сохранение истории между запусками ----
+
+// Сохранённая история: только для того содержимого файла, с которым записана
+type undoFile struct {
+	Path string           `json:"path"`
+	Hash string           `json:"hash"`
+	Undo []*textbuf.Entry `json:"undo"`
+	Redo []*textbuf.Entry `json:"redo"`
+}
+
+func contentHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Файл истории для документа: <stateDir>/undo/<хэш пути>.json
+func undoPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return filepath.Join(stateDir(), "undo", contentHash(abs)[:32]+".json")
+}
+
+// История для только что открытого буфера: сохранённая, если файл не менялся
+func (a *App) loadUndo(buf *buffer) *textbuf.History {
+	h := textbuf.NewHistory(buf.Content)
+	if !a.config.Undo.Persist || buf.path == "" {
+		return h
+	}
+	data, err := os.ReadFile(undoPath(buf.path))
+	if err != nil {
+		return h
+	}
+	var f undoFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		a.debugf("undo history %s: %v", buf.path, err)
+		return h
+	}
+	if f.Hash != contentHash(buf.Content) {
+		return h
+	}
+	h.Restore(f.Undo, f.Redo)
+	return h
+}
+
+// Записать историю буфера рядом с сохранённым файлом
+func (a *App) saveUndo(buf *buffer) {
+	if !a.config.Undo.Persist || buf.path == "" || buf.Undo == nil {
+		return
+	}
+	undo, redo := buf.Undo.Steps()
+	f := undoFile{Path: buf.path, Hash: contentHash(buf.Content), Undo: undo, Redo: redo}
+	data, err := json.Marshal(f)
+	if err == nil {
+		path := undoPath(buf.path)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			err = os.WriteFile(path, data, 0644)
+		}
+	}
+	if err != nil {
+		a.debugf("undo history %s: %v", buf.path, err)
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/preview"
+)
+
+// ---- Окна редактора ----
+//
+// Правая область — одно или два окна (split) над общими или разными
+// буферами. Здесь раскладка окон, переключение между ними и режимами
+// правки и предпросмотра, прокрутка и отрисовка текста с номерами
+// строк, выделением и подсветкой синтаксиса.
+
+const textEditorPadding = 2 // Отступ от левой границы текстового редактора
+
+// Окно редактора: буфер, режим, курсор и прокрутка.
+// Правая область может содержать одно или два окна (split).
+type editorView struct {
+	buf  *buffer
+	mode string // "edit" или "preview"
+
+	// Позиции курсора в редакторе (в rune-единицах)
+	editX, editY int
+
+	// Начало выделения (см. selection.go)
+	selecting  bool
+	selX, selY int
+
+	// Смещение для прокрутки (в rune-единицах)
+	scrollX, scrollY int
+
+	// Предпросмотр: верхняя экранная строка и собранный буфер (см. internal/preview)
+	previewY int
+	preview  preview.Cache
+	found    *previewFound // поиск в предпросмотре (см. previewsearch.go)
+
+	// Область окна на экране (включая строку заголовка)
+	x, y, w, h int
+}
+
+// Область текста внутри окна (без заголовка, с учётом отступа)
+func (v *editorView) textArea() (x, y, w, h int) {
+	x = v.x + textEditorPadding
+	y = v.y + 2
+	w = v.w - 1 - textEditorPadding
+	h = v.h - 2
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return x, y, w, h
+}
+
+// Скрыть/показать панель файлов (редактор на всю ширину).
+// В узком терминале — переключиться между списком и редактором.
+func (a *App) toggleFilePanel() {
+	if a.narrow() {
+		a.toggleNarrowPanel()
+		return
+	}
+	if a.leftWidth > 0 {
+		a.leftWidth = 0
+		a.activePanel = "right"
+	} else {
+		a.leftWidth = a.panelWidth
+	}
+	a.ensureCursorVisible()
+}
+
+// Переключение между режимами редактирования и предпросмотра
+func (a *App) toggleMode() {
+	v := a.view
+	defer func() {
+		if a.isMarkdownFile() {
+			a.markdownMode = v.mode
+		}
+	}()
+	if v.mode == "edit" {
+		v.mode = "preview"
+		// сверху — та же исходная строка, что была в редакторе
+		a.scrollPreviewTo(v, v.scrollY)
+		return
+	}
+	v.mode = "edit"
+	v.scrollY = a.previewTopSource(v)
+	// курсор — в видимую часть (с учётом scrolloff)
+	_, _, _, editorHeight := v.textArea()
+	so := a.scrollOff(editorHeight)
+	if v.editY < v.scrollY+so || v.editY >= v.scrollY+editorHeight-so {
+		v.editY, v.editX = v.scrollY+so, 0
+	}
+	a.clampViewCursor(v)
+}
+
+// Расчёт размеров окон правой области
+func (a *App) layout() {
+	a.width, a.height = a.screen.Size()
+	a.updatePanelWidth()
+	x := 0
+	if lw := a.listWidth(); lw > 0 {
+		x = lw + 1
+	}
+	w := max(a.width-x, 0)
+	h := a.height - 3
+
+	if len(a.views) < 2 {
+		v := a.views[0]
+		v.x, v.y, v.w, v.h = x, 0, w, h
+		return
+	}
+
+	first, second := a.views[0], a.views[1]
+	if a.split == "vertical" {
+		w1 := (w - 1) * a.splitRatio / 100
+		first.x, first.y, first.w, first.h = x, 0, w1, h
+		second.x, second.y, second.w, second.h = x+w1+1, 0, w-w1-1, h
+	} else {
+		h1 := (h - 1) * a.splitRatio / 100
+		first.x, first.y, first.w, first.h = x, 0, w, h1
+		second.x, second.y, second.w, second.h = x, h1+1, w, h-h1-1
+	}
+}
+
+// Разделить правую область на два окна ("vertical" или "horizontal").
+// Новое окно показывает тот же буфер, что и текущее.
+func (a *App) splitView(kind string) {
+	if len(a.views) > 1 {
+		// уже разделено — просто меняем ориентацию
+		a.split = kind
+		return
+	}
+	nv := *a.view
+	a.views = append(a.views, &nv)
+	a.view = &nv
+	a.split = kind
+	a.splitRatio = 50
+	a.activePanel = "right"
+}
+
+// Закрыть активное окно (последнее окно не закрывается)
+func (a *App) closeView() {
+	if len(a.views) < 2 {
+		return
+	}
+	closed := a.view
+	for i, v := range a.views {
+		if v == a.view {
+			a.views = append(a.views[:i], a.views[i+1:]...)
+			break
+		}
+	}
+	a.view = a.views[0]
+	a.split = ""
+	a.releaseUnused(closed.buf)
+}
+
+// Оставить только активное окно
+func (a *App) onlyView() {
+	others := a.views
+	a.views = []*editorView{a.view}
+	a.split = ""
+	for _, v := range others {
+		a.releaseUnused(v.buf)
+	}
+}
+
+// Переключить фокус на следующее окно
+func (a *App) nextView() {
+	for i, v := range a.views {
+		if v == a.view {
+			a.view = a.views[(i+1)%len(a.views)]
+			break
+		}
+	}
+	a.activePanel = "right"
+}
+
+// Изменить размер разделения (delta в процентах, для первого окна)
+func (a *App) resizeSplit(delta int) {
+	if len(a.views) < 2 {
+		return
+	}
+	// увеличиваем активное окно: для второго окна знак меняется
+	if a.view == a.views[1] {
+		delta = -delta
+	}
+	a.splitRatio += delta
+	if a.splitRatio < 10 {
+		a.splitRatio = 10
+	}
+	if a.splitRatio > 90 {
+		a.splitRatio = 90
+	}
+}
+
+// Отрисовка правой области: одно или два окна редактора
+func (a *App) drawEditor() {
+	// Терминальный курсор показывает только активное окно в режиме edit
+	a.screen.HideCursor()
+
+	for _, v := range a.views {
+		a.drawView(v)
+	}
+
+	// Разделитель между окнами
+	if len(a.views) > 1 {
+		style := a.getStyles().Divider
+		second := a.views[1]
+		if a.split == "vertical" {
+			for y := second.y; y < second.y+second.h; y++ {
+				a.screen.SetContent(second.x-1, y, '│', nil, style)
+			}
+		} else {
+			for x := second.x; x < second.x+second.w; x++ {
+				a.screen.SetContent(x, second.y-1, '─', nil, style)
+			}
+		}
+	}
+
+}
+
+// Отрисовка одного окна редактора
+func (a *App) drawView(v *editorView) {
+	styles := a.getStyles()
+
+	// Заголовок окна
+	title := "  " + tr("ui.editor")
+	if v.mode == "preview" {
+		title = "  " + tr("ui.preview")
+	}
+
+	// При разделении показываем имя файла, чтобы различать окна
+	if len(a.views) > 1 && v.buf.path != "" {
+		title += ": " + filepath.Base(v.buf.path)
+	}
+
+	// Добавляем звездочку, если файл был изменен
+	if v.buf.Modified && v.mode == "edit" {
+		title += " *"
+	}
+
+	maxTitleCols := v.w - 1
+	if maxTitleCols < 0 {
+		maxTitleCols = 0
+	}
+	col := 0
+	// активное окно выделяем акцентным цветом
+	titleColor := styles.title(true, a.activePanel == "right" && v == a.view)
+	for _, r := range title {
+		w := runewidth.RuneWidth(r)
+		if col >= maxTitleCols {
+			break
+		}
+		a.screen.SetContent(v.x+col, v.y, r, nil, tcell.StyleDefault.Foreground(titleColor).Bold(true))
+		col += w
+	}
+
+	// Показываем редактор или предпросмотр в зависимости от режима
+	if v.mode == "edit" {
+		a.drawTextEditor(v)
+	} else {
+		a.drawPreview(v)
+	}
+
+	// Полоса прокрутки у правого края окна
+	_, startY, _, editorHeight := v.textArea()
+	if v.mode == "preview" {
+		a.drawScrollbar(v.x+v.w-1, startY, editorHeight, len(a.previewLines(v)), editorHeight, v.previewY)
+	} else {
+		a.drawScrollbar(v.x+v.w-1, startY, editorHeight, len(v.buf.CachedLines()), editorHeight, v.scrollY)
+	}
+
+}
+
+// Отрисовка текстового редактора
+func (a *App) drawTextEditor(v *editorView) {
+	// Буфер мог измениться в соседнем окне — поправим курсор.
+	a.clampViewCursor(v)
+	// В первую очередь, убедимся, что курсор виден.
+	a.ensureViewCursorVisible(v)
+
+	lines := v.buf.CachedLines()
+	// Учитываем отступ здесь
+	startX, startY, editorWidth, editorHeight := v.textArea()
+	// Курсор рисуем только в активном окне
+	active := a.activePanel == "right" && v == a.view
+
+	styles := a.getStyles()
+
+	// Вертикальная направляющая на заданной колонке (0 — выключена)
+	rulerX := -1
+	if a.config.Editor.Ruler > 0 {
+		rulerX = startX + a.config.Editor.Ruler - v.scrollX
+	}
+	// цвета текста по типу файла ([filetype.<расширение>] в теме)
+	textStyle, filled := styles.filetypeStyle(v.buf.path)
+	rulerStyle := styles.Ruler.apply(textStyle)
+	spellMarks := a.spellMarks(v, lines, v.scrollY, v.scrollY+editorHeight)
+	var brackets map[int][][2]int
+	if active {
+		brackets = bracketMarks(v, lines)
+	}
+	sel, hasSel := v.selection()
+	conflicts := conflictKinds(v.buf.Content, lines)
+
+	for i := 0; i < editorHeight; i++ {
+		lineIdx := v.scrollY + i
+		y := startY + i
+		if filled {
+			for x := v.x; x < startX+editorWidth; x++ {
+				a.screen.SetContent(x, y, ' ', nil, textStyle)
+			}
+		}
+		if rulerX >= startX && rulerX < startX+editorWidth {
+			a.screen.SetContent(rulerX, y, '│', nil, rulerStyle)
+		}
+		if lineIdx >= len(lines) { // пустые строки после конца файла
+			// Если курсор находится на пустой строке после текста
+			if active && lineIdx == v.editY {
+				// Корректируем положение курсора с учетом отступа
+				cursorCol := 0 - runesDisplayWidth([]rune(""), v.scrollX) // фактически 0
+				cursorX := startX + cursorCol
+				cursorY := y
+				// Если курсор на пустой строке, но не в первой позиции, нарисуем курсор-пробел
+				if cursorX >= startX && cursorX < startX+editorWidth && cursorY == y {
+					a.screen.SetContent(cursorX, cursorY, ' ', nil, styles.Cursor.apply(tcell.StyleDefault))
+				}
+			}
+			continue // Продолжаем рисовать "пустые строки" или фон, но не содержимое.
+		}
+		li := v.buf.LineInfo(lineIdx)
+		col := 0
+
+		// Подсветка строки с курсором и блоков конфликтов на всю ширину окна
+		lineStyle := textStyle
+		inConflict := conflicts != nil && conflicts[lineIdx] != conflictNone
+		if inConflict {
+			lineStyle = styles.Conflict[conflicts[lineIdx]].apply(lineStyle)
+		}
+		if v == a.view && lineIdx == v.editY {
+			lineStyle = styles.CursorLine.apply(lineStyle)
+		}
+		if inConflict || (v == a.view && lineIdx == v.editY) {
+			for x := v.x; x < startX+editorWidth; x++ {
+				if x == rulerX {
+					a.screen.SetContent(x, y, '│', nil, rulerStyle.Background(bgOf(lineStyle)))
+					continue
+				}
+				a.screen.SetContent(x, y, ' ', nil, lineStyle)
+			}
+		}
+
+		// Обычная отрисовка без подсветки синтаксиса (подходящая для Markdown plain-editor)
+		runes := li.Runes
+		// Итерируем по графемам, начиная с rune-индекса scrollX
+		for _, g := range li.Graphemes {
+			if g.Start < v.scrollX {
+				continue
+			}
+			if col >= editorWidth {
+				break
+			}
+			k := g.Start
+			w := g.Width
+			if col+w > editorWidth {
+				break
+			}
+			style := lineStyle
+			if hasSel && sel.contains(lineIdx, k) {
+				style = styles.Selection.apply(style)
+			}
+			if inRanges(spellMarks[lineIdx], k) {
+				style = a.spellStyle(style)
+			}
+			if inRanges(brackets[lineIdx], k) {
+				style = styles.Bracket.apply(style)
+			}
+
+			// Если это активный курсор, инвертируем цвет текущего символа
+			if active && lineIdx == v.editY && k == v.editX {
+				style = styles.Cursor.apply(style)
+			}
+			// Здесь startX уже содержит textEditorPadding
+			a.screen.SetContent(startX+col, y, runes[k], runes[k+1:k+g.N], style)
+			col += w
+		}
+
+		// Если курсор находится в конце строки (после последнего символа)
+		if active && lineIdx == v.editY && v.editX == len(runes) {
+			// Корректируем положение курсора с учетом отступа
+			// вычисляем дисплей-колонку курсора и курсора прокрутки
+			cursorDisp := li.Width(v.editX)
+			scrollDisp := li.Width(v.scrollX)
+			cursorX := startX + (cursorDisp - scrollDisp)
+			if cursorX >= startX && cursorX < startX+editorWidth {
+				a.screen.SetContent(cursorX, y, ' ', nil, styles.Cursor.apply(tcell.StyleDefault)) // рисуем инвертированный пробел
+			}
+		}
+	}
+
+	// --- управление реальным курсором терминала ---
+	// Показываем терминальный курсор, если правая панель активна и курсор внутри видимой области редактора.
+	if active {
+		if v.editY >= v.scrollY && v.editY < v.scrollY+editorHeight {
+			// Ширины по строке курсора (если её нет, считаем пустой)
+			cursorDisp, scrollDisp := 0, 0
+			if v.editY < len(lines) {
+				li := v.buf.LineInfo(v.editY)
+				cursorDisp, scrollDisp = li.Width(v.editX), li.Width(v.scrollX)
+			}
+			cursorX := startX + (cursorDisp - scrollDisp)
+			cursorY := startY + (v.editY - v.scrollY)
+			if cursorX >= startX && cursorX < startX+editorWidth && cursorY >= startY && cursorY < startY+editorHeight {
+				a.screen.ShowCursor(cursorX, cursorY)
+			} else {
+				a.screen.HideCursor()
+			}
+		} else {
+			a.screen.HideCursor()
+		}
+	}
+
+}
+
+// Прокрутить окно на delta строк. В режиме edit курсор
+// переносится внутрь видимой области, чтобы прокрутка не откатывалась.
+func (a *App) scrollView(v *editorView, delta int) {
+	if v.mode == "preview" {
+		a.scrollPreview(v, delta)
+		return
+	}
+	lines := v.buf.Lines()
+	_, _, _, editorHeight := v.textArea()
+
+	v.scrollY += delta
+	maxScroll := len(lines) - 1
+	if v.mode == "edit" {
+		// в редакторе не уводим конец файла выше нижнего края окна
+		maxScroll = len(lines) - editorHeight
+	}
+	if v.scrollY > maxScroll {
+		v.scrollY = maxScroll
+	}
+	if v.scrollY < 0 {
+		v.scrollY = 0
+	}
+
+	if v.mode == "edit" {
+		so := a.scrollOff(editorHeight)
+		if v.editY < v.scrollY+so {
+			v.editY = v.scrollY + so
+		} else if v.editY >= v.scrollY+editorHeight-so {
+			v.editY = v.scrollY + editorHeight - so - 1
+		}
+		a.clampViewCursor(v)
+	}
+}
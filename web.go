@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ---- Документы по ссылке http(s):// ----
+//
+// Адрес можно передать в командной строке (eddy https://…/README.md) или
+// ввести в окне перехода (Alt+o). Документ загружается в фоне и
+// открывается в предпросмотре только для чтения; Alt+w сохраняет его
+// в текущую папку. Ссылки вида github.com/…/blob/… открываются как
+// исходный текст (raw.githubusercontent.com).
+
+const (
+	webTimeout = 30 * time.Second
+	webMaxSize = 8 << 20
+)
+
+func isWebURL(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// Адрес исходного текста для страниц GitHub
+func rawURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host != "github.com" {
+		return s
+	}
+	// /owner/repo/blob/branch/path -> raw.githubusercontent.com/owner/repo/branch/path
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 4)
+	if len(parts) < 4 || parts[2] != "blob" {
+		return s
+	}
+	u.Host = "raw.githubusercontent.com"
+	u.Path = "/" + parts[0] + "/" + parts[1] + "/" + parts[3]
+	return u.String()
+}
+
+// Загрузить документ
+func fetchURL(s string) ([]byte, error) {
+	client := &http.Client{Timeout: webTimeout}
+	resp, err := client.Get(rawURL(s))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", s, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, webMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > webMaxSize {
+		return nil, fmt.Errorf("%s: > %s", s, formatSize(webMaxSize))
+	}
+	return data, nil
+}
+
+// Открыть документ по ссылке в предпросмотре
+func (a *App) openURL(s string) {
+	a.notify(levelInfo, tr("web.loading"), s)
+	go func() {
+		data, err := fetchURL(s)
+		a.post(func() {
+			if err != nil {
+				a.notify(levelError, tr("web.failed"), err)
+				return
+			}
+			a.openContent(s, data)
+			a.view.mode = "preview"
+			a.activePanel = "right"
+			a.notify(levelInfo, "%s", tr("web.opened"))
+		})
+	}()
+}
+
+// Имя файла для сохранения документа по ссылке
+func urlFileName(s string) string {
+	name := "index.md"
+	if u, err := url.Parse(s); err == nil {
+		if base := path.Base(u.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+	return name
+}
+
+// Alt+w: сохранить открытый по ссылке документ в текущую папку
+func (a *App) downloadURL() {
+	buf := a.view.buf
+	if !isWebURL(buf.path) {
+		a.notify(levelInfo, "%s", tr("web.not_url"))
+		return
+	}
+	if isRemote(a.currentDir) || inArchive(a.currentDir) {
+		a.notify(levelWarning, "%s", tr("web.local_only"))
+		return
+	}
+	dest := filepath.Join(a.currentDir, urlFileName(buf.path))
+	write := func() {
+		if err := os.WriteFile(dest, []byte(buf.Content), 0644); err != nil {
+			a.notify(levelError, tr("save.failed"), err)
+			return
+		}
+		a.notify(levelSuccess, tr("save.ok"), filepath.Base(dest))
+		a.loadFiles()
+	}
+	if _, err := os.Stat(dest); err == nil {
+		a.confirmIf(a.config.Confirm.Overwrite, trf("web.overwrite", filepath.Base(dest)), write)
+		return
+	}
+	write()
+}
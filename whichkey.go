@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/StasKrav/eddy_tcell/internal/ui"
+)
+
+// ---- Подсказка продолжений (which-key) ----
+//
+// Если после префиксной клавиши (Ctrl+W, Alt+") ничего не нажато за
+// whichKeyDelay, в правом нижнем углу появляется список возможных
+// продолжений с командами. F1 показывает такой же список всех клавиш
+// текущей панели. Список строится из реестра команд (commands.go).
+
+const whichKeyDelay = 400 * time.Millisecond
+
+// Начат ввод префиксной команды: подсказка появится после паузы
+func (a *App) startPrefix() {
+	a.prefixSince = time.Now()
+	a.after(whichKeyDelay)
+}
+
+// Префикс, ожидающий продолжения ("" — нет)
+func (a *App) activePrefix() string {
+	if a.register == registerPending {
+		return `Alt+"`
+	}
+	return a.pendingPrefix
+}
+
+// Привязки, начинающиеся с префикса; keys — только продолжение
+func (a *App) prefixBindings(prefix string) []keyBinding {
+	var res []keyBinding
+	for _, b := range a.keymap() {
+		if rest, ok := strings.CutPrefix(b.keys, prefix+" "); ok {
+			b.keys = rest
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// Привязки для активной панели (для F1)
+func (a *App) contextBindings() []keyBinding {
+	contexts := map[string]bool{"help.ctx.navigation": true, "help.ctx.panels": true, "help.ctx.other": true, "help.ctx.plugins": true}
+	if a.activePanel == "right" {
+		contexts = map[string]bool{"help.ctx.editing": true, "help.ctx.windows": true, "help.ctx.panels": true, "help.ctx.plugins": true}
+		if a.view.mode == "preview" {
+			delete(contexts, "help.ctx.editing")
+			contexts["help.ctx.preview"] = true
+		}
+	}
+	var res []keyBinding
+	for _, b := range a.keymap() {
+		if contexts[b.context] && !b.alias {
+			res = append(res, b)
+		}
+	}
+	return res
+}
+
+// Нарисовать подсказку для ожидающего префикса
+func (a *App) drawWhichKey() {
+	prefix := a.activePrefix()
+	if prefix == "" || time.Since(a.prefixSince) < whichKeyDelay {
+		return
+	}
+	bs := a.prefixBindings(prefix)
+	if a.register == registerPending {
+		bs = []keyBinding{{"a–z", "", "help.edit.register", false}}
+	}
+	a.drawBindings(prefix, bs)
+}
+
+// Окно со списком привязок в правом нижнем углу (над статусной строкой);
+// если не помещаются по высоте — в несколько колонок
+func (a *App) drawBindings(title string, bs []keyBinding) {
+	if len(bs) == 0 {
+		return
+	}
+	st := a.dialogStyles()
+	keyW, descW := 0, 0
+	for _, b := range bs {
+		keyW = max(keyW, runewidth.StringWidth(b.keys))
+		descW = max(descW, runewidth.StringWidth(tr(b.desc)))
+	}
+	colW := keyW + 2 + descW
+	rows := min(len(bs), max(a.height-6, 1))
+	cols := (len(bs) + rows - 1) / rows
+	// длинные описания обрезаем, чтобы колонки поместились по ширине
+	colW = max(min(colW, (a.width-4)/cols-2), keyW+4)
+	w := min(cols*(colW+2)+2, a.width-2)
+	h := rows + 2
+	x, y := a.width-w-1, a.height-3-h
+	if y < 0 {
+		y = 0
+	}
+	ui.Box(a.screen, x, y, w, h, " "+title+" ", st.border, st.body)
+	for i, b := range bs {
+		cx := x + 2 + (i/rows)*(colW+2)
+		cy := y + 1 + i%rows
+		if cx >= x+w-1 {
+			break
+		}
+		ui.PutString(a.screen, cx, cy, x+w-1, b.keys, st.body.Bold(true))
+		ui.PutString(a.screen, cx+keyW+2, cy, min(cx+colW, x+w-1), tr(b.desc), st.dim)
+	}
+}
+
+// F1: все клавиши текущей панели; любая клавиша закрывает
+type whichKeyOverlay struct{}
+
+func (o *whichKeyOverlay) draw(a *App) {
+	title := tr("ui.files")
+	if a.activePanel == "right" {
+		title = tr("ui.editor")
+	}
+	a.drawBindings(title, a.contextBindings())
+}
+
+func (o *whichKeyOverlay) handleKey(a *App, ev *tcell.EventKey) bool {
+	return true
+}